@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"todoist/internal/backup"
+)
+
+type BackupSummary = backup.Summary
+
+// Backup streams a backup archive of the whole server into w. It needs the server's admin
+// token and is never retried, since part of the archive may already have been written.
+func (c *Client) Backup(ctx context.Context, w io.Writer) error {
+	resp, err := c.stream(ctx, http.MethodGet, "/admin/backup", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// Restore replaces everything on the server with the contents of the archive read from r
+func (c *Client) Restore(ctx context.Context, r io.Reader) (BackupSummary, error) {
+	resp, err := c.stream(ctx, http.MethodPost, "/admin/restore", r)
+	if err != nil {
+		return BackupSummary{}, err
+	}
+	defer resp.Body.Close()
+
+	var summary BackupSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return BackupSummary{}, fmt.Errorf("todoist: decoding response: %w", err)
+	}
+	return summary, nil
+}
+
+// stream sends a raw body once and hands back a successful response unread
+func (c *Client) stream(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += path
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.decode(resp, nil); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
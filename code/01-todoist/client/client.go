@@ -0,0 +1,345 @@
+// Package client is a typed Go client for the todoist HTTP API.
+//
+// Its methods mirror services.ITodoService, so a *Client can stand in wherever
+// the service interface is expected. Server errors are decoded back into the
+// same sentinel values the service layer uses, so callers can test them with errors.Is.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+	"todoist/internal/services"
+)
+
+type (
+	Todo       = models.Todo
+	TodoStatus = models.TodoStatus
+	CreateTodo = models.CreateTodo
+	UpdateTodo = models.UpdateTodo
+	MoveTodo   = models.MoveTodo
+)
+
+const (
+	StatusPending   = models.StatusPending
+	StatusCompleted = models.StatusCompleted
+	StatusTrashed   = models.StatusTrashed
+)
+
+var (
+	ErrNotFound     = repositories.ErrNotFound
+	ErrConflict     = repositories.ErrConflict
+	ErrInvalidInput = services.ErrInvalidInput
+)
+
+var _ services.ITodoService = (*Client)(nil)
+
+// APIError is returned for any non-2xx response. It unwraps to the matching sentinel error, if any.
+type APIError struct {
+	StatusCode int
+	Message    string
+	sentinel   error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("todoist: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.sentinel
+}
+
+// decodeError prefers the message the server wrote for a sentinel and falls back to the status code
+func decodeError(status int, body string) *APIError {
+	e := &APIError{StatusCode: status, Message: body}
+
+	for _, sentinel := range []error{ErrNotFound, ErrConflict, ErrInvalidInput} {
+		if body == sentinel.Error() {
+			e.sentinel = sentinel
+			return e
+		}
+	}
+
+	switch status {
+	case http.StatusNotFound:
+		e.sentinel = ErrNotFound
+	case http.StatusConflict:
+		e.sentinel = ErrConflict
+	case http.StatusBadRequest:
+		e.sentinel = ErrInvalidInput
+	}
+	return e
+}
+
+type Client struct {
+	baseURL    *url.URL
+	http       *http.Client
+	token      string
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	pageSize   int
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// WithToken sends the token as a bearer token on every request
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRetries sets how often idempotent calls (GET, PUT, DELETE) are retried after network
+// errors and 429, 502, 503 and 504 responses. A 500 is not retried, since the server failed
+// on the request itself and would most likely fail the same way again. Creates are never retried.
+func WithRetries(max int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = max
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithPageSize sets how many todos are fetched per request when listing
+func WithPageSize(n int) Option {
+	return func(c *Client) {
+		c.pageSize = n
+	}
+}
+
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("todoist: unsupported scheme %q", u.Scheme)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		http:       &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+		pageSize:   100,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+func (c *Client) CreateTodo(ctx context.Context, dto CreateTodo) (Todo, error) {
+	var t Todo
+	_, err := c.do(ctx, http.MethodPost, "/todos", nil, dto, &t)
+	return t, err
+}
+
+func (c *Client) GetTodo(ctx context.Context, id int) (Todo, error) {
+	var t Todo
+	_, err := c.do(ctx, http.MethodGet, "/todos/"+strconv.Itoa(id), nil, nil, &t)
+	return t, err
+}
+
+// ListTodos fetches every page of the user's todos
+func (c *Client) ListTodos(ctx context.Context, userID string) ([]Todo, error) {
+	todos := make([]Todo, 0)
+	for t, err := range c.Todos(ctx, userID) {
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, t)
+	}
+	return todos, nil
+}
+
+// Todos iterates over the user's todos in position order, fetching pages lazily.
+// Iteration stops after the first error, which is yielded with a zero Todo.
+func (c *Client) Todos(ctx context.Context, userID string) iter.Seq2[Todo, error] {
+	return func(yield func(Todo, error) bool) {
+		path := "/users/" + url.PathEscape(userID) + "/todos"
+		cursor := ""
+
+		for {
+			query := url.Values{"limit": {strconv.Itoa(c.pageSize)}}
+			if cursor != "" {
+				query.Set("cursor", cursor)
+			}
+
+			var page []Todo
+			header, err := c.do(ctx, http.MethodGet, path, query, nil, &page)
+			if err != nil {
+				yield(Todo{}, err)
+				return
+			}
+
+			for _, t := range page {
+				if !yield(t, nil) {
+					return
+				}
+			}
+
+			cursor = header.Get("X-Next-Cursor")
+			if cursor == "" {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) UpdateTodo(ctx context.Context, dto UpdateTodo) (Todo, error) {
+	var t Todo
+	_, err := c.do(ctx, http.MethodPut, "/todos/"+strconv.Itoa(dto.ID), nil, dto, &t)
+	return t, err
+}
+
+// MoveTodo is not retried, but repeating it is harmless: the todo ends up in the same place
+func (c *Client) MoveTodo(ctx context.Context, dto MoveTodo) (Todo, error) {
+	var t Todo
+	_, err := c.do(ctx, http.MethodPost, "/todos/"+strconv.Itoa(dto.ID)+"/move", nil, dto, &t)
+	return t, err
+}
+
+// DeleteTodo is retried like other idempotent calls, so a retry after a lost
+// response may report ErrNotFound for a todo this call deleted.
+func (c *Client) DeleteTodo(ctx context.Context, id int) error {
+	_, err := c.do(ctx, http.MethodDelete, "/todos/"+strconv.Itoa(id), nil, nil, nil)
+	return err
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) (http.Header, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	retries := c.maxRetries
+	if method == http.MethodPost {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, u.String(), payload)
+
+		retryAfter := time.Duration(0)
+		if err == nil {
+			if !retryable(resp.StatusCode) {
+				defer resp.Body.Close()
+				return resp.Header, c.decode(resp, out)
+			}
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			err = c.decode(resp, nil)
+			resp.Body.Close()
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt >= retries {
+			return nil, err
+		}
+
+		wait := max(c.backoff(attempt), retryAfter)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, u string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return c.http.Do(req)
+}
+
+func (c *Client) decode(resp *http.Response, out any) error {
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return decodeError(resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("todoist: decoding response: %w", err)
+	}
+	return nil
+}
+
+// retryable lists the statuses that say the server was briefly unable to answer
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// backoff doubles minBackoff per attempt up to maxBackoff, with full jitter
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.minBackoff << attempt
+	if wait <= 0 || wait > c.maxBackoff {
+		wait = c.maxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return rand.N(wait + 1)
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todoist/internal/handlers"
+	"todoist/internal/repositories"
+	"todoist/internal/services"
+)
+
+func newTestClient(t *testing.T, opts ...Option) *Client {
+	t.Helper()
+
+	handler := handlers.NewTodoHandler(services.NewTodoService(repositories.NewInMemoryTodoRepo()))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/todos", handler.CreateTodoHandler)
+	mux.HandleFunc("/todos/", handler.TodoByIDHandler)
+	mux.HandleFunc("/users/", handler.UsersHandler)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClientCRUD(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	created, err := c.CreateTodo(ctx, CreateTodo{UserID: "alice", Title: "write client"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.GetTodo(ctx, created.ID)
+	if err != nil || got.Title != "write client" {
+		t.Fatalf("got %+v, %v want the created todo", got, err)
+	}
+
+	status := StatusCompleted
+	updated, err := c.UpdateTodo(ctx, UpdateTodo{ID: created.ID, Status: &status})
+	if err != nil || updated.Status != StatusCompleted {
+		t.Fatalf("got %+v, %v want completed todo", updated, err)
+	}
+
+	if err := c.DeleteTodo(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetTodo(ctx, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v want %v", err, ErrNotFound)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+
+	_, err := c.CreateTodo(ctx, CreateTodo{UserID: "alice"})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("got %v want %v", err, ErrInvalidInput)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("got %#v want *APIError with status 400", err)
+	}
+
+	title := "x"
+	if _, err := c.UpdateTodo(ctx, UpdateTodo{ID: 99, Title: &title}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v want %v", err, ErrNotFound)
+	}
+}
+
+func TestClientPagination(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, WithPageSize(3))
+
+	for range 8 {
+		c.CreateTodo(ctx, CreateTodo{UserID: "alice", Title: "t"})
+	}
+	c.CreateTodo(ctx, CreateTodo{UserID: "bob", Title: "not alice's"})
+
+	todos, err := c.ListTodos(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != 8 {
+		t.Fatalf("got %d todos want 8", len(todos))
+	}
+	for i, todo := range todos {
+		if todo.ID != i+1 {
+			t.Errorf("got id %d at %d want %d", todo.ID, i, i+1)
+		}
+	}
+
+	seen := 0
+	for _, err := range c.Todos(ctx, "alice") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen++; seen == 4 {
+			break
+		}
+	}
+	if seen != 4 {
+		t.Errorf("got %d todos want iteration to stop at 4", seen)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	var calls atomic.Int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":1,"title":"finally"}`))
+	}))
+	defer flaky.Close()
+
+	ctx := context.Background()
+	c, _ := New(flaky.URL, WithRetries(3, time.Millisecond, 5*time.Millisecond))
+
+	todo, err := c.GetTodo(ctx, 1)
+	if err != nil || todo.Title != "finally" {
+		t.Fatalf("got %+v, %v want success after retries", todo, err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("got %d calls want 3", got)
+	}
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "bug", http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+	calls.Store(0)
+	b, _ := New(broken.URL, WithRetries(3, time.Millisecond, 5*time.Millisecond))
+	if _, err := b.GetTodo(ctx, 1); err == nil {
+		t.Error("expected a 500 to fail")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d calls want a 500 not to be retried", got)
+	}
+
+	calls.Store(0)
+	if _, err := c.CreateTodo(ctx, CreateTodo{UserID: "a", Title: "b"}); err == nil {
+		t.Error("expected create to fail without retrying")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d calls want creates not to be retried", got)
+	}
+}
+
+func TestClientContextCancellation(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	c, _ := New(down.URL, WithRetries(10, time.Second, time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetTodo(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("took %s, backoff ignored the context", elapsed)
+	}
+}
@@ -1,23 +1,235 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"log"
 	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+	_ "time/tzdata" // quick-add reads dates in the user's time zone, even where the host has no zoneinfo
+	"todoist/internal/backup"
+	"todoist/internal/blobs"
+	"todoist/internal/events"
 	"todoist/internal/handlers"
+	"todoist/internal/health"
+	"todoist/internal/models"
+	"todoist/internal/reminders"
 	"todoist/internal/repositories"
 	"todoist/internal/services"
+	"todoist/internal/webhooks"
 )
 
 func main() {
-	repo := repositories.NewInMemoryTodoRepo()
-	service := services.NewTodoService(repo)
+	addr := flag.String("addr", ":8080", "address to listen on")
+	dataDir := flag.String("data-dir", "", "directory used by file-backed storage")
+	cacheSize := flag.Int("cache-size", 0, "number of repository reads to cache, 0 disables the cache")
+	cacheTTL := flag.Duration("cache-ttl", 30*time.Second, "how long cached repository reads stay valid")
+	store := flag.String("store", "memory", "todo storage: memory, sharded for many concurrent users, or events to keep the full history of every todo")
+	reminderLead := flag.Duration("reminder-lead", 15*time.Minute, "how long before the due date reminders fire")
+	reminderWebhook := flag.String("reminder-webhook", "", "URL that receives every reminder as a signed POST")
+	reminderSecret := flag.String("reminder-webhook-secret", "", "secret used to sign reminder webhooks")
+	smtpAddr := flag.String("smtp-addr", "", "SMTP server host:port used to mail reminders")
+	smtpFrom := flag.String("smtp-from", "reminders@localhost", "sender address of reminder mail")
+	smtpDomain := flag.String("smtp-domain", "", "reminders are mailed to <user id>@<domain>")
+	maxAttachment := flag.Int64("max-attachment-size", services.DefaultMaxAttachmentSize, "largest attachment upload in bytes")
+	adminToken := flag.String("admin-token", "", "bearer token for the /admin endpoints, which stay disabled when empty")
+	requestTimeout := flag.Duration("request-timeout", 10*time.Second, "deadline applied to every request except event streams")
+	flag.Parse()
+
+	var repo interface {
+		repositories.TodoRepository
+		repositories.ChangeLog
+		repositories.Pinger
+	}
+	var history repositories.TodoHistoryReader
+	switch *store {
+	case "memory":
+		repo = repositories.NewInMemoryTodoRepo()
+	case "sharded":
+		repo = repositories.NewShardedTodoRepo(0)
+	case "events":
+		sourced, err := repositories.NewEventSourcedTodoRepo(context.Background(), repositories.NewInMemoryEventStore())
+		if err != nil {
+			log.Fatal(err)
+		}
+		repo, history = sourced, sourced
+	default:
+		log.Fatalf("unknown store %q", *store)
+	}
+
+	var todoRepo repositories.TodoRepository = repo
+	if *cacheSize > 0 {
+		cached := repositories.NewCachingTodoRepo(repo, *cacheSize, *cacheTTL)
+		http.HandleFunc("GET /debug/cache", handlers.CacheStatsHandler(cached))
+		todoRepo = cached
+	}
+
+	userRepo := repositories.NewInMemoryUserRepo()
+	userHandler := handlers.NewUserHandler(services.NewUserService(userRepo))
+
+	projectRepo := repositories.NewInMemoryProjectRepo()
+	dependencyRepo := repositories.NewInMemoryDependencyRepo()
+
+	bus := events.NewBus(256)
+	service := services.NewTodoService(todoRepo, services.WithEvents(bus), services.WithUsers(userRepo), services.WithProjects(projectRepo), services.WithDependencies(dependencyRepo))
 	handler := handlers.NewTodoHandler(service)
+	undoHandler := handlers.NewUndoHandler(service)
+	projectHandler := handlers.NewProjectHandler(services.NewProjectService(projectRepo, service))
+	dependencyService := services.NewDependencyService(dependencyRepo, service, projectRepo)
+	bus.Listen(dependencyService.HandleEvent)
+	dependencyHandler := handlers.NewDependencyHandler(dependencyService)
+	historyHandler := handlers.NewHistoryHandler(services.NewHistoryService(history))
+	eventsHandler := handlers.NewEventsHandler(bus, 15*time.Second)
+	syncHandler := handlers.NewSyncHandler(services.NewSyncService(service, repo))
+	statsService := services.NewStatsService()
+	bus.Listen(statsService.HandleEvent)
+	statsHandler := handlers.NewStatsHandler(statsService)
+	timeEntryRepo := repositories.NewInMemoryTimeEntryRepo()
+	timeHandler := handlers.NewTimeHandler(services.NewTimeService(timeEntryRepo, service))
+	quickAddHandler := handlers.NewQuickAddHandler(services.NewQuickAddService(service))
+	transferHandler := handlers.NewTransferHandler(service, services.NewImportService(service))
+
+	// attachments only outlive the process when their records do, so without a data
+	// directory the files stay in memory with everything else
+	var blobStore blobs.BlobStore = blobs.NewMemoryStore()
+	if *dataDir != "" {
+		diskStore, err := blobs.NewDiskStore(filepath.Join(*dataDir, "attachments"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		blobStore = diskStore
+	}
+	commentRepo := repositories.NewInMemoryCommentRepo()
+	attachmentRepo := repositories.NewInMemoryAttachmentRepo()
+	commentService := services.NewCommentService(commentRepo, service)
+	attachmentService := services.NewAttachmentService(attachmentRepo, blobStore, service, services.WithMaxAttachmentSize(*maxAttachment))
+	bus.Listen(commentService.HandleEvent)
+	bus.Listen(attachmentService.HandleEvent)
+	commentHandler := handlers.NewCommentHandler(commentService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, *maxAttachment)
 
-	http.HandleFunc("/health", handlers.HealthHandler)
+	webhookRepo := repositories.NewInMemoryWebhookRepo()
+	webhookHandler := handlers.NewWebhookHandler(services.NewWebhookService(webhookRepo))
+	dispatcher := webhooks.NewDispatcher(webhookRepo, webhooks.DefaultConfig())
+	bus.Listen(dispatcher.HandleEvent)
+	dispatcher.Start(context.Background())
+
+	notifiers := reminders.Notifiers{reminders.LogNotifier{}}
+	if *reminderWebhook != "" {
+		notifiers = append(notifiers, reminders.WebhookNotifier{URL: *reminderWebhook, Secret: *reminderSecret, Client: &http.Client{Timeout: 10 * time.Second}})
+	}
+	if *smtpAddr != "" && *smtpDomain != "" {
+		notifiers = append(notifiers, reminders.SMTPNotifier{
+			Addr: *smtpAddr,
+			From: *smtpFrom,
+			Recipient: func(ctx context.Context, userID string) (string, error) {
+				return userID + "@" + *smtpDomain, nil
+			},
+		})
+	}
+	reminderCfg := reminders.DefaultConfig()
+	reminderCfg.Lead = *reminderLead
+	scheduler := reminders.NewScheduler(reminders.SystemClock{}, notifiers, reminderCfg)
+	bus.Listen(scheduler.HandleEvent)
+	go scheduler.Run(context.Background())
+
+	// requests that write hold writes shared; a backup or restore holds it on its own
+	var writes sync.RWMutex
+	if *adminToken != "" {
+		stores := backup.Stores{
+			Users:        userRepo,
+			Projects:     projectRepo,
+			Dependencies: dependencyRepo,
+			Comments:     commentRepo,
+			Attachments:  attachmentRepo,
+			TimeEntries:  timeEntryRepo,
+			Webhooks:     webhookRepo,
+			Writes:       &writes,
+			Derived:      []backup.Rebuilder{service, statsService, scheduler},
+		}
+		// the event-sourced store keeps history a snapshot would lose, so it is not backed up this way
+		if todos, ok := todoRepo.(repositories.Snapshotter[models.Todo]); ok {
+			stores.Todos = todos
+		}
+		adminHandler := handlers.NewAdminHandler(stores, *adminToken)
+		http.HandleFunc("GET /admin/backup", adminHandler.Backup)
+		http.HandleFunc("POST /admin/restore", adminHandler.Restore)
+	}
+
+	checker := health.NewChecker(2 * time.Second)
+	checker.Register("repository", repo.Ping)
+	if *dataDir != "" {
+		checker.Register("disk", health.DiskSpace(*dataDir, 100<<20))
+	}
+	checker.Register("webhook-dispatcher", dispatcher.Heartbeat.Check(30*time.Second))
+	healthHandler := handlers.NewHealthHandler(checker)
+
+	http.HandleFunc("/livez", healthHandler.Livez)
+	http.HandleFunc("/readyz", healthHandler.Readyz)
+	http.HandleFunc("/version", healthHandler.Version)
+	http.HandleFunc("POST /users", userHandler.Register)
+	http.HandleFunc("POST /login", userHandler.Login)
+	http.HandleFunc("PUT /users/{id}/password", userHandler.ChangePassword)
 	http.HandleFunc("/todos", handler.CreateTodoHandler) // POST only
 	http.HandleFunc("/todos/", handler.TodoByIDHandler)  // GET, PUT, DELETE
 	http.HandleFunc("/users/", handler.UsersHandler)     // GET /users/{id}/todos
+	http.HandleFunc("POST /todos/{id}/move", handler.Move)
+	http.HandleFunc("GET /todos/{id}/history", historyHandler.History)
+	http.HandleFunc("GET /todos/{id}/asof", historyHandler.AsOf)
+	http.HandleFunc("POST /todos/{id}/comments", commentHandler.Create)
+	http.HandleFunc("GET /todos/{id}/comments", commentHandler.List)
+	http.HandleFunc("PUT /todos/{id}/comments/{commentID}", commentHandler.Update)
+	http.HandleFunc("DELETE /todos/{id}/comments/{commentID}", commentHandler.Delete)
+	http.HandleFunc("POST /todos/{id}/attachments", attachmentHandler.Upload)
+	http.HandleFunc("GET /todos/{id}/attachments", attachmentHandler.List)
+	http.HandleFunc("GET /todos/{id}/attachments/{attachmentID}", attachmentHandler.Download)
+	http.HandleFunc("DELETE /todos/{id}/attachments/{attachmentID}", attachmentHandler.Delete)
+	http.HandleFunc("POST /todos/{id}/blockers", dependencyHandler.Add)
+	http.HandleFunc("GET /todos/{id}/blockers", dependencyHandler.List)
+	http.HandleFunc("DELETE /todos/{id}/blockers/{blockerID}", dependencyHandler.Delete)
+	http.HandleFunc("POST /todos/{id}/timer/start", timeHandler.Start)
+	http.HandleFunc("POST /todos/{id}/timer/stop", timeHandler.Stop)
+	http.HandleFunc("POST /todos/{id}/time", timeHandler.Create)
+	http.HandleFunc("GET /todos/{id}/time", timeHandler.List)
+	http.HandleFunc("DELETE /todos/{id}/time/{entryID}", timeHandler.Delete)
+	http.HandleFunc("POST /users/{id}/undo", undoHandler.Undo)
+	http.HandleFunc("POST /users/{id}/redo", undoHandler.Redo)
+	http.HandleFunc("GET /users/{id}/events", eventsHandler.Stream)
+	http.HandleFunc("POST /users/{id}/sync", syncHandler.Sync)
+	http.HandleFunc("GET /users/{id}/todos.ics", transferHandler.ExportICal)
+	http.HandleFunc("GET /users/{id}/todos.csv", transferHandler.ExportCSV)
+	http.HandleFunc("GET /users/{id}/todos.md", transferHandler.ExportMarkdown)
+	http.HandleFunc("POST /users/{id}/todos/import", transferHandler.Import)
+	http.HandleFunc("POST /users/{id}/todos/quick", quickAddHandler.QuickAdd)
+	http.HandleFunc("GET /users/{id}/stats", statsHandler.Stats)
+	http.HandleFunc("GET /users/{id}/time", timeHandler.Totals)
+	http.HandleFunc("GET /users/{id}/timesheet.csv", timeHandler.Timesheet)
+	http.HandleFunc("POST /users/{id}/projects", projectHandler.Create)
+	http.HandleFunc("GET /users/{id}/projects", projectHandler.List)
+	http.HandleFunc("GET /users/{id}/board", projectHandler.Inbox)
+	http.HandleFunc("GET /projects/{id}", projectHandler.Get)
+	http.HandleFunc("PUT /projects/{id}/workflow", projectHandler.SetWorkflow)
+	http.HandleFunc("GET /projects/{id}/board", projectHandler.Board)
+	http.HandleFunc("GET /projects/{id}/plan", dependencyHandler.Plan)
+	http.HandleFunc("POST /users/{id}/webhooks", webhookHandler.Create)
+	http.HandleFunc("GET /users/{id}/webhooks", webhookHandler.List)
+	http.HandleFunc("DELETE /users/{id}/webhooks/{webhookID}", webhookHandler.Delete)
+	http.HandleFunc("GET /users/{id}/webhooks/{webhookID}/attempts", webhookHandler.Attempts)
+	http.HandleFunc("GET /users/{id}/webhooks/deadletters", webhookHandler.DeadLetters)
+
+	// backups and restores take writes themselves and may run for longer than a request should
+	root := handlers.WriteBarrier(&writes, http.DefaultServeMux, "/admin/")
+	root = handlers.Timeout(*requestTimeout, root, "GET /users/{id}/events", "/admin/")
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           root,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
 
-	fmt.Println("server is listening on port:8080")
-	http.ListenAndServe(":8080", nil)
+	fmt.Println("server is listening on", *addr)
+	server.ListenAndServe()
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// subBits sets the histogram's precision: every power-of-two range of values is split into
+// 2^(subBits-1) equal buckets, so a recorded value is off by less than 1/512 (0.2%).
+const subBits = 10
+
+const half = 1 << (subBits - 1)
+
+// Histogram counts latencies in log-linear buckets the way HdrHistogram does: fixed memory,
+// constant-time recording and bounded relative error over the whole range of int64 nanoseconds.
+// It is not safe for concurrent use.
+type Histogram struct {
+	counts [(64 - subBits + 2) * half]int64
+	total  int64
+	sum    float64
+	min    int64
+	max    int64
+}
+
+func bucketOf(v int64) int {
+	if v < 2*half {
+		return int(v)
+	}
+	e := bits.Len64(uint64(v)) - subBits
+	return e*half + int(v>>e)
+}
+
+// highest is the largest value that lands in bucket i; percentiles report it so they never understate
+func highest(i int) int64 {
+	if i < 2*half {
+		return int64(i)
+	}
+	e := i/half - 1
+	sub := int64(i - e*half)
+	return sub<<e + (1<<e - 1)
+}
+
+func (h *Histogram) Record(d time.Duration) {
+	v := max(int64(d), 0)
+	h.counts[bucketOf(v)]++
+	if h.total == 0 || v < h.min {
+		h.min = v
+	}
+	h.max = max(h.max, v)
+	h.total++
+	h.sum += float64(v)
+}
+
+func (h *Histogram) Merge(o *Histogram) {
+	if o.total == 0 {
+		return
+	}
+	for i, n := range o.counts {
+		h.counts[i] += n
+	}
+	if h.total == 0 || o.min < h.min {
+		h.min = o.min
+	}
+	h.max = max(h.max, o.max)
+	h.total += o.total
+	h.sum += o.sum
+}
+
+func (h *Histogram) Count() int64 {
+	return h.total
+}
+
+func (h *Histogram) Min() time.Duration {
+	return time.Duration(h.min)
+}
+
+func (h *Histogram) Max() time.Duration {
+	return time.Duration(h.max)
+}
+
+func (h *Histogram) Mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / float64(h.total))
+}
+
+// Quantile returns the smallest recorded value that at least q of all values are at or below, q in [0, 1]
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := max(int64(math.Ceil(q*float64(h.total))), 1)
+
+	var seen int64
+	for i, n := range h.counts {
+		if seen += n; seen >= rank {
+			// the true value cannot exceed what was actually recorded
+			return time.Duration(min(highest(i), h.max))
+		}
+	}
+	return time.Duration(h.max)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBucketsCoverEveryValue(t *testing.T) {
+	// every value must land in a bucket whose range contains it, and buckets must be ordered
+	values := []int64{0, 1, 2*half - 1, 2 * half, 2*half + 1, 1e6, 123456789, 1<<62 + 12345, 1<<63 - 1}
+	for range 10000 {
+		values = append(values, rand.Int64N(1<<40))
+	}
+	slices.Sort(values)
+
+	last := -1
+	for _, v := range values {
+		i := bucketOf(v)
+		if i < last {
+			t.Fatalf("bucket of %d is %d, below the bucket of a smaller value", v, i)
+		}
+		last = i
+		if i >= len(Histogram{}.counts) {
+			t.Fatalf("bucket %d of %d is out of range", i, v)
+		}
+		if hi := highest(i); hi < v || float64(hi-v) > float64(v)/half {
+			t.Fatalf("value %d reported as %d", v, hi)
+		}
+		if i > 0 && highest(i-1) >= v {
+			t.Fatalf("value %d also fits bucket %d", v, i-1)
+		}
+	}
+}
+
+func TestQuantiles(t *testing.T) {
+	var h Histogram
+	if h.Quantile(0.99) != 0 || h.Mean() != 0 {
+		t.Errorf("empty histogram reported values")
+	}
+
+	for i := 1; i <= 1000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0, time.Millisecond},
+		{0.5, 500 * time.Millisecond},
+		{0.9, 900 * time.Millisecond},
+		{0.99, 990 * time.Millisecond},
+		{0.999, 999 * time.Millisecond},
+		{1, 1000 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		got := h.Quantile(tt.q)
+		if got < tt.want || float64(got-tt.want) > float64(tt.want)/half {
+			t.Errorf("q%v: got %v want %v within %.2f%%", tt.q, got, tt.want, 100.0/half)
+		}
+	}
+	if h.Count() != 1000 || h.Min() != time.Millisecond || h.Max() != time.Second {
+		t.Errorf("got count %d min %v max %v", h.Count(), h.Min(), h.Max())
+	}
+	if mean := h.Mean(); mean != 500500*time.Microsecond {
+		t.Errorf("got mean %v want 500.5ms", mean)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var a, b, both Histogram
+	for i := range 500 {
+		d := time.Duration(i*i) * time.Microsecond
+		if i%2 == 0 {
+			a.Record(d)
+		} else {
+			b.Record(d)
+		}
+		both.Record(d)
+	}
+
+	a.Merge(&b)
+	a.Merge(&Histogram{})
+	if a != both {
+		t.Errorf("merged histogram differs from recording everything in one")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMix(t *testing.T) {
+	m, err := ParseMix("get=3, create=1,delete=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[Op]int)
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 40000 {
+		counts[m.Pick(rng)]++
+	}
+	if counts[OpDelete] != 0 || counts[OpList] != 0 {
+		t.Errorf("picked operations without weight: %v", counts)
+	}
+	if ratio := float64(counts[OpGet]) / float64(counts[OpCreate]); ratio < 2.8 || ratio > 3.2 {
+		t.Errorf("got get/create ratio %.2f want about 3", ratio)
+	}
+
+	for _, bad := range []string{"", "get", "get=-1", "fetch=1", "get=1,get=2", "get=0", "get=x"} {
+		if _, err := ParseMix(bad); err == nil {
+			t.Errorf("%q: want an error", bad)
+		}
+	}
+}
+
+func TestRunInProcess(t *testing.T) {
+	for _, store := range []string{"memory", "sharded"} {
+		t.Run(store, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := []string{"-rate", "400", "-duration", "250ms", "-users", "3", "-seed-todos", "2", "-store", store, "-o", "json"}
+			if code := run(context.Background(), args, &stdout, &stderr); code != 0 {
+				t.Fatalf("got exit %d\nstderr: %s", code, stderr.String())
+			}
+
+			var report jsonReport
+			if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+				t.Fatalf("decoding %q: %v", stdout.String(), err)
+			}
+			if report.Scheduled != 100 || report.Total.Count != report.Scheduled {
+				t.Errorf("got %d scheduled, %d recorded, %d dropped want 100 accounted for", report.Scheduled, report.Total.Count, report.Dropped)
+			}
+			for _, op := range []string{"create", "get", "list"} {
+				if report.Ops[op].Count == 0 {
+					t.Errorf("no %s requests in %+v", op, report.Ops)
+				}
+			}
+			if p50, p999 := report.Total.Quantiles["p50"], report.Total.Quantiles["p999"]; p50 <= 0 || p999 < p50 {
+				t.Errorf("got p50 %v p999 %v", p50, p999)
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-rate", "50", "-duration", "100ms", "-users", "1"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("got exit %d\nstderr: %s", code, stderr.String())
+	}
+	if out := stdout.String(); !strings.Contains(out, "p999") || !strings.Contains(out, "all") {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+}
+
+func TestRunRecordsDroppedRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users" {
+			http.NotFound(w, r)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	mix, _ := ParseMix("get=1")
+	cfg := Config{Target: server.URL, Rate: 200, Duration: 100 * time.Millisecond, Mix: mix, Users: 1, MaxInFlight: 1, Timeout: time.Second, Seed: 1}
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := res.Ops[OpGet]
+	if res.Dropped == 0 || get.Dropped != res.Dropped || get.Latency.Count() != res.Dropped || get.Errors != res.Dropped {
+		t.Errorf("got %d dropped, get %+v want every drop recorded as a failed get", res.Dropped, get)
+	}
+	if get.Latency.Min() < cfg.Timeout {
+		t.Errorf("got dropped latency %v want at least the timeout %v", get.Latency.Min(), cfg.Timeout)
+	}
+	if got := res.Completed() + res.Dropped; got != res.Scheduled {
+		t.Errorf("got %d completed and dropped want %d scheduled", got, res.Scheduled)
+	}
+}
+
+func TestRunRejectsFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"-rate", "0"},
+		{"-rate", "1e9"},
+		{"-mix", "get=0"},
+		{"-o", "xml"},
+		{"-store", "disk"},
+		{"-users", "-1"},
+		{"-bogus"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run(context.Background(), args, &stdout, &stderr); code != 2 {
+			t.Errorf("%v: got exit %d want 2", args, code)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Command loadgen drives a todoist server with a mix of todo requests at a fixed rate
+// and reports throughput and latency percentiles.
+//
+// Requests are started on schedule whether or not earlier ones have finished (open loop), and
+// each latency is measured from the time its request was due. A slow server therefore shows
+// up as high latency instead of quietly lowering the request rate. Requests that are due while
+// -max-inflight are outstanding are dropped and recorded as failures at the request timeout.
+//
+// Without -target it starts an in-process server backed by the in-memory store, or the sharded
+// one with -store sharded, which gives repeatable numbers for CI:
+//
+//	loadgen -rate 500 -duration 30s -mix get=70,list=10,create=10,update=10
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// maxRate keeps the scheduling interval at a microsecond or more
+const maxRate = 1_000_000
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	target := fs.String("target", "", "base URL of the server under test; empty starts an in-process server")
+	rate := fs.Float64("rate", 100, "requests started per second")
+	duration := fs.Duration("duration", 10*time.Second, "how long to send requests")
+	mix := fs.String("mix", defaultMix, "relative weights of the operations")
+	users := fs.Int("users", 10, "number of users the requests are spread over")
+	seedTodos := fs.Int("seed-todos", 20, "todos created for each user before the run")
+	maxInFlight := fs.Int("max-inflight", 512, "outstanding requests after which due requests are dropped")
+	timeout := fs.Duration("timeout", 5*time.Second, "per-request timeout")
+	seed := fs.Uint64("seed", 1, "seed for choosing operations and users")
+	store := fs.String("store", "memory", "todo storage of the in-process server: memory or sharded")
+	output := fs.String("o", "text", "report format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	m, err := ParseMix(*mix)
+	if err != nil {
+		fmt.Fprintf(stderr, "loadgen: %v\n", err)
+		return 2
+	}
+	if *rate > maxRate {
+		fmt.Fprintf(stderr, "loadgen: -rate above %d is not supported\n", maxRate)
+		return 2
+	}
+	if *rate <= 0 || *duration <= 0 || *users <= 0 || *seedTodos < 0 || *maxInFlight <= 0 || *timeout <= 0 {
+		fmt.Fprintln(stderr, "loadgen: -rate, -duration, -users, -max-inflight and -timeout must be positive")
+		return 2
+	}
+	if *store != "memory" && *store != "sharded" {
+		fmt.Fprintf(stderr, "loadgen: unknown store %q\n", *store)
+		return 2
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(stderr, "loadgen: unknown output format %q\n", *output)
+		return 2
+	}
+
+	cfg := Config{
+		Target:      *target,
+		Rate:        *rate,
+		Duration:    *duration,
+		Mix:         m,
+		Users:       *users,
+		SeedTodos:   *seedTodos,
+		MaxInFlight: *maxInFlight,
+		Timeout:     *timeout,
+		Seed:        *seed,
+	}
+	if cfg.Target == "" {
+		server := newInProcessServer(*store)
+		defer server.Close()
+		cfg.Target = server.URL
+	}
+
+	res, err := Run(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "loadgen: %v\n", err)
+		return 1
+	}
+
+	if *output == "json" {
+		err = writeJSON(stdout, cfg, res)
+	} else {
+		err = writeText(stdout, cfg, res)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "loadgen: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Op is one kind of request the generator sends
+type Op string
+
+const (
+	OpCreate Op = "create"
+	OpGet    Op = "get"
+	OpList   Op = "list"
+	OpUpdate Op = "update"
+	OpDelete Op = "delete"
+)
+
+var allOps = []Op{OpCreate, OpGet, OpList, OpUpdate, OpDelete}
+
+const defaultMix = "create=20,get=40,list=20,update=15,delete=5"
+
+// Mix picks operations at random in proportion to their weights
+type Mix struct {
+	ops []Op
+	// cumulative[i] is the sum of the weights of ops[0..i]
+	cumulative []int
+}
+
+// ParseMix reads weights written as op=weight pairs separated by commas, e.g. get=9,create=1
+func ParseMix(s string) (Mix, error) {
+	var m Mix
+	total := 0
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		op := Op(name)
+		if !ok || !slices.Contains(allOps, op) {
+			return Mix{}, fmt.Errorf("invalid mix entry %q, want op=weight with op one of create, get, list, update, delete", part)
+		}
+		if slices.Contains(m.ops, op) {
+			return Mix{}, fmt.Errorf("%s appears twice in the mix", op)
+		}
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 0 {
+			return Mix{}, fmt.Errorf("invalid weight %q for %s", value, op)
+		}
+		if weight == 0 {
+			continue
+		}
+		total += weight
+		m.ops = append(m.ops, op)
+		m.cumulative = append(m.cumulative, total)
+	}
+	if total == 0 {
+		return Mix{}, fmt.Errorf("mix %q has no operation with a positive weight", s)
+	}
+	return m, nil
+}
+
+func (m Mix) Pick(rng *rand.Rand) Op {
+	n := rng.IntN(m.cumulative[len(m.cumulative)-1])
+	i, _ := slices.BinarySearch(m.cumulative, n+1)
+	return m.ops[i]
+}
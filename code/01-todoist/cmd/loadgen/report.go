@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+var quantiles = []struct {
+	label string
+	q     float64
+}{
+	{"p50", 0.5},
+	{"p90", 0.9},
+	{"p99", 0.99},
+	{"p999", 0.999},
+}
+
+type latencySummary struct {
+	Count     int64              `json:"count"`
+	Errors    int64              `json:"errors"`
+	Dropped   int64              `json:"dropped"`
+	Mean      float64            `json:"meanMs"`
+	Max       float64            `json:"maxMs"`
+	Quantiles map[string]float64 `json:"quantilesMs"`
+}
+
+type jsonReport struct {
+	Target     string                    `json:"target"`
+	Rate       float64                   `json:"rate"`
+	Elapsed    float64                   `json:"elapsedSeconds"`
+	Scheduled  int64                     `json:"scheduled"`
+	Dropped    int64                     `json:"dropped"`
+	Throughput float64                   `json:"throughput"`
+	Total      latencySummary            `json:"total"`
+	Ops        map[string]latencySummary `json:"ops"`
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func summarize(h *Histogram, errors, dropped int64) latencySummary {
+	s := latencySummary{Count: h.Count(), Errors: errors, Dropped: dropped, Mean: ms(h.Mean()), Max: ms(h.Max()), Quantiles: make(map[string]float64)}
+	for _, q := range quantiles {
+		s.Quantiles[q.label] = ms(h.Quantile(q.q))
+	}
+	return s
+}
+
+func writeJSON(w io.Writer, cfg Config, res *Result) error {
+	report := jsonReport{
+		Target:     cfg.Target,
+		Rate:       cfg.Rate,
+		Elapsed:    res.Elapsed.Seconds(),
+		Scheduled:  res.Scheduled,
+		Dropped:    res.Dropped,
+		Throughput: res.Throughput(),
+		Total:      summarize(res.Total(), res.Errors(), res.Dropped),
+		Ops:        make(map[string]latencySummary),
+	}
+	for op, o := range res.Ops {
+		if o.Latency.Count() > 0 {
+			report.Ops[string(op)] = summarize(&o.Latency, o.Errors, o.Dropped)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func writeText(w io.Writer, cfg Config, res *Result) error {
+	total := res.Total()
+	fmt.Fprintf(w, "target:     %s\n", cfg.Target)
+	fmt.Fprintf(w, "requests:   %d scheduled, %d completed, %d dropped, %d failed\n", res.Scheduled, res.Completed(), res.Dropped, res.Errors()-res.Dropped)
+	fmt.Fprintf(w, "throughput: %.1f req/s (target %.1f) over %s\n\n", res.Throughput(), cfg.Rate, res.Elapsed.Round(time.Millisecond))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "OP\tCOUNT\tERRORS\tDROPPED\tMEAN\t")
+	for _, q := range quantiles {
+		fmt.Fprintf(tw, "%s\t", q.label)
+	}
+	fmt.Fprintln(tw, "MAX\t")
+
+	row := func(name string, h *Histogram, errors, dropped int64) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t", name, h.Count(), errors, dropped, round(h.Mean()))
+		for _, q := range quantiles {
+			fmt.Fprintf(tw, "%s\t", round(h.Quantile(q.q)))
+		}
+		fmt.Fprintf(tw, "%s\t\n", round(h.Max()))
+	}
+	for _, op := range allOps {
+		if o := res.Ops[op]; o.Latency.Count() > 0 {
+			row(string(op), &o.Latency, o.Errors, o.Dropped)
+		}
+	}
+	row("all", total, res.Errors(), res.Dropped)
+	return tw.Flush()
+}
+
+// round keeps three significant digits, which is all the histogram measures anyway
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= 100*time.Millisecond:
+		return d.Round(time.Millisecond)
+	case d >= 10*time.Millisecond:
+		return d.Round(100 * time.Microsecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"todoist/client"
+	"todoist/internal/models"
+)
+
+// Config describes one load test
+type Config struct {
+	Target string
+	// Rate is the number of requests started per second, whether or not earlier ones have finished
+	Rate     float64
+	Duration time.Duration
+	Mix      Mix
+	Users    int
+	// SeedTodos is how many todos each user starts with, so reads have something to find
+	SeedTodos   int
+	MaxInFlight int
+	Timeout     time.Duration
+	Seed        uint64
+}
+
+// OpResult holds the outcome of one kind of operation. Dropped requests are counted in
+// Errors and recorded in Latency as well.
+type OpResult struct {
+	Latency Histogram
+	Errors  int64
+	Dropped int64
+}
+
+// Result is what a run measured. Latencies are taken from the moment a request was scheduled,
+// not the moment it was sent, so a server that falls behind cannot hide its queueing delay.
+type Result struct {
+	Elapsed   time.Duration
+	Scheduled int64
+	// Dropped requests were due while MaxInFlight requests were already outstanding. Each is
+	// recorded as a failure that took at least the request timeout, so the percentiles keep
+	// the requests a saturated server could not take instead of losing them.
+	Dropped int64
+	Ops     map[Op]*OpResult
+}
+
+func (r *Result) Total() *Histogram {
+	var h Histogram
+	for _, o := range r.Ops {
+		h.Merge(&o.Latency)
+	}
+	return &h
+}
+
+func (r *Result) Errors() int64 {
+	var n int64
+	for _, o := range r.Ops {
+		n += o.Errors
+	}
+	return n
+}
+
+// Completed is the number of requests that were sent and answered, successfully or not
+func (r *Result) Completed() int64 {
+	return r.Total().Count() - r.Dropped
+}
+
+// Throughput is completed requests per second of wall time
+func (r *Result) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Completed()) / r.Elapsed.Seconds()
+}
+
+type runner struct {
+	cfg   Config
+	api   *client.Client
+	http  *http.Client
+	users []string
+	pool  *idPool
+
+	mu  sync.Mutex
+	ops map[Op]*OpResult
+}
+
+// Run sets up the users and their todos, then drives the target at cfg.Rate for cfg.Duration
+func Run(ctx context.Context, cfg Config) (*Result, error) {
+	hc := &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: &http.Transport{MaxIdleConns: cfg.MaxInFlight, MaxIdleConnsPerHost: cfg.MaxInFlight},
+	}
+	defer hc.CloseIdleConnections()
+
+	api, err := client.New(cfg.Target, client.WithHTTPClient(hc), client.WithRetries(0, 0, 0), client.WithPageSize(pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &runner{cfg: cfg, api: api, http: hc, pool: newIDPool(), ops: make(map[Op]*OpResult)}
+	for _, op := range allOps {
+		r.ops[op] = &OpResult{}
+	}
+	if err := r.setup(ctx); err != nil {
+		return nil, fmt.Errorf("setup: %w", err)
+	}
+	return r.run(ctx), nil
+}
+
+func (r *runner) setup(ctx context.Context) error {
+	for i := range r.cfg.Users {
+		user := fmt.Sprintf("loadgen-%d", i)
+		if err := r.register(ctx, user); err != nil {
+			return err
+		}
+		r.users = append(r.users, user)
+
+		for j := range r.cfg.SeedTodos {
+			t, err := r.api.CreateTodo(ctx, models.CreateTodo{UserID: user, Title: fmt.Sprintf("seed %d", j)})
+			if err != nil {
+				return err
+			}
+			r.pool.add(user, t.ID)
+		}
+	}
+	return nil
+}
+
+// register creates the user unless it exists from an earlier run. The client has no
+// registration call since the API treats users as an administrative concern.
+func (r *runner) register(ctx context.Context, user string) error {
+	body, _ := json.Marshal(models.RegisterUser{ID: user, Password: "loadgen password " + user})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(r.cfg.Target, "/")+"/users", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	// 404 means the server does not check users at all
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusConflict && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("registering %s: %s", user, resp.Status)
+	}
+	return nil
+}
+
+// run schedules requests at fixed intervals without waiting for responses (open loop)
+func (r *runner) run(ctx context.Context) *Result {
+	rng := rand.New(rand.NewPCG(r.cfg.Seed, r.cfg.Seed))
+	res := &Result{Ops: r.ops}
+
+	var wg sync.WaitGroup
+	var inFlight atomic.Int64
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	start := time.Now()
+	for i := 0; ; i++ {
+		due := start.Add(time.Duration(float64(i) * float64(time.Second) / r.cfg.Rate))
+		if due.Sub(start) >= r.cfg.Duration {
+			break
+		}
+		if wait := time.Until(due); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-ctx.Done():
+				wg.Wait()
+				res.Elapsed = time.Since(start)
+				return res
+			case <-timer.C:
+			}
+		}
+
+		res.Scheduled++
+		// the todo is chosen here too, since rng is not safe to share with the requests
+		op, user, n := r.cfg.Mix.Pick(rng), r.users[rng.IntN(len(r.users))], rng.Uint64()
+		if inFlight.Load() >= int64(r.cfg.MaxInFlight) {
+			res.Dropped++
+			r.drop(op, due)
+			continue
+		}
+
+		inFlight.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer inFlight.Add(-1)
+			op, err := r.do(ctx, op, user, n)
+			r.record(op, due, err)
+		}()
+	}
+
+	wg.Wait()
+	res.Elapsed = time.Since(start)
+	return res
+}
+
+func (r *runner) record(op Op, due time.Time, err error) {
+	latency := time.Since(due)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o := r.ops[op]
+	o.Latency.Record(latency)
+	if err != nil {
+		o.Errors++
+	}
+}
+
+// drop records a request that was never sent as a failure. It could not have been answered
+// before the request timeout ran out any sooner than one that was sent, so that is its latency.
+func (r *runner) drop(op Op, due time.Time) {
+	latency := max(time.Since(due), r.cfg.Timeout)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o := r.ops[op]
+	o.Latency.Record(latency)
+	o.Errors++
+	o.Dropped++
+}
+
+// do performs op for user and reports what it actually did: operations on an existing todo
+// turn into a create when the user has none left. n chooses the todo.
+func (r *runner) do(ctx context.Context, op Op, user string, n uint64) (Op, error) {
+	switch op {
+	case OpGet, OpUpdate:
+		id, ok := r.pool.pick(user, n)
+		if !ok {
+			break
+		}
+		if op == OpGet {
+			_, err := r.api.GetTodo(ctx, id)
+			return op, err
+		}
+		title := fmt.Sprintf("updated at %s", time.Now().Format(time.RFC3339Nano))
+		_, err := r.api.UpdateTodo(ctx, models.UpdateTodo{ID: id, Title: &title})
+		return op, err
+
+	case OpDelete:
+		id, ok := r.pool.take(user, n)
+		if !ok {
+			break
+		}
+		return op, r.api.DeleteTodo(ctx, id)
+
+	case OpList:
+		// one page, so every list is a single request
+		n := 0
+		for _, err := range r.api.Todos(ctx, user) {
+			if err != nil {
+				return op, err
+			}
+			if n++; n == pageSize {
+				break
+			}
+		}
+		return op, nil
+	}
+
+	t, err := r.api.CreateTodo(ctx, models.CreateTodo{UserID: user, Title: "loadgen"})
+	if err == nil {
+		r.pool.add(user, t.ID)
+	}
+	return OpCreate, err
+}
+
+// pageSize is how many todos a list request asks for
+const pageSize = 100
+
+// idPool tracks the todos each user has, so reads and writes hit records that exist
+type idPool struct {
+	mu  sync.Mutex
+	ids map[string][]int
+}
+
+func newIDPool() *idPool {
+	return &idPool{ids: make(map[string][]int)}
+}
+
+func (p *idPool) add(user string, id int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ids[user] = append(p.ids[user], id)
+}
+
+// pick returns one of the user's todos, chosen by n
+func (p *idPool) pick(user string, n uint64) (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := p.ids[user]
+	if len(ids) == 0 {
+		return 0, false
+	}
+	return ids[n%uint64(len(ids))], true
+}
+
+// take removes the todo before it is deleted, so concurrent reads rarely pick a todo that is going away
+func (p *idPool) take(user string, n uint64) (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := p.ids[user]
+	if len(ids) == 0 {
+		return 0, false
+	}
+	i := n % uint64(len(ids))
+	id := ids[i]
+	ids[i] = ids[len(ids)-1]
+	p.ids[user] = ids[:len(ids)-1]
+	return id, true
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/handlers"
+	"todoist/internal/repositories"
+	"todoist/internal/services"
+)
+
+// newInProcessServer serves the todo endpoints the way cmd/api wires them, on a loopback port,
+// so a run measures the HTTP stack and the service without depending on a deployment.
+func newInProcessServer(store string) *httptest.Server {
+	userRepo := repositories.NewInMemoryUserRepo()
+	// registration only happens during setup and is not what is being measured
+	userHandler := handlers.NewUserHandler(services.NewUserService(userRepo, services.WithPasswordIterations(1)))
+
+	var repo repositories.TodoRepository = repositories.NewInMemoryTodoRepo()
+	if store == "sharded" {
+		repo = repositories.NewShardedTodoRepo(0)
+	}
+
+	bus := events.NewBus(256)
+	service := services.NewTodoService(repo, services.WithEvents(bus), services.WithUsers(userRepo))
+	handler := handlers.NewTodoHandler(service)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /users", userHandler.Register)
+	mux.HandleFunc("/todos", handler.CreateTodoHandler)
+	mux.HandleFunc("/todos/", handler.TodoByIDHandler)
+	mux.HandleFunc("/users/", handler.UsersHandler)
+
+	return httptest.NewServer(handlers.Timeout(10*time.Second, mux))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+	"todoist/client"
+)
+
+// admin runs backup and restore; the profile's token must be the server's admin token
+func (c *cli) admin(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: want backup or restore", errUsage)
+	}
+
+	switch args[0] {
+	case "backup":
+		fs := flag.NewFlagSet("admin backup", flag.ContinueOnError)
+		file := fs.String("f", "", "file to write the archive to")
+		rest, err := parseArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(rest) != 0 {
+			return fmt.Errorf("%w: unexpected arguments %v", errUsage, rest)
+		}
+		if *file == "" {
+			return c.api.Backup(ctx, c.stdout)
+		}
+		return c.backupToFile(ctx, *file)
+
+	case "restore":
+		if len(args) != 2 {
+			return fmt.Errorf("%w: want an archive file", errUsage)
+		}
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		summary, err := c.api.Restore(ctx, f)
+		if err != nil {
+			return err
+		}
+		return printSummary(c.stdout, c.output, summary)
+	}
+
+	return fmt.Errorf("%w: unknown admin action %q", errUsage, args[0])
+}
+
+// backupToFile downloads next to path and renames at the end, so a failed download never replaces a good archive
+func (c *cli) backupToFile(ctx context.Context, path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := c.api.Backup(ctx, tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	if c.output == outputTable {
+		fmt.Fprintf(c.stdout, "wrote %s\n", path)
+	}
+	return nil
+}
+
+func printSummary(w io.Writer, format string, s client.BackupSummary) error {
+	sections := make([]string, 0, len(s.Counts))
+	for name := range s.Counts {
+		sections = append(sections, name)
+	}
+	sort.Strings(sections)
+
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case outputYAML:
+		fmt.Fprintf(w, "version: %d\ncreatedAt: %s\ncounts:\n", s.Version, s.CreatedAt.Format(time.RFC3339))
+		for _, name := range sections {
+			fmt.Fprintf(w, "  %s: %d\n", name, s.Counts[name])
+		}
+		return nil
+	}
+
+	fmt.Fprintf(w, "restored archive from %s\n", s.CreatedAt.Local().Format(time.DateTime))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SECTION\tRECORDS")
+	for _, name := range sections {
+		fmt.Fprintf(tw, "%s\t%d\n", name, s.Counts[name])
+	}
+	return tw.Flush()
+}
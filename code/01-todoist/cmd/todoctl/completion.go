@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+var commandNames = []string{"add", "list", "show", "edit", "done", "mv", "rm", "search", "config", "admin", "completion"}
+
+const bashCompletion = `# bash completion for todoctl
+_todoctl() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    if [ "$COMP_CWORD" -eq 1 ]; then
+        COMPREPLY=( $(compgen -W "{{commands}}" -- "$cur") )
+        return
+    fi
+    case "${COMP_WORDS[1]}" in
+        completion) COMPREPLY=( $(compgen -W "bash zsh fish" -- "$cur") ) ;;
+        config) COMPREPLY=( $(compgen -W "set use show" -- "$cur") ) ;;
+        admin) COMPREPLY=( $(compgen -W "backup restore" -- "$cur") ) ;;
+        *) COMPREPLY=( $(compgen -W "-o -profile -user" -- "$cur") ) ;;
+    esac
+}
+complete -F _todoctl todoctl
+`
+
+const zshCompletion = `#compdef todoctl
+_todoctl() {
+    local -a commands
+    commands=({{quoted}})
+    if (( CURRENT == 2 )); then
+        _describe 'command' commands
+        return
+    fi
+    case "$words[2]" in
+        completion) _values 'shell' bash zsh fish ;;
+        config) _values 'action' set use show ;;
+        admin) _values 'action' backup restore ;;
+        *) _arguments '-o[output format]:format:(table json yaml)' '-profile[config profile]:profile:' '-user[user id]:user:' ;;
+    esac
+}
+_todoctl "$@"
+`
+
+const fishCompletion = `# fish completion for todoctl
+complete -c todoctl -f
+complete -c todoctl -n '__fish_use_subcommand' -a '{{commands}}'
+complete -c todoctl -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'
+complete -c todoctl -n '__fish_seen_subcommand_from config' -a 'set use show'
+complete -c todoctl -n '__fish_seen_subcommand_from admin' -a 'backup restore'
+complete -c todoctl -o o -d 'output format' -xa 'table json yaml'
+complete -c todoctl -o profile -d 'config profile' -x
+complete -c todoctl -o user -d 'user id' -x
+`
+
+func writeCompletion(w io.Writer, shell string) error {
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		return fmt.Errorf("unsupported shell %q, want bash, zsh or fish", shell)
+	}
+
+	r := strings.NewReplacer(
+		"{{commands}}", strings.Join(commandNames, " "),
+		"{{quoted}}", "'"+strings.Join(commandNames, "' '")+"'",
+	)
+	_, err := r.WriteString(w, script)
+	return err
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Profile holds the connection settings for one todoist server
+type Profile struct {
+	Server string `json:"server"`
+	Token  string `json:"token,omitempty"`
+	User   string `json:"user,omitempty"`
+}
+
+type Config struct {
+	Current  string             `json:"current"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+const defaultServer = "http://localhost:8080"
+
+// configPath honours TODOCTL_CONFIG and otherwise uses the user's config directory
+func configPath(getenv func(string) string) (string, error) {
+	if p := getenv("TODOCTL_CONFIG"); p != "" {
+		return p, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "todoctl", "config.json"), nil
+}
+
+// loadConfig returns an empty config when the file does not exist yet
+func loadConfig(path string) (Config, error) {
+	cfg := Config{Profiles: make(map[string]Profile)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]Profile)
+	}
+	return cfg, nil
+}
+
+func saveConfig(path string, cfg Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	// the file holds tokens, keep it private
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// resolve picks the named profile, falling back to the current one, and applies environment overrides
+func (c Config) resolve(name string, getenv func(string) string) (Profile, error) {
+	if name == "" {
+		name = getenv("TODOCTL_PROFILE")
+	}
+	if name == "" {
+		name = c.Current
+	}
+
+	p := Profile{Server: defaultServer}
+	if name != "" {
+		found, ok := c.Profiles[name]
+		if !ok {
+			return Profile{}, fmt.Errorf("unknown profile %q", name)
+		}
+		p = found
+	}
+
+	if v := getenv("TODOCTL_SERVER"); v != "" {
+		p.Server = v
+	}
+	if v := getenv("TODOCTL_TOKEN"); v != "" {
+		p.Token = v
+	}
+	if v := getenv("TODOCTL_USER"); v != "" {
+		p.User = v
+	}
+	return p, nil
+}
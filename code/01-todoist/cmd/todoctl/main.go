@@ -0,0 +1,452 @@
+// Command todoctl manages todos on a todoist server from the terminal.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todoist/client"
+	"todoist/internal/models"
+)
+
+const usage = `usage: todoctl [-profile name] [-o table|json|yaml] [-user id] <command> [args]
+
+commands:
+  add [-d description] [-due date] <title>   create a todo
+  list [-status s]                            list the user's todos
+  search <query>                              list todos whose title or description match
+  show <id>                                   show one todo
+  edit [-title t] [-d description] [-status s] [-due date] <id>
+  done <id>                                   mark a todo completed
+  mv [-after id] [-before id] <id>            move a todo between two others
+  rm <id>                                     delete a todo
+  config set [-server url] [-token t] [-user id] <profile>
+  config use <profile>
+  config show
+  admin backup [-f file]                      download a backup archive, to stdout without -f
+  admin restore <file>                        replace everything on the server with an archive
+  completion bash|zsh|fish                    print a shell completion script
+`
+
+// errUsage is returned for malformed invocations; run prints the usage text for it
+var errUsage = errors.New("invalid usage")
+
+type cli struct {
+	stdout  io.Writer
+	getenv  func(string) string
+	cfgPath string
+	cfg     Config
+	profile Profile
+	output  string
+	api     *client.Client
+}
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr, os.Getenv))
+}
+
+// run executes one todoctl invocation and returns the process exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, getenv func(string) string) int {
+	global := flag.NewFlagSet("todoctl", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	profileName := global.String("profile", "", "config profile to use")
+	output := global.String("o", outputTable, "output format: table, json or yaml")
+	user := global.String("user", "", "user id, overrides the profile")
+	server := global.String("server", "", "server URL, overrides the profile")
+
+	if err := global.Parse(args); err != nil || global.NArg() == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	if !validOutput(*output) {
+		fmt.Fprintf(stderr, "todoctl: unknown output format %q\n", *output)
+		return 2
+	}
+
+	c := &cli{stdout: stdout, getenv: getenv, output: *output}
+
+	var err error
+	if c.cfgPath, err = configPath(getenv); err == nil {
+		c.cfg, err = loadConfig(c.cfgPath)
+	}
+	if err == nil {
+		c.profile, err = c.cfg.resolve(*profileName, getenv)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "todoctl: %v\n", err)
+		return 1
+	}
+
+	if *user != "" {
+		c.profile.User = *user
+	}
+	if *server != "" {
+		c.profile.Server = *server
+	}
+	c.api, err = client.New(c.profile.Server, client.WithToken(c.profile.Token))
+	if err != nil {
+		fmt.Fprintf(stderr, "todoctl: %v\n", err)
+		return 1
+	}
+
+	cmd, rest := global.Arg(0), global.Args()[1:]
+	err = c.dispatch(ctx, cmd, rest)
+
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(stderr, "todoctl %s: %v\n\n%s", cmd, err, usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "todoctl %s: %v\n", cmd, err)
+		return 1
+	}
+	return 0
+}
+
+func (c *cli) dispatch(ctx context.Context, cmd string, args []string) error {
+	switch cmd {
+	case "add":
+		return c.add(ctx, args)
+	case "list":
+		return c.list(ctx, args)
+	case "search":
+		return c.search(ctx, args)
+	case "show":
+		return c.show(ctx, args)
+	case "edit":
+		return c.edit(ctx, args)
+	case "done":
+		return c.done(ctx, args)
+	case "rm":
+		return c.rm(ctx, args)
+	case "mv":
+		return c.mv(ctx, args)
+	case "config":
+		return c.config(args)
+	case "admin":
+		return c.admin(ctx, args)
+	case "completion":
+		if len(args) != 1 {
+			return fmt.Errorf("%w: want a shell name", errUsage)
+		}
+		return writeCompletion(c.stdout, args[0])
+	}
+	return fmt.Errorf("%w: unknown command %q", errUsage, cmd)
+}
+
+// parseArgs parses fs allowing flags before and after positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func parseID(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%w: want exactly one todo id", errUsage)
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: invalid todo id %q", errUsage, args[0])
+	}
+	return id, nil
+}
+
+func parseDue(s string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid due date %q, want RFC 3339 or YYYY-MM-DD", s)
+}
+
+func (c *cli) add(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	description := fs.String("d", "", "description")
+	due := fs.String("due", "", "due date, RFC 3339 or YYYY-MM-DD")
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		return fmt.Errorf("%w: want a title", errUsage)
+	}
+	if c.profile.User == "" {
+		return errors.New("no user set, use -user or configure one in the profile")
+	}
+
+	dto := models.CreateTodo{
+		UserID:      c.profile.User,
+		Title:       strings.Join(rest, " "),
+		Description: *description,
+	}
+	if *due != "" {
+		if dto.DueAt, err = parseDue(*due); err != nil {
+			return err
+		}
+	}
+
+	todo, err := c.api.CreateTodo(ctx, dto)
+	if err != nil {
+		return err
+	}
+	return printTodo(c.stdout, c.output, todo)
+}
+
+func (c *cli) list(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	status := fs.String("status", "", "only todos with this status")
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return fmt.Errorf("%w: unexpected arguments %v", errUsage, rest)
+	}
+
+	filter := models.TodoFilter{}
+	if *status != "" {
+		s := models.TodoStatus(strings.ToUpper(*status))
+		filter.Status = &s
+	}
+	return c.listFiltered(ctx, filter)
+}
+
+func (c *cli) search(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: want a query", errUsage)
+	}
+	return c.listFiltered(ctx, models.TodoFilter{Query: strings.Join(args, " ")})
+}
+
+func (c *cli) listFiltered(ctx context.Context, filter models.TodoFilter) error {
+	if c.profile.User == "" {
+		return errors.New("no user set, use -user or configure one in the profile")
+	}
+
+	todos, err := c.api.ListTodos(ctx, c.profile.User)
+	if err != nil {
+		return err
+	}
+
+	matched := make([]models.Todo, 0, len(todos))
+	for _, t := range todos {
+		if filter.Matches(t) {
+			matched = append(matched, t)
+		}
+	}
+	return printTodos(c.stdout, c.output, matched)
+}
+
+func (c *cli) show(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	todo, err := c.api.GetTodo(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printTodo(c.stdout, c.output, todo)
+}
+
+func (c *cli) edit(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+	title := fs.String("title", "", "new title")
+	description := fs.String("d", "", "new description")
+	status := fs.String("status", "", "new status")
+	due := fs.String("due", "", "new due date")
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(rest)
+	if err != nil {
+		return err
+	}
+
+	// only send the flags that were given, so empty values can still be set explicitly
+	dto := models.UpdateTodo{ID: id}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			dto.Title = title
+		case "d":
+			dto.Description = description
+		case "status":
+			s := models.TodoStatus(strings.ToUpper(*status))
+			dto.Status = &s
+		}
+	})
+	if *due != "" {
+		if dto.DueAt, err = parseDue(*due); err != nil {
+			return err
+		}
+	}
+
+	todo, err := c.api.UpdateTodo(ctx, dto)
+	if err != nil {
+		return err
+	}
+	return printTodo(c.stdout, c.output, todo)
+}
+
+func (c *cli) done(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	status := models.StatusCompleted
+	todo, err := c.api.UpdateTodo(ctx, models.UpdateTodo{ID: id, Status: &status})
+	if err != nil {
+		return err
+	}
+	return printTodo(c.stdout, c.output, todo)
+}
+
+func (c *cli) mv(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("mv", flag.ContinueOnError)
+	after := fs.Int("after", 0, "place directly after this todo")
+	before := fs.Int("before", 0, "place directly before this todo")
+
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(rest)
+	if err != nil {
+		return err
+	}
+
+	dto := models.MoveTodo{ID: id}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "after":
+			dto.After = after
+		case "before":
+			dto.Before = before
+		}
+	})
+	if dto.After == nil && dto.Before == nil {
+		return fmt.Errorf("%w: want -after, -before or both", errUsage)
+	}
+
+	todo, err := c.api.MoveTodo(ctx, dto)
+	if err != nil {
+		return err
+	}
+	return printTodo(c.stdout, c.output, todo)
+}
+
+func (c *cli) rm(ctx context.Context, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	if err := c.api.DeleteTodo(ctx, id); err != nil {
+		return err
+	}
+	if c.output == outputTable {
+		fmt.Fprintf(c.stdout, "deleted todo %d\n", id)
+	}
+	return nil
+}
+
+func (c *cli) config(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: want set, use or show", errUsage)
+	}
+
+	switch args[0] {
+	case "set":
+		fs := flag.NewFlagSet("config set", flag.ContinueOnError)
+		server := fs.String("server", "", "server URL")
+		token := fs.String("token", "", "API token")
+		user := fs.String("user", "", "user id")
+
+		rest, err := parseArgs(fs, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(rest) != 1 {
+			return fmt.Errorf("%w: want a profile name", errUsage)
+		}
+
+		p, ok := c.cfg.Profiles[rest[0]]
+		if !ok {
+			p.Server = defaultServer
+		}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "server":
+				p.Server = *server
+			case "token":
+				p.Token = *token
+			case "user":
+				p.User = *user
+			}
+		})
+		c.cfg.Profiles[rest[0]] = p
+		if c.cfg.Current == "" {
+			c.cfg.Current = rest[0]
+		}
+		return saveConfig(c.cfgPath, c.cfg)
+
+	case "use":
+		if len(args) != 2 {
+			return fmt.Errorf("%w: want a profile name", errUsage)
+		}
+		if _, ok := c.cfg.Profiles[args[1]]; !ok {
+			return fmt.Errorf("unknown profile %q", args[1])
+		}
+		c.cfg.Current = args[1]
+		return saveConfig(c.cfgPath, c.cfg)
+
+	case "show":
+		names := make([]string, 0, len(c.cfg.Profiles))
+		for name := range c.cfg.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			p := c.cfg.Profiles[name]
+			marker := " "
+			if name == c.cfg.Current {
+				marker = "*"
+			}
+			token := ""
+			if p.Token != "" {
+				token = " token=<set>"
+			}
+			fmt.Fprintf(c.stdout, "%s %s server=%s user=%s%s\n", marker, name, p.Server, p.User, token)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: unknown config action %q", errUsage, args[0])
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"todoist/internal/models"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+func validOutput(format string) bool {
+	return format == outputTable || format == outputJSON || format == outputYAML
+}
+
+func printTodos(w io.Writer, format string, todos []models.Todo) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(todos)
+	case outputYAML:
+		if len(todos) == 0 {
+			_, err := fmt.Fprintln(w, "[]")
+			return err
+		}
+		for _, t := range todos {
+			writeYAMLTodo(w, t, "- ", "  ")
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tDUE\tTITLE")
+	for _, t := range todos {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", t.ID, t.Status, formatDue(t.DueAt), t.Title)
+	}
+	return tw.Flush()
+}
+
+func printTodo(w io.Writer, format string, t models.Todo) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(t)
+	case outputYAML:
+		writeYAMLTodo(w, t, "", "")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID:\t%d\n", t.ID)
+	fmt.Fprintf(tw, "Title:\t%s\n", t.Title)
+	fmt.Fprintf(tw, "Description:\t%s\n", t.Description)
+	fmt.Fprintf(tw, "Status:\t%s\n", t.Status)
+	fmt.Fprintf(tw, "Due:\t%s\n", formatDue(t.DueAt))
+	fmt.Fprintf(tw, "User:\t%s\n", t.UserID)
+	fmt.Fprintf(tw, "Created:\t%s\n", t.CreatedAt.Local().Format(time.DateTime))
+	fmt.Fprintf(tw, "Updated:\t%s\n", t.UpdatedAt.Local().Format(time.DateTime))
+	return tw.Flush()
+}
+
+// writeYAMLTodo writes a flat YAML mapping; first prefixes the first key and indent the rest
+func writeYAMLTodo(w io.Writer, t models.Todo, first, indent string) {
+	fields := []struct{ key, value string }{
+		{"id", strconv.Itoa(t.ID)},
+		{"userid", yamlString(t.UserID)},
+		{"title", yamlString(t.Title)},
+		{"description", yamlString(t.Description)},
+		{"status", string(t.Status)},
+	}
+	if t.DueAt != nil {
+		fields = append(fields, struct{ key, value string }{"dueAt", t.DueAt.Format(time.RFC3339)})
+	}
+	fields = append(fields,
+		struct{ key, value string }{"createdAt", t.CreatedAt.Format(time.RFC3339)},
+		struct{ key, value string }{"updatedAt", t.UpdatedAt.Format(time.RFC3339)},
+	)
+
+	for i, f := range fields {
+		prefix := indent
+		if i == 0 {
+			prefix = first
+		}
+		fmt.Fprintf(w, "%s%s: %s\n", prefix, f.key, f.value)
+	}
+}
+
+// yamlString quotes values that YAML would otherwise misread
+func yamlString(s string) string {
+	if s == "" || strings.ContainsAny(s, ":#{}[],&*!|>'\"%@`\n\t") || strings.TrimSpace(s) != s {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "null", "~":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func formatDue(due *time.Time) string {
+	if due == nil {
+		return "-"
+	}
+	return due.Local().Format("2006-01-02 15:04")
+}
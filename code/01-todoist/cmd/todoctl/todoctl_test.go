@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"todoist/internal/backup"
+	"todoist/internal/handlers"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+	"todoist/internal/services"
+)
+
+type harness struct {
+	t   *testing.T
+	env map[string]string
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+
+	todoRepo := repositories.NewInMemoryTodoRepo()
+	handler := handlers.NewTodoHandler(services.NewTodoService(todoRepo))
+	admin := handlers.NewAdminHandler(backup.Stores{
+		Users:        repositories.NewInMemoryUserRepo(),
+		Projects:     repositories.NewInMemoryProjectRepo(),
+		Todos:        todoRepo,
+		Dependencies: repositories.NewInMemoryDependencyRepo(),
+		Comments:     repositories.NewInMemoryCommentRepo(),
+		Attachments:  repositories.NewInMemoryAttachmentRepo(),
+		TimeEntries:  repositories.NewInMemoryTimeEntryRepo(),
+		Webhooks:     repositories.NewInMemoryWebhookRepo(),
+	}, "admin-token")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/todos", handler.CreateTodoHandler)
+	mux.HandleFunc("/todos/", handler.TodoByIDHandler)
+	mux.HandleFunc("/users/", handler.UsersHandler)
+	mux.HandleFunc("POST /todos/{id}/move", handler.Move)
+	mux.HandleFunc("GET /admin/backup", admin.Backup)
+	mux.HandleFunc("POST /admin/restore", admin.Restore)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &harness{
+		t: t,
+		env: map[string]string{
+			"TODOCTL_CONFIG": filepath.Join(t.TempDir(), "config.json"),
+			"TODOCTL_SERVER": server.URL,
+			"TODOCTL_USER":   "alice",
+		},
+	}
+}
+
+// run invokes todoctl and fails the test unless it exits with want
+func (h *harness) run(want int, args ...string) string {
+	h.t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	getenv := func(k string) string { return h.env[k] }
+	if code := run(context.Background(), args, &stdout, &stderr, getenv); code != want {
+		h.t.Fatalf("todoctl %v: got exit %d want %d\nstderr: %s", args, code, want, stderr.String())
+	}
+	if want != 0 {
+		return stderr.String()
+	}
+	return stdout.String()
+}
+
+func (h *harness) todo(args ...string) models.Todo {
+	h.t.Helper()
+
+	var todo models.Todo
+	out := h.run(0, append([]string{"-o", "json"}, args...)...)
+	if err := json.Unmarshal([]byte(out), &todo); err != nil {
+		h.t.Fatalf("decoding %q: %v", out, err)
+	}
+	return todo
+}
+
+func TestTodoctlLifecycle(t *testing.T) {
+	h := newHarness(t)
+
+	created := h.todo("add", "-d", "monthly", "Pay", "rent", "-due", "2026-03-01")
+	if created.Title != "Pay rent" || created.Description != "monthly" || created.DueAt == nil {
+		t.Fatalf("got %+v want title, description and due set", created)
+	}
+	h.todo("add", "Buy milk")
+
+	list := h.run(0, "list")
+	if !strings.Contains(list, "Pay rent") || !strings.Contains(list, "Buy milk") || !strings.HasPrefix(list, "ID") {
+		t.Errorf("unexpected table:\n%s", list)
+	}
+
+	search := h.run(0, "search", "MILK")
+	if strings.Contains(search, "Pay rent") || !strings.Contains(search, "Buy milk") {
+		t.Errorf("unexpected search result:\n%s", search)
+	}
+
+	edited := h.todo("edit", "-title", "Pay the rent", "1")
+	if edited.Title != "Pay the rent" || edited.Description != "monthly" {
+		t.Errorf("got %+v want only the title changed", edited)
+	}
+
+	done := h.todo("done", "1")
+	if done.Status != models.StatusCompleted {
+		t.Errorf("got status %s want %s", done.Status, models.StatusCompleted)
+	}
+
+	pending := h.run(0, "list", "-status", "pending")
+	if strings.Contains(pending, "Pay the rent") {
+		t.Errorf("completed todo listed as pending:\n%s", pending)
+	}
+
+	shown := h.run(0, "-o", "yaml", "show", "1")
+	if !strings.Contains(shown, `title: "Pay the rent"`) && !strings.Contains(shown, "title: Pay the rent") {
+		t.Errorf("unexpected yaml:\n%s", shown)
+	}
+	if !strings.Contains(shown, "status: COMPLETED") {
+		t.Errorf("unexpected yaml:\n%s", shown)
+	}
+
+	if out := h.run(0, "rm", "1"); out != "deleted todo 1\n" {
+		t.Errorf("got %q", out)
+	}
+	if stderr := h.run(1, "show", "1"); !strings.Contains(stderr, "Not Found") {
+		t.Errorf("got %q want not found error", stderr)
+	}
+}
+
+func TestTodoctlMove(t *testing.T) {
+	h := newHarness(t)
+	for _, title := range []string{"first", "second", "third"} {
+		h.todo("add", title)
+	}
+
+	h.todo("mv", "-before", "1", "3")
+	h.todo("mv", "2", "-after", "3", "-before", "1")
+
+	var list []models.Todo
+	if err := json.Unmarshal([]byte(h.run(0, "-o", "json", "list")), &list); err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, todo := range list {
+		order = append(order, todo.Title)
+	}
+	if got := strings.Join(order, ","); got != "third,second,first" {
+		t.Errorf("got order %s want third,second,first", got)
+	}
+
+	h.run(2, "mv", "1")
+}
+
+func TestTodoctlErrors(t *testing.T) {
+	h := newHarness(t)
+
+	h.run(2)
+	h.run(2, "frobnicate")
+	h.run(2, "show", "abc")
+	h.run(2, "-o", "xml", "list")
+
+	if stderr := h.run(1, "add", strings.Repeat("x", 300)); !strings.Contains(stderr, "invalid input") {
+		t.Errorf("got %q want server validation error", stderr)
+	}
+}
+
+func TestTodoctlBackup(t *testing.T) {
+	h := newHarness(t)
+	h.todo("add", "keep me")
+	file := filepath.Join(t.TempDir(), "todoist.backup")
+
+	if stderr := h.run(1, "admin", "backup", "-f", file); !strings.Contains(stderr, "Unauthorized") {
+		t.Errorf("got %q want unauthorized without the admin token", stderr)
+	}
+
+	h.env["TODOCTL_TOKEN"] = "admin-token"
+	if out := h.run(0, "admin", "backup", "-f", file); out != "wrote "+file+"\n" {
+		t.Errorf("got %q", out)
+	}
+	h.run(0, "rm", "1")
+	h.todo("add", "lost")
+
+	restored := h.run(0, "admin", "restore", file)
+	if !strings.Contains(restored, "todos") || !strings.Contains(restored, "restored archive") {
+		t.Errorf("unexpected summary:\n%s", restored)
+	}
+	if list := h.run(0, "list"); !strings.Contains(list, "keep me") || strings.Contains(list, "lost") {
+		t.Errorf("got\n%s\nwant only the backed up todo", list)
+	}
+
+	h.run(2, "admin", "restore")
+	h.run(1, "admin", "restore", filepath.Join(t.TempDir(), "missing"))
+}
+
+func TestTodoctlProfiles(t *testing.T) {
+	h := newHarness(t)
+	server := h.env["TODOCTL_SERVER"]
+	delete(h.env, "TODOCTL_SERVER")
+	delete(h.env, "TODOCTL_USER")
+
+	h.run(0, "config", "set", "-server", "http://127.0.0.1:1", "-user", "nobody", "broken")
+	h.run(0, "config", "set", "-server", server, "-user", "bob", "-token", "t0k3n", "work")
+	h.run(0, "config", "use", "work")
+
+	h.todo("add", "from profile")
+	if out := h.run(0, "-profile", "work", "list"); !strings.Contains(out, "from profile") {
+		t.Errorf("got %q want todo created through the work profile", out)
+	}
+
+	shown := h.run(0, "config", "show")
+	if !strings.Contains(shown, "* work") || strings.Contains(shown, "t0k3n") {
+		t.Errorf("unexpected config listing:\n%s", shown)
+	}
+
+	h.run(1, "-profile", "broken", "list")
+	h.run(1, "-profile", "missing", "list")
+}
+
+func TestTodoctlCompletion(t *testing.T) {
+	h := newHarness(t)
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		out := h.run(0, "completion", shell)
+		if !strings.Contains(out, "search") || strings.Contains(out, "{{") {
+			t.Errorf("%s: unexpected script:\n%s", shell, out)
+		}
+	}
+	h.run(1, "completion", "powershell")
+}
@@ -0,0 +1,211 @@
+// Package backup writes and reads point-in-time archives of the in-memory stores.
+//
+// An archive is a gzip stream of newline-separated JSON values:
+//
+//	{"format":"todoist-backup","version":1,"createdAt":"2026-03-09T10:00:00Z"}
+//	{"section":"users","count":2}
+//	{...one record per line...}
+//	{"section":"projects","count":0}
+//	...
+//	{"sha256":"<hex>"}
+//
+// The trailer's SHA-256 covers every byte before it, so a truncated or edited archive is
+// rejected before anything is restored. Readers refuse versions newer than their own.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+)
+
+const (
+	Format  = "todoist-backup"
+	Version = 1
+
+	// maxLine bounds a single record, well above anything the services accept
+	maxLine = 16 << 20
+)
+
+// maxArchiveSize bounds the decompressed archive, which Restore holds in memory before
+// replacing anything. A small compressed body can expand far beyond its own size.
+var maxArchiveSize int64 = 4 << 30
+
+var (
+	// ErrCorrupt covers archives that are truncated, malformed or fail the checksum
+	ErrCorrupt = errors.New("backup archive is corrupt")
+	// ErrVersion is returned for archives written by a newer format version
+	ErrVersion = errors.New("unsupported backup version")
+	// ErrTooLarge is returned once a decompressed archive grows past maxArchiveSize
+	ErrTooLarge = errors.New("backup archive too large")
+)
+
+type header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// entry is a section header or the trailer; exactly one of Section and SHA256 is set
+type entry struct {
+	Section string `json:"section,omitempty"`
+	Count   int    `json:"count"`
+	SHA256  string `json:"sha256,omitempty"`
+}
+
+type archiveWriter struct {
+	gz   *gzip.Writer
+	hash hash.Hash
+	out  io.Writer
+}
+
+func newArchiveWriter(w io.Writer, createdAt time.Time) (*archiveWriter, error) {
+	gz := gzip.NewWriter(w)
+	h := sha256.New()
+	a := &archiveWriter{gz: gz, hash: h, out: io.MultiWriter(gz, h)}
+	return a, a.line(header{Format: Format, Version: Version, CreatedAt: createdAt})
+}
+
+func (a *archiveWriter) line(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = a.out.Write(append(b, '\n'))
+	return err
+}
+
+// close writes the trailer, which is not part of its own checksum
+func (a *archiveWriter) close() error {
+	b, err := json.Marshal(entry{SHA256: hex.EncodeToString(a.hash.Sum(nil))})
+	if err != nil {
+		return err
+	}
+	if _, err := a.gz.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
+
+type archiveReader struct {
+	br   *bufio.Reader
+	hash hash.Hash
+	// sum is the checksum of everything before the line last returned
+	sum string
+}
+
+func newArchiveReader(r io.Reader) (*archiveReader, header, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, header{}, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	a := &archiveReader{br: bufio.NewReader(&capped{r: gz, n: maxArchiveSize}), hash: sha256.New()}
+
+	var h header
+	if err := a.decode(&h); err != nil {
+		return nil, header{}, err
+	}
+	if h.Format != Format {
+		return nil, header{}, fmt.Errorf("%w: not a %s archive", ErrCorrupt, Format)
+	}
+	if h.Version < 1 || h.Version > Version {
+		return nil, header{}, fmt.Errorf("%w: %d", ErrVersion, h.Version)
+	}
+	return a, h, nil
+}
+
+func (a *archiveReader) next() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := a.br.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxLine {
+			return nil, fmt.Errorf("%w: record too long", ErrCorrupt)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if errors.Is(err, ErrTooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		break
+	}
+
+	a.sum = hex.EncodeToString(a.hash.Sum(nil))
+	a.hash.Write(line)
+	return line, nil
+}
+
+// decode reads a header, section or trailer line, which must not carry unknown fields
+func (a *archiveReader) decode(v any) error {
+	line, err := a.next()
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return nil
+}
+
+// record reads one record line
+func (a *archiveReader) record(v any) error {
+	line, err := a.next()
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(line, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return nil
+}
+
+// finish checks the trailer just read against the checksum and that nothing follows it
+func (a *archiveReader) finish(trailer entry) error {
+	if trailer.SHA256 != a.sum {
+		return fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+	}
+	switch _, err := a.br.ReadByte(); {
+	case err == nil:
+		return fmt.Errorf("%w: data after trailer", ErrCorrupt)
+	case err != io.EOF:
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return nil
+}
+
+// capped reads at most n bytes from r and fails with ErrTooLarge if r has more
+type capped struct {
+	r io.Reader
+	n int64
+}
+
+func (c *capped) Read(p []byte) (int, error) {
+	if c.n <= 0 {
+		var probe [1]byte
+		n, err := c.r.Read(probe[:])
+		if n > 0 {
+			return 0, ErrTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > c.n {
+		p = p[:c.n]
+	}
+	n, err := c.r.Read(p)
+	c.n -= int64(n)
+	return n, err
+}
@@ -0,0 +1,432 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+// Stores are the repositories an archive covers. Attachment contents live in the blob store
+// and are backed up with its directory; the archive holds their metadata.
+type Stores struct {
+	Users        repositories.Snapshotter[models.User]
+	Projects     repositories.Snapshotter[models.Project]
+	Todos        repositories.Snapshotter[models.Todo]
+	Dependencies repositories.Snapshotter[models.Dependency]
+	Comments     repositories.Snapshotter[models.Comment]
+	Attachments  repositories.Snapshotter[models.Attachment]
+	TimeEntries  repositories.Snapshotter[models.TimeEntry]
+	Webhooks     repositories.Snapshotter[models.Webhook]
+
+	// Writes, when set, is held while the stores are copied or replaced. Everything that
+	// writes to the stores holds it shared, so an archive never has half of a request in it
+	// and a restore is never interleaved with other writes.
+	Writes TryLocker
+	// Derived is rebuilt from the restored todos before Writes is released
+	Derived []Rebuilder
+}
+
+// Rebuilder is implemented by state derived from events rather than kept in a store, such as
+// reminder schedules and statistics, which a restore would otherwise leave describing the
+// old contents
+type Rebuilder interface {
+	Rebuild(todos []models.Todo)
+}
+
+func (s Stores) complete() bool {
+	return s.Users != nil && s.Projects != nil && s.Todos != nil && s.Dependencies != nil &&
+		s.Comments != nil && s.Attachments != nil && s.TimeEntries != nil && s.Webhooks != nil
+}
+
+// TryLocker is the exclusive side of a lock such as *sync.RWMutex
+type TryLocker interface {
+	TryLock() bool
+	Unlock()
+}
+
+// ErrBusy is returned when writes kept holding Writes for all of lockTimeout
+var ErrBusy = errors.New("writes did not pause for the backup")
+
+// lockTimeout bounds how long a backup or restore waits for the writes in progress to finish
+var lockTimeout = 10 * time.Second
+
+// lock takes Writes by polling rather than blocking: a blocked Lock would hold up every new
+// write behind a request that is slow to finish, such as one still receiving its body.
+func (s Stores) lock(ctx context.Context) (unlock func(), err error) {
+	if s.Writes == nil {
+		return func() {}, nil
+	}
+
+	deadline := time.NewTimer(lockTimeout)
+	defer deadline.Stop()
+	wait := time.Millisecond
+	for !s.Writes.TryLock() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			return nil, ErrBusy
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, 50*time.Millisecond)
+	}
+	return s.Writes.Unlock, nil
+}
+
+// Summary describes an archive that was written or restored
+type Summary struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Counts    map[string]int `json:"counts"`
+}
+
+// userRecord and attachmentRecord carry the fields the API hides from JSON
+type userRecord struct {
+	models.User
+	PasswordHash []byte `json:"passwordHash"`
+	Salt         []byte `json:"salt"`
+	Iterations   int    `json:"iterations"`
+}
+
+type attachmentRecord struct {
+	models.Attachment
+	BlobKey string `json:"blobKey"`
+}
+
+// contents is a whole archive in memory, in section order: parents before the records pointing at them
+type contents struct {
+	Users        []models.User
+	Projects     []models.Project
+	Todos        []models.Todo
+	Dependencies []models.Dependency
+	Comments     []models.Comment
+	Attachments  []models.Attachment
+	TimeEntries  []models.TimeEntry
+	Webhooks     []models.Webhook
+}
+
+// Write copies every store and then streams the copies to w. The stores are copied one after
+// another while holding Writes, so they agree with each other, and no lock is held while the
+// archive is written out.
+func Write(ctx context.Context, w io.Writer, s Stores, now time.Time) (Summary, error) {
+	if !s.complete() {
+		return Summary{}, errors.ErrUnsupported
+	}
+
+	c, err := snapshot(ctx, s)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	return writeArchive(w, c, now)
+}
+
+// snapshot copies every store while holding Writes
+func snapshot(ctx context.Context, s Stores) (contents, error) {
+	unlock, err := s.lock(ctx)
+	if err != nil {
+		return contents{}, err
+	}
+	defer unlock()
+
+	return copyStores(ctx, s)
+}
+
+// copyStores copies every store; callers hold Writes
+func copyStores(ctx context.Context, s Stores) (contents, error) {
+	var c contents
+	var err error
+	if c.Users, err = s.Users.Snapshot(ctx); err != nil {
+		return contents{}, err
+	}
+	if c.Projects, err = s.Projects.Snapshot(ctx); err != nil {
+		return contents{}, err
+	}
+	if c.Todos, err = s.Todos.Snapshot(ctx); err != nil {
+		return contents{}, err
+	}
+	if c.Dependencies, err = s.Dependencies.Snapshot(ctx); err != nil {
+		return contents{}, err
+	}
+	if c.Comments, err = s.Comments.Snapshot(ctx); err != nil {
+		return contents{}, err
+	}
+	if c.Attachments, err = s.Attachments.Snapshot(ctx); err != nil {
+		return contents{}, err
+	}
+	if c.TimeEntries, err = s.TimeEntries.Snapshot(ctx); err != nil {
+		return contents{}, err
+	}
+	if c.Webhooks, err = s.Webhooks.Snapshot(ctx); err != nil {
+		return contents{}, err
+	}
+	return c, nil
+}
+
+func writeArchive(w io.Writer, c contents, now time.Time) (Summary, error) {
+	a, err := newArchiveWriter(w, now)
+	if err != nil {
+		return Summary{}, err
+	}
+	summary := Summary{Version: Version, CreatedAt: now, Counts: make(map[string]int)}
+
+	users := make([]userRecord, len(c.Users))
+	for i, u := range c.Users {
+		users[i] = userRecord{User: u, PasswordHash: u.PasswordHash, Salt: u.Salt, Iterations: u.Iterations}
+	}
+	attachments := make([]attachmentRecord, len(c.Attachments))
+	for i, at := range c.Attachments {
+		attachments[i] = attachmentRecord{Attachment: at, BlobKey: at.BlobKey}
+	}
+
+	for _, err := range []error{
+		writeSection(a, summary, "users", users),
+		writeSection(a, summary, "projects", c.Projects),
+		writeSection(a, summary, "todos", c.Todos),
+		writeSection(a, summary, "dependencies", c.Dependencies),
+		writeSection(a, summary, "comments", c.Comments),
+		writeSection(a, summary, "attachments", attachments),
+		writeSection(a, summary, "timeEntries", c.TimeEntries),
+		writeSection(a, summary, "webhooks", c.Webhooks),
+	} {
+		if err != nil {
+			return Summary{}, err
+		}
+	}
+
+	if err := a.close(); err != nil {
+		return Summary{}, err
+	}
+	return summary, nil
+}
+
+func writeSection[T any](a *archiveWriter, summary Summary, name string, records []T) error {
+	if err := a.line(entry{Section: name, Count: len(records)}); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := a.line(r); err != nil {
+			return err
+		}
+	}
+	summary.Counts[name] = len(records)
+	return nil
+}
+
+// Restore reads and checks the whole archive before touching any store, then replaces each
+// store's contents and rebuilds the derived state while holding Writes. Once checking is done
+// the replacement runs to completion even if ctx is cancelled, and if a store still refuses
+// its records the stores already replaced get their old contents back, so a restore never
+// stops halfway. Records pointing at todos the archive does not contain are dropped, except
+// time entries, which are kept for billing like they are when a todo is deleted.
+func Restore(ctx context.Context, r io.Reader, s Stores) (Summary, error) {
+	if !s.complete() {
+		return Summary{}, errors.ErrUnsupported
+	}
+
+	c, summary, err := read(r)
+	if err != nil {
+		return Summary{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Summary{}, err
+	}
+	c.prune()
+	if err := c.validate(); err != nil {
+		return Summary{}, err
+	}
+
+	unlock, err := s.lock(ctx)
+	if err != nil {
+		return Summary{}, err
+	}
+	defer unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	old, err := copyStores(ctx, s)
+	if err != nil {
+		return Summary{}, err
+	}
+	for i, replace := range replacers(s, c) {
+		if err := replace(ctx); err != nil {
+			return Summary{}, rollback(ctx, replacers(s, old)[:i], err)
+		}
+	}
+	for _, d := range s.Derived {
+		d.Rebuild(c.Todos)
+	}
+
+	summary.Counts = map[string]int{
+		"users":        len(c.Users),
+		"projects":     len(c.Projects),
+		"todos":        len(c.Todos),
+		"dependencies": len(c.Dependencies),
+		"comments":     len(c.Comments),
+		"attachments":  len(c.Attachments),
+		"timeEntries":  len(c.TimeEntries),
+		"webhooks":     len(c.Webhooks),
+	}
+	return summary, nil
+}
+
+// replacers swap c into each store, in section order
+func replacers(s Stores, c contents) []func(context.Context) error {
+	return []func(context.Context) error{
+		func(ctx context.Context) error { return s.Users.ReplaceAll(ctx, c.Users) },
+		func(ctx context.Context) error { return s.Projects.ReplaceAll(ctx, c.Projects) },
+		func(ctx context.Context) error { return s.Todos.ReplaceAll(ctx, c.Todos) },
+		func(ctx context.Context) error { return s.Dependencies.ReplaceAll(ctx, c.Dependencies) },
+		func(ctx context.Context) error { return s.Comments.ReplaceAll(ctx, c.Comments) },
+		func(ctx context.Context) error { return s.Attachments.ReplaceAll(ctx, c.Attachments) },
+		func(ctx context.Context) error { return s.TimeEntries.ReplaceAll(ctx, c.TimeEntries) },
+		func(ctx context.Context) error { return s.Webhooks.ReplaceAll(ctx, c.Webhooks) },
+	}
+}
+
+// rollback gives the stores replaced before one refused its records their old contents back.
+// Those stores held exactly these records a moment ago, so they accept them again.
+func rollback(ctx context.Context, undo []func(context.Context) error, cause error) error {
+	errs := []error{cause}
+	for _, replace := range undo {
+		if err := replace(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("rolling back: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func read(r io.Reader) (contents, Summary, error) {
+	a, h, err := newArchiveReader(r)
+	if err != nil {
+		return contents{}, Summary{}, err
+	}
+
+	var c contents
+	var users []userRecord
+	var attachments []attachmentRecord
+	seen := make(map[string]bool)
+	for {
+		var e entry
+		if err := a.decode(&e); err != nil {
+			return contents{}, Summary{}, err
+		}
+		if e.SHA256 != "" {
+			if err := a.finish(e); err != nil {
+				return contents{}, Summary{}, err
+			}
+			break
+		}
+		if seen[e.Section] || e.Count < 0 {
+			return contents{}, Summary{}, fmt.Errorf("%w: bad section %q", ErrCorrupt, e.Section)
+		}
+		seen[e.Section] = true
+
+		switch e.Section {
+		case "users":
+			users, err = readSection[userRecord](a, e.Count)
+		case "projects":
+			c.Projects, err = readSection[models.Project](a, e.Count)
+		case "todos":
+			c.Todos, err = readSection[models.Todo](a, e.Count)
+		case "dependencies":
+			c.Dependencies, err = readSection[models.Dependency](a, e.Count)
+		case "comments":
+			c.Comments, err = readSection[models.Comment](a, e.Count)
+		case "attachments":
+			attachments, err = readSection[attachmentRecord](a, e.Count)
+		case "timeEntries":
+			c.TimeEntries, err = readSection[models.TimeEntry](a, e.Count)
+		case "webhooks":
+			c.Webhooks, err = readSection[models.Webhook](a, e.Count)
+		default:
+			err = fmt.Errorf("%w: unknown section %q", ErrCorrupt, e.Section)
+		}
+		if err != nil {
+			return contents{}, Summary{}, err
+		}
+	}
+	if len(seen) != 8 {
+		return contents{}, Summary{}, fmt.Errorf("%w: missing sections", ErrCorrupt)
+	}
+
+	c.Users = make([]models.User, len(users))
+	for i, u := range users {
+		c.Users[i] = u.User
+		c.Users[i].PasswordHash, c.Users[i].Salt, c.Users[i].Iterations = u.PasswordHash, u.Salt, u.Iterations
+	}
+	c.Attachments = make([]models.Attachment, len(attachments))
+	for i, at := range attachments {
+		c.Attachments[i] = at.Attachment
+		c.Attachments[i].BlobKey = at.BlobKey
+	}
+
+	return c, Summary{Version: h.Version, CreatedAt: h.CreatedAt}, nil
+}
+
+func readSection[T any](a *archiveReader, count int) ([]T, error) {
+	records := make([]T, 0, min(count, 1024))
+	for range count {
+		var r T
+		if err := a.record(&r); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func (c *contents) prune() {
+	todos := make(map[int]bool, len(c.Todos))
+	for _, t := range c.Todos {
+		todos[t.ID] = true
+	}
+
+	c.Dependencies = keep(c.Dependencies, func(d models.Dependency) bool { return todos[d.TodoID] && todos[d.BlockerID] })
+	c.Comments = keep(c.Comments, func(cm models.Comment) bool { return todos[cm.TodoID] })
+	c.Attachments = keep(c.Attachments, func(at models.Attachment) bool { return todos[at.TodoID] })
+}
+
+func keep[T any](records []T, ok func(T) bool) []T {
+	out := records[:0]
+	for _, r := range records {
+		if ok(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// validate catches what ReplaceAll would refuse, so a bad archive fails before the first store is replaced
+func (c *contents) validate() error {
+	for name, dup := range map[string]bool{
+		"users":        duplicates(c.Users, func(u models.User) string { return u.ID }),
+		"projects":     duplicates(c.Projects, func(p models.Project) int { return p.ID }),
+		"todos":        duplicates(c.Todos, func(t models.Todo) int { return t.ID }),
+		"dependencies": duplicates(c.Dependencies, func(d models.Dependency) models.Dependency { return d }),
+		"comments":     duplicates(c.Comments, func(cm models.Comment) int { return cm.ID }),
+		"attachments":  duplicates(c.Attachments, func(at models.Attachment) int { return at.ID }),
+		"timeEntries":  duplicates(c.TimeEntries, func(e models.TimeEntry) int { return e.ID }),
+		"webhooks":     duplicates(c.Webhooks, func(w models.Webhook) int { return w.ID }),
+	} {
+		if dup {
+			return fmt.Errorf("%w: duplicate record in %s", ErrCorrupt, name)
+		}
+	}
+	return nil
+}
+
+func duplicates[T any, K comparable](records []T, key func(T) K) bool {
+	seen := make(map[K]bool, len(records))
+	for _, r := range records {
+		k := key(r)
+		if seen[k] {
+			return true
+		}
+		seen[k] = true
+	}
+	return false
+}
@@ -0,0 +1,381 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/reminders"
+	"todoist/internal/repositories"
+	"todoist/internal/services"
+)
+
+type repos struct {
+	users        *repositories.InMemoryUserRepo
+	projects     *repositories.InMemoryProjectRepo
+	todos        *repositories.InMemoryTodoRepo
+	dependencies *repositories.InMemoryDependencyRepo
+	comments     *repositories.InMemoryCommentRepo
+	attachments  *repositories.InMemoryAttachmentRepo
+	timeEntries  *repositories.InMemoryTimeEntryRepo
+	webhooks     *repositories.InMemoryWebhookRepo
+}
+
+func newRepos() repos {
+	return repos{
+		users:        repositories.NewInMemoryUserRepo(),
+		projects:     repositories.NewInMemoryProjectRepo(),
+		todos:        repositories.NewInMemoryTodoRepo(),
+		dependencies: repositories.NewInMemoryDependencyRepo(),
+		comments:     repositories.NewInMemoryCommentRepo(),
+		attachments:  repositories.NewInMemoryAttachmentRepo(),
+		timeEntries:  repositories.NewInMemoryTimeEntryRepo(),
+		webhooks:     repositories.NewInMemoryWebhookRepo(),
+	}
+}
+
+func (r repos) stores() Stores {
+	return Stores{
+		Users:        r.users,
+		Projects:     r.projects,
+		Todos:        r.todos,
+		Dependencies: r.dependencies,
+		Comments:     r.comments,
+		Attachments:  r.attachments,
+		TimeEntries:  r.timeEntries,
+		Webhooks:     r.webhooks,
+	}
+}
+
+var created = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// seeded returns repositories holding a little of everything
+func seeded(t *testing.T) repos {
+	t.Helper()
+	ctx := context.Background()
+	r := newRepos()
+
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := r.users.Create(ctx, models.User{ID: "alice", PasswordHash: []byte("hash"), Salt: []byte("salt"), Iterations: 600000, CreatedAt: created})
+	must(err)
+	p, err := r.projects.Create(ctx, models.Project{UserID: "alice", Name: "home", CreatedAt: created})
+	must(err)
+	first, err := r.todos.Create(ctx, models.Todo{UserID: "alice", Title: "first", Status: models.StatusPending, ProjectID: p.ID, CreatedAt: created})
+	must(err)
+	second, err := r.todos.Create(ctx, models.Todo{UserID: "alice", Title: "second", Status: models.StatusPending, CreatedAt: created})
+	must(err)
+	must(r.dependencies.Add(ctx, second.ID, first.ID))
+	_, err = r.comments.Create(ctx, models.Comment{TodoID: first.ID, UserID: "alice", Body: "hi", CreatedAt: created})
+	must(err)
+	_, err = r.attachments.Create(ctx, models.Attachment{TodoID: first.ID, UserID: "alice", Filename: "a.txt", BlobKey: "ab/cdef", CreatedAt: created})
+	must(err)
+	end := created.Add(time.Hour)
+	_, err = r.timeEntries.Create(ctx, models.TimeEntry{TodoID: first.ID, UserID: "alice", Start: created, End: &end, CreatedAt: created})
+	must(err)
+	_, err = r.webhooks.Create(ctx, models.Webhook{UserID: "alice", URL: "https://example.com/hook", Secret: "s3cret", Events: []string{"todo.created"}, CreatedAt: created})
+	must(err)
+	return r
+}
+
+func archive(t *testing.T, s Stores) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if _, err := Write(context.Background(), &buf, s, created); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := seeded(t)
+	data := archive(t, src.stores())
+
+	dst := newRepos()
+	for _, title := range []string{"one", "two", "three"} {
+		if _, err := dst.todos.Create(ctx, models.Todo{UserID: "bob", Title: title, Status: models.StatusPending}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summary, err := Restore(ctx, bytes.NewReader(data), dst.stores())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Version != Version || !summary.CreatedAt.Equal(created) {
+		t.Errorf("got %+v want version %d created %v", summary, Version, created)
+	}
+	for _, name := range []string{"users", "projects", "comments", "attachments", "timeEntries", "webhooks", "dependencies"} {
+		if summary.Counts[name] != 1 {
+			t.Errorf("%s: got %d records want 1", name, summary.Counts[name])
+		}
+	}
+	if summary.Counts["todos"] != 2 {
+		t.Errorf("todos: got %d records want 2", summary.Counts["todos"])
+	}
+
+	if bobs, err := dst.todos.ListByUser(ctx, "bob"); err != nil || len(bobs) != 0 {
+		t.Errorf("got %v, %v want the old todos replaced", bobs, err)
+	}
+	u, err := dst.users.GetByID(ctx, "alice")
+	if err != nil || string(u.PasswordHash) != "hash" || string(u.Salt) != "salt" || u.Iterations != 600000 {
+		t.Errorf("got %+v, %v want credentials restored", u, err)
+	}
+	as, err := dst.attachments.ListByTodo(ctx, 1)
+	if err != nil || len(as) != 1 || as[0].BlobKey != "ab/cdef" {
+		t.Errorf("got %+v, %v want blob key restored", as, err)
+	}
+	blockers, err := dst.dependencies.Blockers(ctx, 2)
+	if err != nil || len(blockers) != 1 || blockers[0] != 1 {
+		t.Errorf("got %v, %v want blocker 1", blockers, err)
+	}
+
+	// IDs keep counting past both the restored records and anything the store handed out before
+	next, err := dst.todos.Create(ctx, models.Todo{UserID: "alice", Title: "new", Status: models.StatusPending})
+	if err != nil || next.ID != 4 {
+		t.Errorf("got id %d, %v want 4", next.ID, err)
+	}
+
+	if again := archive(t, src.stores()); !bytes.Equal(again, data) {
+		t.Error("archives of unchanged stores differ")
+	}
+}
+
+func TestRestoreRejects(t *testing.T) {
+	ctx := context.Background()
+	data := archive(t, seeded(t).stores())
+
+	// rewrite decompresses the archive, edits its text and compresses it again
+	rewrite := func(edit func(string) string) []byte {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		plain, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(edit(string(plain))))
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrCorrupt},
+		{"not gzip", []byte("hello"), ErrCorrupt},
+		{"truncated", data[:len(data)/2], ErrCorrupt},
+		{"tampered", rewrite(func(s string) string { return strings.Replace(s, `"first"`, `"frist"`, 1) }), ErrCorrupt},
+		{"newer version", rewrite(func(s string) string { return strings.Replace(s, `"version":1`, `"version":2`, 1) }), ErrVersion},
+		{"other format", rewrite(func(s string) string { return strings.Replace(s, Format, "tarball", 1) }), ErrCorrupt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := newRepos()
+			if _, err := dst.todos.Create(ctx, models.Todo{UserID: "bob", Title: "kept", Status: models.StatusPending}); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Restore(ctx, bytes.NewReader(tt.data), dst.stores()); !errors.Is(err, tt.want) {
+				t.Fatalf("got %v want %v", err, tt.want)
+			}
+			if _, err := dst.todos.GetByID(ctx, 1); err != nil {
+				t.Errorf("got %v want the store untouched", err)
+			}
+		})
+	}
+}
+
+func TestUnsupportedStore(t *testing.T) {
+	s := newRepos().stores()
+	s.Todos = nil
+
+	if _, err := Write(context.Background(), io.Discard, s, created); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("got %v want %v", err, errors.ErrUnsupported)
+	}
+	if _, err := Restore(context.Background(), strings.NewReader(""), s); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("got %v want %v", err, errors.ErrUnsupported)
+	}
+}
+
+func TestRestoreRebuildsDerivedState(t *testing.T) {
+	ctx := context.Background()
+	due := time.Now().Add(24 * time.Hour)
+
+	src := seeded(t)
+	if _, err := src.todos.Create(ctx, models.Todo{UserID: "alice", Title: "due", Status: models.StatusPending, DueAt: &due, CreatedAt: created}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.todos.Create(ctx, models.Todo{UserID: "alice", Title: "done", Status: models.StatusCompleted, CreatedAt: created, UpdatedAt: created.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	data := archive(t, src.stores())
+
+	// the destination has seen events for todos the restore replaces
+	dst := newRepos()
+	bus := events.NewBus(16)
+	todos := services.NewTodoService(dst.todos, services.WithEvents(bus))
+	stats := services.NewStatsService()
+	scheduler := reminders.NewScheduler(reminders.SystemClock{}, nil, reminders.DefaultConfig())
+	bus.Listen(stats.HandleEvent)
+	bus.Listen(scheduler.HandleEvent)
+	if _, err := todos.CreateTodo(ctx, models.CreateTodo{UserID: "bob", Title: "old", DueAt: &due}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := dst.stores()
+	s.Derived = []Rebuilder{todos, stats, scheduler}
+	if _, err := Restore(ctx, bytes.NewReader(data), s); err != nil {
+		t.Fatal(err)
+	}
+
+	pending := scheduler.Pending()
+	if len(pending) != 1 || pending[0].TodoID != 3 {
+		t.Errorf("got reminders %+v want only the restored todo 3", pending)
+	}
+	if _, err := todos.Undo(ctx, "bob"); !errors.Is(err, services.ErrNothingToUndo) {
+		t.Errorf("got %v want the undo history dropped", err)
+	}
+
+	got, err := stats.Stats(ctx, models.StatsQuery{UserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ByStatus[models.StatusPending] != 3 || got.ByStatus[models.StatusCompleted] != 1 || got.AverageCompletion != "1h0m0s" {
+		t.Errorf("got %+v want alice's restored todos counted", got)
+	}
+	if old, _ := stats.Stats(ctx, models.StatsQuery{UserID: "bob"}); old.ByStatus[models.StatusPending] != 0 {
+		t.Errorf("got %+v want bob's replaced todo forgotten", old)
+	}
+}
+
+func TestWritesWaitForTheLock(t *testing.T) {
+	var mu sync.RWMutex
+	s := seeded(t).stores()
+	s.Writes = &mu
+
+	// a request is writing
+	mu.RLock()
+	done := make(chan error, 1)
+	go func() {
+		_, err := Write(context.Background(), io.Discard, s, created)
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("backup copied the stores while a write was in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// the waiting backup does not hold up the writes that come after it
+	if !mu.TryRLock() {
+		t.Fatal("a new write waited behind the backup")
+	}
+	mu.RUnlock()
+
+	mu.RUnlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBusyWrites(t *testing.T) {
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 20 * time.Millisecond
+
+	var mu sync.RWMutex
+	mu.RLock()
+	defer mu.RUnlock()
+
+	data := archive(t, seeded(t).stores())
+	dst := newRepos()
+	s := dst.stores()
+	s.Writes = &mu
+
+	if _, err := Write(context.Background(), io.Discard, s, created); !errors.Is(err, ErrBusy) {
+		t.Errorf("write: got %v want %v", err, ErrBusy)
+	}
+	if _, err := Restore(context.Background(), bytes.NewReader(data), s); !errors.Is(err, ErrBusy) {
+		t.Errorf("restore: got %v want %v", err, ErrBusy)
+	}
+	if users, _ := dst.users.Snapshot(context.Background()); len(users) != 0 {
+		t.Errorf("got users %+v want nothing restored", users)
+	}
+}
+
+// refusing is a store that turns down every restore
+type refusing struct {
+	repositories.Snapshotter[models.Webhook]
+}
+
+func (refusing) ReplaceAll(ctx context.Context, records []models.Webhook) error {
+	return repositories.ErrConflict
+}
+
+func TestRestoreRollsBack(t *testing.T) {
+	ctx := context.Background()
+	data := archive(t, seeded(t).stores())
+
+	dst := newRepos()
+	kept, err := dst.todos.Create(ctx, models.Todo{UserID: "carol", Title: "before the restore", CreatedAt: created})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := dst.stores()
+	// webhooks are replaced last, after every other store
+	s.Webhooks = refusing{dst.webhooks}
+
+	if _, err := Restore(ctx, bytes.NewReader(data), s); !errors.Is(err, repositories.ErrConflict) {
+		t.Fatalf("got %v want %v", err, repositories.ErrConflict)
+	}
+
+	todos, _ := dst.todos.Snapshot(ctx)
+	if len(todos) != 1 || todos[0].ID != kept.ID || todos[0].Title != kept.Title {
+		t.Errorf("got todos %+v want only the todo from before the restore", todos)
+	}
+	if users, _ := dst.users.Snapshot(ctx); len(users) != 0 {
+		t.Errorf("got users %+v want none", users)
+	}
+}
+
+func TestRestoreBoundsDecompressedSize(t *testing.T) {
+	defer func(n int64) { maxArchiveSize = n }(maxArchiveSize)
+	maxArchiveSize = 1 << 10
+
+	// a megabyte of zeros compresses to about a kilobyte
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(make([]byte, 1<<20))
+	zw.Close()
+
+	if _, err := Restore(context.Background(), &buf, newRepos().stores()); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("got %v want %v", err, ErrTooLarge)
+	}
+
+	// an archive that fits is still accepted
+	data := archive(t, seeded(t).stores())
+	maxArchiveSize = 1 << 20
+	if _, err := Restore(context.Background(), bytes.NewReader(data), newRepos().stores()); err != nil {
+		t.Errorf("got %v want the archive restored", err)
+	}
+}
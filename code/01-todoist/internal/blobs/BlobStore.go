@@ -0,0 +1,46 @@
+package blobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobStore holds opaque file contents under caller-chosen keys
+type BlobStore interface {
+	// Put stores everything r yields and returns the number of bytes written.
+	// A failed Put leaves nothing behind under key.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open returns a seekable reader so callers can serve byte ranges
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// keys end up in file paths, so nothing that could climb out of the store's directory is allowed
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{4,128}$`)
+
+func validKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// contextReader stops a long copy once its context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,62 @@
+package blobs
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDiskStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := store.Put(ctx, "abcdef", strings.NewReader("hello, world"))
+	if err != nil || n != 12 {
+		t.Fatalf("got %d, %v want 12 bytes stored", n, err)
+	}
+
+	f, err := store.Open(ctx, "abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Seek(7, io.SeekStart)
+	rest, _ := io.ReadAll(f)
+	f.Close()
+	if string(rest) != "world" {
+		t.Errorf("got %q want world after seeking", rest)
+	}
+
+	if err := store.Delete(ctx, "abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Open(ctx, "abcdef"); err != ErrNotFound {
+		t.Errorf("got %v want %v after delete", err, ErrNotFound)
+	}
+
+	for _, key := range []string{"../../etc/passwd", "a/b/c/d", "", "ab"} {
+		if _, err := store.Put(ctx, key, strings.NewReader("x")); err != ErrInvalidKey {
+			t.Errorf("got %v want %v for key %q", err, ErrInvalidKey, key)
+		}
+	}
+}
+
+func TestDiskStoreLeavesNothingOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewDiskStore(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := store.Put(ctx, "abcdef", strings.NewReader("data")); err != context.Canceled {
+		t.Fatalf("got %v want %v", err, context.Canceled)
+	}
+
+	entries, _ := os.ReadDir(dir + "/ab")
+	if len(entries) != 0 {
+		t.Errorf("got %d leftover files want 0", len(entries))
+	}
+}
@@ -0,0 +1,81 @@
+package blobs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// MemoryStore keeps blobs in memory for servers without a data directory, so nothing is
+// left behind on disk when the process exits along with the attachments that pointed at it
+type MemoryStore struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{blobs: make(map[string][]byte)}
+}
+
+// Put reads the whole body before storing it, so readers never see a partial blob
+func (s *MemoryStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	if err := validKey(key); err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, contextReader{ctx, r})
+	if err != nil {
+		return n, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[key] = buf.Bytes()
+	return n, nil
+}
+
+func (s *MemoryStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.blobs[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	// stored slices are never written to again, so readers can share them
+	return nopCloser{bytes.NewReader(b)}, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.blobs[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.blobs, key)
+	return nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,49 @@
+package blobs
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	n, err := store.Put(ctx, "abcdef", strings.NewReader("hello, world"))
+	if err != nil || n != 12 {
+		t.Fatalf("got %d, %v want 12 bytes stored", n, err)
+	}
+
+	f, err := store.Open(ctx, "abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Seek(7, io.SeekStart)
+	rest, _ := io.ReadAll(f)
+	f.Close()
+	if string(rest) != "world" {
+		t.Errorf("got %q want world after seeking", rest)
+	}
+
+	if err := store.Delete(ctx, "abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Open(ctx, "abcdef"); err != ErrNotFound {
+		t.Errorf("got %v want %v after delete", err, ErrNotFound)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := store.Put(cancelled, "abcdef", strings.NewReader("data")); err != context.Canceled {
+		t.Errorf("got %v want %v", err, context.Canceled)
+	}
+	if _, err := store.Open(ctx, "abcdef"); err != ErrNotFound {
+		t.Errorf("got %v want nothing stored by a failed put", err)
+	}
+
+	if _, err := store.Put(ctx, "../../etc/passwd", strings.NewReader("x")); err != ErrInvalidKey {
+		t.Errorf("got %v want %v", err, ErrInvalidKey)
+	}
+}
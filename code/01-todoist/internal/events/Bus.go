@@ -0,0 +1,166 @@
+package events
+
+import (
+	"sync"
+	"time"
+	"todoist/internal/models"
+)
+
+type Type string
+
+const (
+	TodoCreated Type = "todo.created"
+	TodoUpdated Type = "todo.updated"
+	TodoDeleted Type = "todo.deleted"
+	// TodoPurged follows TodoDeleted once the delete can no longer be undone; whatever hangs
+	// off the todo is only dropped then
+	TodoPurged Type = "todo.purged"
+	// TodoCompleted is never published; it names the updates that move a todo to COMPLETED
+	TodoCompleted Type = "todo.completed"
+)
+
+// Event describes a successful write made through the service layer.
+// Previous is set for updates and deletes and holds the todo as it was before the write.
+type Event struct {
+	ID         uint64       `json:"id"`
+	Type       Type         `json:"type"`
+	UserID     string       `json:"userId"`
+	Todo       models.Todo  `json:"todo"`
+	Previous   *models.Todo `json:"previous,omitempty"`
+	OccurredAt time.Time    `json:"occurredAt"`
+}
+
+// Completed reports whether the event moved a todo into the completed status
+func (e Event) Completed() bool {
+	return e.Type == TodoUpdated &&
+		e.Todo.Status == models.StatusCompleted &&
+		(e.Previous == nil || e.Previous.Status != models.StatusCompleted)
+}
+
+const subscriptionBuffer = 64
+
+// Bus fans events out to per-user subscribers and keeps a bounded replay buffer per user
+type Bus struct {
+	mu         sync.Mutex
+	nextID     uint64
+	replaySize int
+	replay     map[string][]Event
+	// evicted is the ID of the newest event each user's replay buffer has let go of
+	evicted   map[string]uint64
+	subs      map[string]map[*Subscription]struct{}
+	listeners []func(Event)
+}
+
+func NewBus(replaySize int) *Bus {
+	return &Bus{
+		replaySize: replaySize,
+		replay:     make(map[string][]Event),
+		evicted:    make(map[string]uint64),
+		subs:       make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the event its ID and delivers it.
+// A subscriber whose buffer is full is dropped rather than blocking the writer; it can resume from its last ID.
+func (b *Bus) Publish(e Event) Event {
+	b.mu.Lock()
+
+	b.nextID++
+	e.ID = b.nextID
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	buf := append(b.replay[e.UserID], e)
+	if len(buf) > b.replaySize {
+		b.evicted[e.UserID] = buf[len(buf)-b.replaySize-1].ID
+		buf = buf[len(buf)-b.replaySize:]
+	}
+	b.replay[e.UserID] = buf
+
+	for sub := range b.subs[e.UserID] {
+		select {
+		case sub.ch <- e:
+		default:
+			b.removeLocked(sub)
+		}
+	}
+
+	listeners := b.listeners
+	b.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(e)
+	}
+
+	return e
+}
+
+// Listen registers fn to be called synchronously for every event of every user.
+// fn must not block; hand slow work off to a goroutine or queue.
+func (b *Bus) Listen(fn func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	listeners := make([]func(Event), len(b.listeners), len(b.listeners)+1)
+	copy(listeners, b.listeners)
+	b.listeners = append(listeners, fn)
+}
+
+// Subscribe registers for a user's events and returns the buffered events newer than lastID,
+// which the caller should deliver before reading from the subscription. missed reports that
+// some events after lastID are no longer buffered, or that lastID came from before a restart,
+// so the backlog alone cannot bring the caller up to date.
+func (b *Bus) Subscribe(userID string, lastID uint64) (sub *Subscription, backlog []Event, missed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	missed = lastID != 0 && (lastID < b.evicted[userID] || lastID > b.nextID)
+
+	for _, e := range b.replay[userID] {
+		if e.ID > lastID {
+			backlog = append(backlog, e)
+		}
+	}
+
+	ch := make(chan Event, subscriptionBuffer)
+	sub = &Subscription{C: ch, ch: ch, bus: b, userID: userID}
+
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[*Subscription]struct{})
+	}
+	b.subs[userID][sub] = struct{}{}
+
+	return sub, backlog, missed
+}
+
+func (b *Bus) removeLocked(sub *Subscription) {
+	subs, ok := b.subs[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subs, sub.userID)
+	}
+	close(sub.ch)
+}
+
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	bus    *Bus
+	userID string
+}
+
+// Close unsubscribes and closes C; it is safe to call more than once
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.bus.removeLocked(s)
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"testing"
+	"todoist/internal/models"
+)
+
+func TestBus(t *testing.T) {
+	t.Run("subscriber receives only its user's events", func(t *testing.T) {
+		bus := NewBus(10)
+		sub, _, _ := bus.Subscribe("alice", 0)
+		defer sub.Close()
+
+		bus.Publish(Event{Type: TodoCreated, UserID: "bob", Todo: models.Todo{ID: 1}})
+		bus.Publish(Event{Type: TodoCreated, UserID: "alice", Todo: models.Todo{ID: 2}})
+
+		got := <-sub.C
+		if got.Todo.ID != 2 {
+			t.Errorf("got todo %d want 2", got.Todo.ID)
+		}
+		if len(sub.C) != 0 {
+			t.Errorf("got %d extra events want 0", len(sub.C))
+		}
+	})
+
+	t.Run("resumes from the replay buffer after last id", func(t *testing.T) {
+		bus := NewBus(2)
+		first := bus.Publish(Event{Type: TodoCreated, UserID: "alice"})
+		bus.Publish(Event{Type: TodoUpdated, UserID: "alice"})
+		bus.Publish(Event{Type: TodoDeleted, UserID: "alice"})
+
+		sub, backlog, missed := bus.Subscribe("alice", first.ID)
+		defer sub.Close()
+
+		if missed {
+			t.Error("got missed events want the buffer to cover everything after last id")
+		}
+		if len(backlog) != 2 {
+			t.Fatalf("got %d replayed events want 2", len(backlog))
+		}
+		if backlog[0].Type != TodoUpdated || backlog[1].Type != TodoDeleted {
+			t.Errorf("got %v, %v want updated, deleted", backlog[0].Type, backlog[1].Type)
+		}
+	})
+
+	t.Run("reports events gone from the replay buffer", func(t *testing.T) {
+		bus := NewBus(2)
+		first := bus.Publish(Event{Type: TodoCreated, UserID: "alice"})
+		second := bus.Publish(Event{Type: TodoCreated, UserID: "alice"})
+		bus.Publish(Event{Type: TodoCreated, UserID: "bob"})
+		bus.Publish(Event{Type: TodoUpdated, UserID: "alice"})
+		last := bus.Publish(Event{Type: TodoDeleted, UserID: "alice"})
+
+		tests := []struct {
+			name   string
+			lastID uint64
+			want   bool
+		}{
+			{"new connection", 0, false},
+			{"older than the buffer", first.ID, true},
+			{"just before the buffer", second.ID, false},
+			{"up to date", last.ID, false},
+			{"from before a restart", last.ID + 100, true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				sub, _, missed := bus.Subscribe("alice", tt.lastID)
+				defer sub.Close()
+				if missed != tt.want {
+					t.Errorf("got missed %v want %v", missed, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("close unsubscribes", func(t *testing.T) {
+		bus := NewBus(10)
+		sub, _, _ := bus.Subscribe("alice", 0)
+		sub.Close()
+		sub.Close()
+
+		bus.Publish(Event{Type: TodoCreated, UserID: "alice"})
+		if _, ok := <-sub.C; ok {
+			t.Error("expected closed channel")
+		}
+	})
+
+	t.Run("listeners see every user", func(t *testing.T) {
+		bus := NewBus(10)
+		var seen []string
+		bus.Listen(func(e Event) { seen = append(seen, e.UserID) })
+
+		bus.Publish(Event{UserID: "alice"})
+		bus.Publish(Event{UserID: "bob"})
+
+		if len(seen) != 2 {
+			t.Errorf("got %v want both users", seen)
+		}
+	})
+}
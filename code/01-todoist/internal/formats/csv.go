@@ -0,0 +1,170 @@
+package formats
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"todoist/internal/models"
+)
+
+var csvColumns = []string{"uid", "title", "description", "status", "due", "createdAt", "updatedAt"}
+
+type csvEncoder struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+// NewCSVEncoder writes a header row followed by one row per todo
+func NewCSVEncoder(w io.Writer) Encoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Encode(t models.Todo) error {
+	if !e.headerWritten {
+		e.headerWritten = true
+		if err := e.w.Write(csvColumns); err != nil {
+			return err
+		}
+	}
+
+	due := ""
+	if t.DueAt != nil {
+		due = t.DueAt.UTC().Format(time.RFC3339)
+	}
+
+	return e.w.Write([]string{
+		escapeCell(TodoUID(t)),
+		escapeCell(t.Title),
+		escapeCell(t.Description),
+		string(t.Status),
+		due,
+		t.CreatedAt.UTC().Format(time.RFC3339),
+		t.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// formulaPrefixes start a formula, or let one through, when a spreadsheet opens the cell
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeCell quotes text a spreadsheet would run as a formula with a leading ', which the
+// spreadsheet hides and unescapeCell drops again. Text already starting with ' gets a
+// second one so that it survives the round trip too.
+func escapeCell(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes+"'", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func unescapeCell(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(formulaPrefixes+"'", rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+func (e *csvEncoder) Flush() error {
+	if !e.headerWritten {
+		e.headerWritten = true
+		e.w.Write(csvColumns)
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ParseCSV reads rows by header name; only the title column is required.
+// Rows that fail to parse are returned with Err set.
+func ParseCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("missing title column")
+	}
+
+	field := func(row []string, name string) string {
+		i, ok := columns[strings.ToLower(name)]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	records := make([]Record, 0)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			records = append(records, Record{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		rec := Record{
+			Line:        line,
+			UID:         unescapeCell(field(row, "uid")),
+			Title:       unescapeCell(field(row, "title")),
+			Description: unescapeCell(field(row, "description")),
+			Status:      models.StatusPending,
+		}
+
+		if v := field(row, "status"); v != "" {
+			status, err := ParseStatus(v)
+			if err != nil {
+				rec.Err = err
+			}
+			rec.Status = status
+		}
+		if v := field(row, "due"); v != "" {
+			due, err := ParseDue(v)
+			if err != nil {
+				rec.Err = err
+			}
+			rec.Due = due
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// ParseStatus accepts the status names case-insensitively
+func ParseStatus(s string) (models.TodoStatus, error) {
+	status := models.TodoStatus(strings.ToUpper(strings.TrimSpace(s)))
+	switch status {
+	case models.StatusPending, models.StatusCompleted, models.StatusTrashed:
+		return status, nil
+	}
+	return models.StatusPending, fmt.Errorf("unknown status %q", s)
+}
+
+// ParseDue accepts RFC 3339 timestamps and plain dates, which are taken as midnight UTC
+func ParseDue(s string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid due date %q", s)
+}
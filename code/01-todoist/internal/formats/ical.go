@@ -0,0 +1,302 @@
+package formats
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"todoist/internal/models"
+)
+
+const (
+	icalDateTimeUTC = "20060102T150405Z"
+	icalDateTime    = "20060102T150405"
+	icalDate        = "20060102"
+)
+
+// TodoUID returns the UID a todo is exported under
+func TodoUID(t models.Todo) string {
+	if t.UID != "" {
+		return t.UID
+	}
+	return fmt.Sprintf("todo-%d@todoist", t.ID)
+}
+
+// NewTodoUID returns a fresh, unguessable UID for a todo created on this server
+func NewTodoUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "todo-" + hex.EncodeToString(b) + "@todoist"
+}
+
+// IsTodoUID reports whether uid has the form of a UID issued by this server
+func IsTodoUID(uid string) bool {
+	return strings.HasPrefix(uid, "todo-") && strings.HasSuffix(uid, "@todoist")
+}
+
+// ParseTodoUID recovers the todo ID from a UID produced by TodoUID
+func ParseTodoUID(uid string) (int, bool) {
+	rest, ok := strings.CutPrefix(uid, "todo-")
+	if !ok {
+		return 0, false
+	}
+	rest, ok = strings.CutSuffix(rest, "@todoist")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(rest)
+	return id, err == nil && id > 0
+}
+
+func icalStatus(s models.TodoStatus) string {
+	switch s {
+	case models.StatusCompleted:
+		return "COMPLETED"
+	case models.StatusTrashed:
+		return "CANCELLED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+func todoStatus(s string) models.TodoStatus {
+	switch strings.ToUpper(s) {
+	case "COMPLETED":
+		return models.StatusCompleted
+	case "CANCELLED":
+		return models.StatusTrashed
+	default:
+		return models.StatusPending
+	}
+}
+
+// WriteICal writes todos as a VCALENDAR of VTODO components
+func WriteICal(w io.Writer, todos []models.Todo) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//todoist//todoist//EN")
+	for _, t := range todos {
+		line("BEGIN", "VTODO")
+		line("UID", escapeText(TodoUID(t)))
+		line("DTSTAMP", t.UpdatedAt.UTC().Format(icalDateTimeUTC))
+		line("CREATED", t.CreatedAt.UTC().Format(icalDateTimeUTC))
+		line("LAST-MODIFIED", t.UpdatedAt.UTC().Format(icalDateTimeUTC))
+		line("SUMMARY", escapeText(t.Title))
+		if t.Description != "" {
+			line("DESCRIPTION", escapeText(t.Description))
+		}
+		line("STATUS", icalStatus(t.Status))
+		if t.DueAt != nil {
+			line("DUE", t.DueAt.UTC().Format(icalDateTimeUTC))
+		}
+		if t.Status == models.StatusCompleted {
+			line("COMPLETED", t.UpdatedAt.UTC().Format(icalDateTimeUTC))
+		}
+		line("END", "VTODO")
+	}
+	line("END", "VCALENDAR")
+
+	return bw.Flush()
+}
+
+// writeFolded splits content lines longer than 75 octets as RFC 5545 section 3.1 requires,
+// without breaking UTF-8 sequences
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+type contentLine struct {
+	number int
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseICal reads every VTODO component in r. Other components such as VEVENT are ignored.
+// A component with an unparseable DUE is returned with Err set.
+func ParseICal(r io.Reader) ([]Record, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make([]Record, 0)
+	var current *Record
+	depth := 0
+
+	for _, l := range lines {
+		switch l.name {
+		case "BEGIN":
+			depth++
+			if strings.EqualFold(l.value, "VTODO") {
+				current = &Record{Line: l.number, Status: models.StatusPending}
+			}
+			continue
+		case "END":
+			depth--
+			if current != nil && strings.EqualFold(l.value, "VTODO") {
+				todos = append(todos, *current)
+				current = nil
+			}
+			continue
+		}
+
+		// only properties directly on the VTODO, not on nested VALARMs
+		if current == nil || depth != 2 {
+			continue
+		}
+
+		switch l.name {
+		case "UID":
+			current.UID = unescapeText(l.value)
+		case "SUMMARY":
+			current.Title = unescapeText(l.value)
+		case "DESCRIPTION":
+			current.Description = unescapeText(l.value)
+		case "STATUS":
+			current.Status = todoStatus(l.value)
+		case "DUE":
+			due, err := parseDateTime(l)
+			if err != nil {
+				current.Err = fmt.Errorf("line %d: %w", l.number, err)
+				continue
+			}
+			current.Due = &due
+		}
+	}
+
+	if current != nil || depth != 0 {
+		return nil, fmt.Errorf("unterminated component")
+	}
+
+	return todos, nil
+}
+
+func parseDateTime(l contentLine) (time.Time, error) {
+	if l.params["VALUE"] == "DATE" || len(l.value) == len(icalDate) {
+		return time.ParseInLocation(icalDate, l.value, time.UTC)
+	}
+	if strings.HasSuffix(l.value, "Z") {
+		return time.Parse(icalDateTimeUTC, l.value)
+	}
+
+	loc := time.UTC
+	if tzid := l.params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, fmt.Errorf("unknown TZID %q", tzid)
+		}
+	}
+	return time.ParseInLocation(icalDateTime, l.value, loc)
+}
+
+// unfold joins folded lines and splits each content line into name, parameters and value
+func unfold(r io.Reader) ([]contentLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var raw []string
+	var numbers []int
+	n := 0
+	for scanner.Scan() {
+		n++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(text) > 0 && (text[0] == ' ' || text[0] == '\t') && len(raw) > 0 {
+			raw[len(raw)-1] += text[1:]
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		raw = append(raw, text)
+		numbers = append(numbers, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	lines := make([]contentLine, 0, len(raw))
+	for i, text := range raw {
+		head, value, ok := splitContentLine(text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing ':'", numbers[i])
+		}
+
+		parts := strings.Split(head, ";")
+		l := contentLine{
+			number: numbers[i],
+			name:   strings.ToUpper(parts[0]),
+			params: make(map[string]string),
+			value:  value,
+		}
+		for _, p := range parts[1:] {
+			k, v, _ := strings.Cut(p, "=")
+			l.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
+
+// splitContentLine splits at the first colon outside a quoted parameter value
+func splitContentLine(s string) (string, string, bool) {
+	quoted := false
+	for i, c := range s {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				return s[:i], s[i+1:], true
+			}
+		}
+	}
+	return "", "", false
+}
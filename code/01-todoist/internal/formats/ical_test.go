@@ -0,0 +1,97 @@
+package formats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"todoist/internal/models"
+)
+
+func TestICalRoundTrip(t *testing.T) {
+	due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	todos := []models.Todo{
+		{ID: 1, Title: "Pay rent; landlord, again", Description: "line one\nline two", Status: models.StatusPending, DueAt: &due},
+		{ID: 2, Title: strings.Repeat("long ", 30), Status: models.StatusCompleted},
+		{ID: 3, Title: "imported", Status: models.StatusTrashed, UID: "abc@calendar.example"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICal(&buf, todos); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+
+	got, err := ParseICal(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(todos) {
+		t.Fatalf("got %d todos want %d", len(got), len(todos))
+	}
+
+	for i, want := range todos {
+		if got[i].UID != TodoUID(want) {
+			t.Errorf("got uid %q want %q", got[i].UID, TodoUID(want))
+		}
+		if got[i].Title != want.Title || got[i].Description != want.Description {
+			t.Errorf("got %q/%q want %q/%q", got[i].Title, got[i].Description, want.Title, want.Description)
+		}
+		if got[i].Status != want.Status {
+			t.Errorf("got status %q want %q", got[i].Status, want.Status)
+		}
+	}
+
+	if got[0].Due == nil || !got[0].Due.Equal(due) {
+		t.Errorf("got due %v want %v", got[0].Due, due)
+	}
+}
+
+func TestParseICal(t *testing.T) {
+	t.Run("date only and zoned due dates", func(t *testing.T) {
+		src := "BEGIN:VCALENDAR\r\n" +
+			"BEGIN:VTODO\r\nUID:a\r\nSUMMARY:date\r\nDUE;VALUE=DATE:20260102\r\nEND:VTODO\r\n" +
+			"BEGIN:VTODO\r\nUID:b\r\nSUMMARY:zoned\r\nDUE;TZID=Europe/Berlin:20260102T100000\r\n" +
+			"BEGIN:VALARM\r\nDESCRIPTION:alarm text\r\nEND:VALARM\r\nEND:VTODO\r\n" +
+			"BEGIN:VEVENT\r\nUID:c\r\nSUMMARY:not a todo\r\nEND:VEVENT\r\n" +
+			"END:VCALENDAR\r\n"
+
+		got, err := ParseICal(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("got %d todos want 2", len(got))
+		}
+		if want := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC); !got[0].Due.Equal(want) {
+			t.Errorf("got %v want %v", got[0].Due, want)
+		}
+		if want := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC); !got[1].Due.Equal(want) {
+			t.Errorf("got %v want %v", got[1].Due, want)
+		}
+		if got[1].Description != "" {
+			t.Errorf("alarm description leaked into todo: %q", got[1].Description)
+		}
+	})
+
+	t.Run("unterminated component", func(t *testing.T) {
+		_, err := ParseICal(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\n"))
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestParseTodoUID(t *testing.T) {
+	if id, ok := ParseTodoUID("todo-42@todoist"); !ok || id != 42 {
+		t.Errorf("got %d, %v want 42, true", id, ok)
+	}
+	if _, ok := ParseTodoUID("todo-x@todoist"); ok {
+		t.Error("expected malformed uid to be rejected")
+	}
+}
@@ -0,0 +1,147 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"todoist/internal/models"
+)
+
+type markdownEncoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewMarkdownEncoder writes a GitHub-flavoured task list. Completed todos are checked,
+// trashed todos are struck through and descriptions follow as indented lines.
+func NewMarkdownEncoder(w io.Writer) Encoder {
+	return &markdownEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *markdownEncoder) Encode(t models.Todo) error {
+	if e.err != nil {
+		return e.err
+	}
+
+	box := " "
+	if t.Status == models.StatusCompleted {
+		box = "x"
+	}
+
+	title := strings.ReplaceAll(t.Title, "\n", " ")
+	if t.Status == models.StatusTrashed {
+		title = "~~" + title + "~~"
+	}
+
+	fmt.Fprintf(e.w, "- [%s] %s", box, title)
+	if t.DueAt != nil {
+		fmt.Fprintf(e.w, " (due: %s)", formatDue(*t.DueAt))
+	}
+	e.w.WriteString("\n")
+
+	if t.Description != "" {
+		for _, line := range strings.Split(t.Description, "\n") {
+			e.w.WriteString("  " + line + "\n")
+		}
+	}
+
+	if e.w.Buffered() > 32<<10 {
+		e.err = e.w.Flush()
+	}
+	return e.err
+}
+
+func (e *markdownEncoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+func formatDue(t time.Time) string {
+	t = t.UTC()
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339)
+}
+
+var (
+	taskItem   = regexp.MustCompile(`^\s*[-*+] \[([ xX])\] (.*)$`)
+	dueSuffix  = regexp.MustCompile(`\s*\(due: ([^)]*)\)\s*$`)
+	struckText = regexp.MustCompile(`^~~(.*)~~$`)
+)
+
+// ParseMarkdown reads every task list item in r. Indented lines directly under an item
+// become its description; headings, prose and blank lines are ignored.
+func ParseMarkdown(r io.Reader) ([]Record, error) {
+	scanner := bufio.NewScanner(r)
+
+	records := make([]Record, 0)
+	var current *Record
+	var description []string
+
+	finish := func() {
+		if current == nil {
+			return
+		}
+		current.Description = strings.Join(description, "\n")
+		records = append(records, *current)
+		current, description = nil, nil
+	}
+
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if m := taskItem.FindStringSubmatch(line); m != nil {
+			finish()
+			current = &Record{Line: n, Status: models.StatusPending}
+			if m[1] != " " {
+				current.Status = models.StatusCompleted
+			}
+
+			title := m[2]
+			if due := dueSuffix.FindStringSubmatch(title); due != nil {
+				title = title[:len(title)-len(due[0])]
+				current.Due, current.Err = ParseDue(due[1])
+			}
+			if struck := struckText.FindStringSubmatch(title); struck != nil {
+				title = struck[1]
+				current.Status = models.StatusTrashed
+			}
+			current.Title = strings.TrimSpace(title)
+			continue
+		}
+
+		if current != nil && (strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "\t")) {
+			description = append(description, strings.TrimSpace(line))
+			continue
+		}
+
+		finish()
+	}
+	finish()
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// LooksLikeMarkdown reports whether text contains a task list item or starts with a heading
+func LooksLikeMarkdown(text string) bool {
+	if strings.HasPrefix(text, "#") {
+		return true
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if taskItem.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
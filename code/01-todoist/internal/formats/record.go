@@ -0,0 +1,26 @@
+package formats
+
+import (
+	"time"
+	"todoist/internal/models"
+)
+
+// Record is one todo read from an import file, whatever its format
+type Record struct {
+	// Line is where the record starts in the source file, for error reports
+	Line        int
+	UID         string
+	Title       string
+	Description string
+	Status      models.TodoStatus
+	Due         *time.Time
+	// Err is set when the record could not be parsed; the rest of the file is still imported
+	Err error
+}
+
+// Encoder writes todos one at a time in one export format
+type Encoder interface {
+	Encode(t models.Todo) error
+	// Flush writes any buffered output and reports the first write error
+	Flush() error
+}
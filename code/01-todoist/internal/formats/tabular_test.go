@@ -0,0 +1,162 @@
+package formats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"todoist/internal/models"
+)
+
+var sampleTodos = func() []models.Todo {
+	due := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	return []models.Todo{
+		{ID: 1, Title: "Pay rent, on time", Description: "landlord\nbank transfer", Status: models.StatusPending, DueAt: &due},
+		{ID: 2, Title: "Ship release", Status: models.StatusCompleted},
+		{ID: 3, Title: "Old idea", Status: models.StatusTrashed},
+	}
+}()
+
+func assertRecords(t testing.TB, got []Record, want []models.Todo) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d records want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Err != nil {
+			t.Errorf("record %d: unexpected error %v", i, g.Err)
+		}
+		if g.Title != w.Title || g.Description != w.Description || g.Status != w.Status {
+			t.Errorf("record %d: got %q/%q/%s want %q/%q/%s", i, g.Title, g.Description, g.Status, w.Title, w.Description, w.Status)
+		}
+		if (g.Due == nil) != (w.DueAt == nil) || (g.Due != nil && !g.Due.Equal(*w.DueAt)) {
+			t.Errorf("record %d: got due %v want %v", i, g.Due, w.DueAt)
+		}
+	}
+}
+
+func encodeAll(t testing.TB, enc Encoder, todos []models.Todo) {
+	t.Helper()
+	for _, todo := range todos {
+		if err := enc.Encode(todo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	encodeAll(t, NewCSVEncoder(&buf), sampleTodos)
+
+	got, err := ParseCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRecords(t, got, sampleTodos)
+	if got[0].UID != "todo-1@todoist" {
+		t.Errorf("got uid %q want todo-1@todoist", got[0].UID)
+	}
+}
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	todos := []models.Todo{
+		{ID: 1, Title: "=HYPERLINK(\"http://evil\",\"x\")", Description: "+1 555 0100", Status: models.StatusPending},
+		{ID: 2, Title: "-5 things", Description: "@mention", Status: models.StatusPending},
+		{ID: 3, Title: "\tindented", Description: "'quoted", Status: models.StatusPending},
+		{ID: 4, Title: "plain 'title'", Status: models.StatusPending},
+	}
+
+	var buf bytes.Buffer
+	encodeAll(t, NewCSVEncoder(&buf), todos)
+	for _, cell := range []string{`"'=HYPERLINK(""http://evil"",""x"")"`, "'+1 555 0100", "'-5 things", "'@mention", "'\tindented", "''quoted", ",plain 'title',"} {
+		if !strings.Contains(buf.String(), cell) {
+			t.Errorf("export has no cell %q:\n%s", cell, buf.String())
+		}
+	}
+
+	got, err := ParseCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRecords(t, got, todos)
+}
+
+func TestTimesheetEscapesFormulas(t *testing.T) {
+	start := time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	rows := []models.TimesheetRow{{TimeEntry: models.TimeEntry{TodoID: 1, Start: start, End: &end, Note: "@SUM(A1:A9)"}, Title: "=1+1"}}
+
+	var buf bytes.Buffer
+	if err := WriteTimesheet(&buf, rows, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), ",'=1+1,'@SUM(A1:A9)\n") {
+		t.Errorf("got %q want the title and note escaped", buf.String())
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	t.Run("reports bad rows by line", func(t *testing.T) {
+		src := "Title,Status,Due\nok,completed,2026-01-02\nbad status,DONE,\nbad due,,tomorrow\n"
+
+		got, err := ParseCSV(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("got %d records want 3", len(got))
+		}
+		if got[0].Err != nil || got[0].Status != models.StatusCompleted {
+			t.Errorf("got %+v want completed record", got[0])
+		}
+		if got[1].Err == nil || got[1].Line != 3 {
+			t.Errorf("got %+v want error on line 3", got[1])
+		}
+		if got[2].Err == nil || got[2].Line != 4 {
+			t.Errorf("got %+v want error on line 4", got[2])
+		}
+	})
+
+	t.Run("requires a title column", func(t *testing.T) {
+		if _, err := ParseCSV(strings.NewReader("name\nx\n")); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	encodeAll(t, NewMarkdownEncoder(&buf), sampleTodos)
+
+	if !strings.HasPrefix(buf.String(), "- [ ] Pay rent, on time (due: 2026-03-01)\n  landlord\n") {
+		t.Errorf("unexpected markdown:\n%s", buf.String())
+	}
+
+	got, err := ParseMarkdown(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRecords(t, got, sampleTodos)
+}
+
+func TestParseMarkdown(t *testing.T) {
+	src := "# Groceries\n\nSome prose.\n* [X] milk\n- [ ] eggs (due: someday)\n"
+
+	got, err := ParseMarkdown(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d records want 2", len(got))
+	}
+	if got[0].Title != "milk" || got[0].Status != models.StatusCompleted || got[0].Line != 4 {
+		t.Errorf("got %+v want completed milk on line 4", got[0])
+	}
+	if got[1].Err == nil {
+		t.Error("expected error for unparseable due date")
+	}
+}
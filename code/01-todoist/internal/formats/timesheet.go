@@ -0,0 +1,41 @@
+package formats
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+	"todoist/internal/models"
+)
+
+var timesheetColumns = []string{"date", "start", "end", "hours", "seconds", "todoId", "title", "note"}
+
+// WriteTimesheet writes one row per finished time entry with dates and times in loc.
+// Hours are rounded to two decimals; seconds are exact for anyone re-summing. Titles and
+// notes are escaped like the CSV export's, so none of them opens as a formula.
+func WriteTimesheet(w io.Writer, rows []models.TimesheetRow, loc *time.Location) error {
+	cw := csv.NewWriter(w)
+	cw.Write(timesheetColumns)
+
+	for _, r := range rows {
+		if r.End == nil {
+			continue
+		}
+		start, end := r.Start.In(loc), r.End.In(loc)
+		seconds := int64(r.End.Sub(r.Start) / time.Second)
+
+		cw.Write([]string{
+			start.Format(time.DateOnly),
+			start.Format(time.RFC3339),
+			end.Format(time.RFC3339),
+			strconv.FormatFloat(float64(seconds)/3600, 'f', 2, 64),
+			strconv.FormatInt(seconds, 10),
+			strconv.Itoa(r.TodoID),
+			escapeCell(r.Title),
+			escapeCell(r.Note),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"todoist/internal/backup"
+)
+
+// maxRestoreSize bounds the compressed archive accepted by Restore
+const maxRestoreSize = 1 << 30
+
+// AdminHandler serves operator endpoints. Every request must carry Token as a bearer token.
+type AdminHandler struct {
+	Stores backup.Stores
+	Token  string
+	now    func() time.Time
+}
+
+func NewAdminHandler(stores backup.Stores, token string) *AdminHandler {
+	return &AdminHandler{Stores: stores, Token: token, now: time.Now}
+}
+
+func (h *AdminHandler) authorized(w http.ResponseWriter, r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || h.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.Token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// Backup serves GET /admin/backup
+func (h *AdminHandler) Backup(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	now := h.now().UTC()
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="todoist-%s.backup"`, now.Format("20060102T150405Z")))
+
+	// once the first byte is out the status is sent; a later failure leaves the archive without its trailer
+	if _, err := backup.Write(r.Context(), w, h.Stores, now); err != nil {
+		writeError(w, err)
+	}
+}
+
+// Restore serves POST /admin/restore
+func (h *AdminHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	summary, err := backup.Restore(r.Context(), http.MaxBytesReader(w, r.Body, maxRestoreSize), h.Stores)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(summary)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+// ChecksumHeader carries the hex SHA-256 a client expects its upload to have
+const ChecksumHeader = "X-Checksum-SHA256"
+
+// multipartOverhead is the room allowed for boundaries and part headers on top of the file itself
+const multipartOverhead = 64 << 10
+
+type AttachmentHandler struct {
+	Service services.IAttachmentService
+	MaxSize int64
+}
+
+func NewAttachmentHandler(s services.IAttachmentService, maxSize int64) *AttachmentHandler {
+	return &AttachmentHandler{Service: s, MaxSize: maxSize}
+}
+
+// Upload serves POST /todos/{id}/attachments. The body is multipart with the file in a part
+// named "file"; the uploader comes from a "userid" field sent before it or the userid query parameter.
+// The file part is streamed straight to storage rather than buffered.
+func (h *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	todoID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxSize+multipartOverhead)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "expected a multipart/form-data body", http.StatusBadRequest)
+		return
+	}
+
+	dto := models.UploadAttachment{
+		TodoID:   todoID,
+		UserID:   r.URL.Query().Get("userid"),
+		Checksum: r.Header.Get(ChecksumHeader),
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			http.Error(w, `missing "file" part`, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		switch part.FormName() {
+		case "userid":
+			value, _ := io.ReadAll(io.LimitReader(part, 256))
+			dto.UserID = string(value)
+			continue
+		case "file":
+		default:
+			continue
+		}
+
+		dto.Filename = part.FileName()
+		dto.Body = part
+
+		attachment, err := h.Service.Upload(r.Context(), dto)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(attachment)
+		return
+	}
+}
+
+// List serves GET /todos/{id}/attachments?userid=
+func (h *AttachmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	todoID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	attachments, err := h.Service.ListAttachments(r.Context(), todoID, r.URL.Query().Get("userid"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// Download serves GET /todos/{id}/attachments/{attachmentID}?userid=. http.ServeContent takes care of
+// Range, If-Range and conditional requests against the checksum ETag.
+func (h *AttachmentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	todoID, id, ok := pathIDs(r, "attachmentID")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	attachment, f, err := h.Service.Open(r.Context(), todoID, r.URL.Query().Get("userid"), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer f.Close()
+
+	// uploads are untrusted: never let a browser render them inline or guess another type
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("ETag", `"`+attachment.SHA256+`"`)
+
+	http.ServeContent(w, r, attachment.Filename, attachment.CreatedAt, f)
+}
+
+// Delete serves DELETE /todos/{id}/attachments/{attachmentID}?userid=
+func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	todoID, id, ok := pathIDs(r, "attachmentID")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteAttachment(r.Context(), todoID, r.URL.Query().Get("userid"), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"todoist/internal/blobs"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+	"todoist/internal/services"
+)
+
+func newAttachmentServer(t *testing.T) *httptest.Server {
+	store, err := blobs.NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	todos := services.NewTodoService(repositories.NewInMemoryTodoRepo())
+	todos.CreateTodo(context.Background(), models.CreateTodo{UserID: "alice", Title: "with files"})
+
+	h := NewAttachmentHandler(services.NewAttachmentService(repositories.NewInMemoryAttachmentRepo(), store, todos, services.WithMaxAttachmentSize(64)), 64)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /todos/{id}/attachments", h.Upload)
+	mux.HandleFunc("GET /todos/{id}/attachments/{attachmentID}", h.Download)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func upload(t *testing.T, url, content string) *http.Response {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("userid", "alice")
+	part, _ := mw.CreateFormFile("file", "digits.txt")
+	io.WriteString(part, content)
+	mw.Close()
+
+	resp, err := http.Post(url+"/todos/1/attachments", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestAttachmentUploadAndRangeDownload(t *testing.T) {
+	srv := newAttachmentServer(t)
+
+	resp := upload(t, srv.URL, "0123456789")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("got status %d want %d", resp.StatusCode, http.StatusCreated)
+	}
+	var a models.Attachment
+	json.NewDecoder(resp.Body).Decode(&a)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/todos/1/attachments/1?userid=alice", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	got, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Body.Close()
+	part, _ := io.ReadAll(got.Body)
+
+	if got.StatusCode != http.StatusPartialContent || string(part) != "2345" {
+		t.Errorf("got %d %q want 206 \"2345\"", got.StatusCode, part)
+	}
+	if got.Header.Get("ETag") != `"`+a.SHA256+`"` || got.Header.Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("got headers %v", got.Header)
+	}
+	if !strings.HasPrefix(got.Header.Get("Content-Disposition"), "attachment") {
+		t.Errorf("got disposition %q want attachment", got.Header.Get("Content-Disposition"))
+	}
+
+	for _, user := range []string{"bob", ""} {
+		other, err := http.Get(srv.URL + "/todos/1/attachments/1?userid=" + user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other.Body.Close()
+		if other.StatusCode != http.StatusNotFound {
+			t.Errorf("got status %d want %d for user %q", other.StatusCode, http.StatusNotFound, user)
+		}
+	}
+}
+
+func TestAttachmentUploadTooLarge(t *testing.T) {
+	srv := newAttachmentServer(t)
+
+	resp := upload(t, srv.URL, strings.Repeat("x", 100))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("got status %d want %d", resp.StatusCode, http.StatusRequestEntityTooLarge)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+type CommentHandler struct {
+	Service services.ICommentService
+}
+
+func NewCommentHandler(s services.ICommentService) *CommentHandler {
+	return &CommentHandler{Service: s}
+}
+
+// pathIDs reads the todo ID and, when the route has one, the comment or attachment ID
+func pathIDs(r *http.Request, name string) (todoID, id int, ok bool) {
+	todoID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, 0, false
+	}
+	if name == "" {
+		return todoID, 0, true
+	}
+	id, err = strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		return 0, 0, false
+	}
+	return todoID, id, true
+}
+
+// Create serves POST /todos/{id}/comments
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	todoID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	dto := models.CreateComment{}
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	dto.TodoID = todoID
+
+	comment, err := h.Service.AddComment(r.Context(), dto)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// List serves GET /todos/{id}/comments?userid=
+func (h *CommentHandler) List(w http.ResponseWriter, r *http.Request) {
+	todoID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	comments, err := h.Service.ListComments(r.Context(), todoID, r.URL.Query().Get("userid"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(comments)
+}
+
+// Update serves PUT /todos/{id}/comments/{commentID}
+func (h *CommentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	todoID, id, ok := pathIDs(r, "commentID")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	dto := models.UpdateComment{}
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	dto.ID, dto.TodoID = id, todoID
+
+	comment, err := h.Service.EditComment(r.Context(), dto)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(comment)
+}
+
+// Delete serves DELETE /todos/{id}/comments/{commentID}?userid=
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	todoID, id, ok := pathIDs(r, "commentID")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteComment(r.Context(), todoID, r.URL.Query().Get("userid"), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+type DependencyHandler struct {
+	Service services.IDependencyService
+}
+
+func NewDependencyHandler(s services.IDependencyService) *DependencyHandler {
+	return &DependencyHandler{Service: s}
+}
+
+// Add serves POST /todos/{id}/blockers
+func (h *DependencyHandler) Add(w http.ResponseWriter, r *http.Request) {
+	todoID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	dto := models.AddBlocker{}
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	dto.TodoID = todoID
+
+	if err := h.Service.AddBlocker(r.Context(), dto); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// List serves GET /todos/{id}/blockers
+func (h *DependencyHandler) List(w http.ResponseWriter, r *http.Request) {
+	todoID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	blockers, err := h.Service.ListBlockers(r.Context(), todoID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(blockers)
+}
+
+// Delete serves DELETE /todos/{id}/blockers/{blockerID}
+func (h *DependencyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	todoID, blockerID, ok := pathIDs(r, "blockerID")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.RemoveBlocker(r.Context(), todoID, blockerID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Plan serves GET /projects/{id}/plan
+func (h *DependencyHandler) Plan(w http.ResponseWriter, r *http.Request) {
+	projectID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	plan, err := h.Service.Plan(r.Context(), projectID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(plan)
+}
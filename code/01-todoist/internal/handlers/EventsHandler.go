@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"todoist/internal/events"
+)
+
+type EventsHandler struct {
+	Bus       *events.Bus
+	Heartbeat time.Duration
+}
+
+func NewEventsHandler(bus *events.Bus, heartbeat time.Duration) *EventsHandler {
+	return &EventsHandler{Bus: bus, Heartbeat: heartbeat}
+}
+
+// Stream serves GET /users/{id}/events as Server-Sent Events.
+// Clients reconnecting with Last-Event-ID get the events they missed that are still in the replay buffer.
+// When some of them are gone the stream starts with a reset event, after which the client
+// should pull what changed through POST /users/{id}/sync.
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	if userID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	var lastID uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		lastID = id
+	}
+
+	sub, backlog, missed := h.Bus.Subscribe(userID, lastID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if missed {
+		if _, err := io.WriteString(w, resetEvent); err != nil {
+			return
+		}
+	}
+	for _, e := range backlog {
+		if err := writeEvent(w, e); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(h.Heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// dropped for falling behind, the client resumes with Last-Event-ID
+				return
+			}
+			if err := writeEvent(w, e); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// resetEvent tells a client that resumed too late that it missed events. It has no id, so the
+// client's Last-Event-ID only moves on with the events that follow it.
+const resetEvent = "event: reset\ndata: {\"reason\":\"events since Last-Event-ID are no longer buffered, resync\"}\n\n"
+
+func writeEvent(w io.Writer, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
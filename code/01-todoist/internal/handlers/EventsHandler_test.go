@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"todoist/internal/events"
+)
+
+func TestStreamResetsAfterAGap(t *testing.T) {
+	bus := events.NewBus(1)
+	first := bus.Publish(events.Event{Type: events.TodoCreated, UserID: "alice"})
+	second := bus.Publish(events.Event{Type: events.TodoUpdated, UserID: "alice"})
+	third := bus.Publish(events.Event{Type: events.TodoDeleted, UserID: "alice"})
+	h := NewEventsHandler(bus, time.Hour)
+
+	stream := func(lastID uint64) string {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		req := httptest.NewRequest(http.MethodGet, "/users/alice/events", nil).WithContext(ctx)
+		req.SetPathValue("id", "alice")
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(lastID, 10))
+
+		rec := httptest.NewRecorder()
+		h.Stream(rec, req)
+		return rec.Body.String()
+	}
+
+	got := stream(first.ID)
+	if !strings.HasPrefix(got, "event: reset\n") || !strings.Contains(got, "id: "+strconv.FormatUint(third.ID, 10)) {
+		t.Errorf("got %q want a reset followed by the buffered event", got)
+	}
+	if got := stream(second.ID); strings.Contains(got, "reset") {
+		t.Errorf("got %q want no reset when nothing was missed", got)
+	}
+}
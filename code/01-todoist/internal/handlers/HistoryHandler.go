@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"todoist/internal/services"
+)
+
+type HistoryHandler struct {
+	Service services.IHistoryService
+}
+
+func NewHistoryHandler(s services.IHistoryService) *HistoryHandler {
+	return &HistoryHandler{Service: s}
+}
+
+// History serves GET /todos/{id}/history
+func (h *HistoryHandler) History(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.Service.TodoHistory(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(history)
+}
+
+// AsOf serves GET /todos/{id}/asof?at=RFC3339
+func (h *HistoryHandler) AsOf(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339, r.URL.Query().Get("at"))
+	if err != nil {
+		http.Error(w, "at must be an RFC 3339 timestamp", http.StatusBadRequest)
+		return
+	}
+
+	todo, err := h.Service.GetTodoAsOf(r.Context(), id, at)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(todo)
+}
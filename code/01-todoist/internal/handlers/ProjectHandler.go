@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+type ProjectHandler struct {
+	Service services.IProjectService
+}
+
+func NewProjectHandler(s services.IProjectService) *ProjectHandler {
+	return &ProjectHandler{Service: s}
+}
+
+// Create serves POST /users/{id}/projects
+func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request) {
+	dto := models.CreateProject{}
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	dto.UserID = r.PathValue("id")
+
+	project, err := h.Service.CreateProject(r.Context(), dto)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(project)
+}
+
+// List serves GET /users/{id}/projects
+func (h *ProjectHandler) List(w http.ResponseWriter, r *http.Request) {
+	projects, err := h.Service.ListProjects(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(projects)
+}
+
+// Get serves GET /projects/{id}
+func (h *ProjectHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.Service.GetProject(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(project)
+}
+
+// SetWorkflow serves PUT /projects/{id}/workflow
+func (h *ProjectHandler) SetWorkflow(w http.ResponseWriter, r *http.Request) {
+	id, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	workflow := models.Workflow{}
+	if err := json.NewDecoder(r.Body).Decode(&workflow); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.Service.SetWorkflow(r.Context(), id, workflow)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(project)
+}
+
+// Board serves GET /projects/{id}/board
+func (h *ProjectHandler) Board(w http.ResponseWriter, r *http.Request) {
+	id, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	board, err := h.Service.Board(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(board)
+}
+
+// Inbox serves GET /users/{id}/board, the board of todos outside any project
+func (h *ProjectHandler) Inbox(w http.ResponseWriter, r *http.Request) {
+	board, err := h.Service.InboxBoard(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(board)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+type QuickAddHandler struct {
+	Service services.IQuickAddService
+}
+
+func NewQuickAddHandler(s services.IQuickAddService) *QuickAddHandler {
+	return &QuickAddHandler{Service: s}
+}
+
+// QuickAdd serves POST /users/{id}/todos/quick
+func (h *QuickAddHandler) QuickAdd(w http.ResponseWriter, r *http.Request) {
+	dto := models.QuickAdd{}
+
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	dto.UserID = r.PathValue("id")
+
+	result, err := h.Service.QuickAdd(r.Context(), dto)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+type StatsHandler struct {
+	Service services.IStatsService
+}
+
+func NewStatsHandler(s services.IStatsService) *StatsHandler {
+	return &StatsHandler{Service: s}
+}
+
+// Stats serves GET /users/{id}/stats?tz=&days=&weeks=&months=
+func (h *StatsHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := models.StatsQuery{UserID: r.PathValue("id"), Days: 30, Weeks: 12, Months: 12}
+
+	loc, err := time.LoadLocation(query.Get("tz"))
+	if err != nil {
+		http.Error(w, "unknown time zone", http.StatusBadRequest)
+		return
+	}
+	q.Location = loc
+
+	for name, target := range map[string]*int{"days": &q.Days, "weeks": &q.Weeks, "months": &q.Months} {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				http.Error(w, "invalid "+name, http.StatusBadRequest)
+				return
+			}
+			*target = n
+		}
+	}
+
+	stats, err := h.Service.Stats(r.Context(), q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(stats)
+}
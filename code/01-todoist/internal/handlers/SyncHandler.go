@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+type SyncHandler struct {
+	Service services.ISyncService
+}
+
+func NewSyncHandler(s services.ISyncService) *SyncHandler {
+	return &SyncHandler{Service: s}
+}
+
+// Sync serves POST /users/{id}/sync
+func (h *SyncHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	req := models.SyncRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	req.UserID = r.PathValue("id")
+
+	resp, err := h.Service.Sync(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"todoist/internal/formats"
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+type TimeHandler struct {
+	Service services.ITimeService
+}
+
+func NewTimeHandler(s services.ITimeService) *TimeHandler {
+	return &TimeHandler{Service: s}
+}
+
+// Start serves POST /todos/{id}/timer/start
+func (h *TimeHandler) Start(w http.ResponseWriter, r *http.Request) {
+	todoID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	dto := models.StartTimer{}
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	dto.TodoID = todoID
+
+	entry, err := h.Service.StartTimer(r.Context(), dto)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// Stop serves POST /todos/{id}/timer/stop?userid=
+func (h *TimeHandler) Stop(w http.ResponseWriter, r *http.Request) {
+	todoID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	entry, err := h.Service.StopTimer(r.Context(), todoID, r.URL.Query().Get("userid"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(entry)
+}
+
+// Create serves POST /todos/{id}/time
+func (h *TimeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	todoID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	dto := models.CreateTimeEntry{}
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	dto.TodoID = todoID
+
+	entry, err := h.Service.AddEntry(r.Context(), dto)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// List serves GET /todos/{id}/time?userid=
+func (h *TimeHandler) List(w http.ResponseWriter, r *http.Request) {
+	todoID, _, ok := pathIDs(r, "")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	total, err := h.Service.TodoTime(r.Context(), todoID, r.URL.Query().Get("userid"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(total)
+}
+
+// Delete serves DELETE /todos/{id}/time/{entryID}?userid=
+func (h *TimeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	todoID, id, ok := pathIDs(r, "entryID")
+	if !ok {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteEntry(r.Context(), todoID, r.URL.Query().Get("userid"), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Totals serves GET /users/{id}/time?from=&to=&period=day|week|month&tz=
+func (h *TimeHandler) Totals(w http.ResponseWriter, r *http.Request) {
+	q, ok := timeQuery(w, r)
+	if !ok {
+		return
+	}
+
+	totals, err := h.Service.Totals(r.Context(), q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(totals)
+}
+
+// Timesheet serves GET /users/{id}/timesheet.csv?from=&to=&tz=
+func (h *TimeHandler) Timesheet(w http.ResponseWriter, r *http.Request) {
+	q, ok := timeQuery(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := h.Service.Timesheet(r.Context(), q)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="timesheet.csv"`)
+	formats.WriteTimesheet(w, rows, q.Location)
+}
+
+// timeQuery reads from and to as dates or RFC 3339 times in tz. Without them it covers the
+// last 30 days; a date-only to includes that whole day.
+func timeQuery(w http.ResponseWriter, r *http.Request) (models.TimeQuery, bool) {
+	query := r.URL.Query()
+
+	loc, err := time.LoadLocation(query.Get("tz"))
+	if err != nil {
+		http.Error(w, "unknown time zone", http.StatusBadRequest)
+		return models.TimeQuery{}, false
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	q := models.TimeQuery{
+		UserID:   r.PathValue("id"),
+		From:     today.AddDate(0, 0, -29),
+		To:       today.AddDate(0, 0, 1),
+		Period:   query.Get("period"),
+		Location: loc,
+	}
+
+	for name, target := range map[string]*time.Time{"from": &q.From, "to": &q.To} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			*target = t
+			continue
+		}
+		day, err := time.ParseInLocation(time.DateOnly, v, loc)
+		if err != nil {
+			http.Error(w, "invalid "+name, http.StatusBadRequest)
+			return models.TimeQuery{}, false
+		}
+		if name == "to" {
+			day = day.AddDate(0, 0, 1)
+		}
+		*target = day
+	}
+
+	return q, true
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+	"todoist/internal/services"
+)
+
+func TestTimeOnForeignTodo(t *testing.T) {
+	todos := services.NewTodoService(repositories.NewInMemoryTodoRepo())
+	todos.CreateTodo(context.Background(), models.CreateTodo{UserID: "alice", Title: "alice's"})
+
+	h := NewTimeHandler(services.NewTimeService(repositories.NewInMemoryTimeEntryRepo(), todos))
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /todos/{id}/timer/start", h.Start)
+	mux.HandleFunc("POST /todos/{id}/time", h.Create)
+	mux.HandleFunc("GET /todos/{id}/time", h.List)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"start timer", http.MethodPost, "/todos/1/timer/start", `{"userid":"bob"}`, http.StatusNotFound},
+		{"add entry", http.MethodPost, "/todos/1/time", `{"userid":"bob","start":"2026-03-09T09:00:00Z","end":"2026-03-09T10:00:00Z"}`, http.StatusNotFound},
+		{"list entries", http.MethodGet, "/todos/1/time?userid=bob", "", http.StatusNotFound},
+		{"list without user", http.MethodGet, "/todos/1/time", "", http.StatusNotFound},
+		{"owner lists entries", http.MethodGet, "/todos/1/time?userid=alice", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != tt.want {
+				t.Errorf("got status %d want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -66,10 +66,23 @@ func (h *TodoHandler) UsersHandler(w http.ResponseWriter, r *http.Request) {
 
 	todos, err := h.Service.ListTodos(r.Context(), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
+	// paging is opt-in so clients that expect the whole list keep working
+	if r.URL.Query().Has("limit") {
+		page, next, err := paginate(todos, r.URL.Query().Get("limit"), r.URL.Query().Get("cursor"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if next != "" {
+			w.Header().Set("X-Next-Cursor", next)
+		}
+		todos = page
+	}
+
 	json.NewEncoder(w).Encode(todos)
 }
 
@@ -85,7 +98,7 @@ func (h *TodoHandler) CreateTodoHandler(w http.ResponseWriter, r *http.Request)
 		todo, err := h.Service.CreateTodo(r.Context(), dto)
 
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
@@ -101,7 +114,7 @@ func (h *TodoHandler) GetTodo(w http.ResponseWriter, r *http.Request, id int) {
 	todo, err := h.Service.GetTodo(r.Context(), id)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
@@ -121,7 +134,7 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request, id int)
 	todo, err := h.Service.UpdateTodo(r.Context(), dto)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
@@ -130,9 +143,33 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request, id int)
 
 func (h *TodoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request, id int) {
 	if err := h.Service.DeleteTodo(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Move serves POST /todos/{id}/move
+func (h *TodoHandler) Move(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	dto := models.MoveTodo{}
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	dto.ID = id
+
+	todo, err := h.Service.MoveTodo(r.Context(), dto)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(todo)
+}
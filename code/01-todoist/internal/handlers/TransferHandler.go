@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"todoist/internal/formats"
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+// maxImportSize bounds uploaded import files
+const maxImportSize = 5 << 20
+
+// exportFlushEvery is how many rows are encoded between flushes, so a long export reaches
+// the client while it is being written. The todos themselves are listed up front.
+const exportFlushEvery = 100
+
+type TransferHandler struct {
+	Todos   services.ITodoService
+	Imports services.IImportService
+}
+
+func NewTransferHandler(todos services.ITodoService, imports services.IImportService) *TransferHandler {
+	return &TransferHandler{Todos: todos, Imports: imports}
+}
+
+// ExportICal serves GET /users/{id}/todos.ics
+func (h *TransferHandler) ExportICal(w http.ResponseWriter, r *http.Request) {
+	todos, err := h.filtered(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="todos.ics"`)
+	formats.WriteICal(w, todos)
+}
+
+// ExportCSV serves GET /users/{id}/todos.csv
+func (h *TransferHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, "text/csv; charset=utf-8", "todos.csv", formats.NewCSVEncoder)
+}
+
+// ExportMarkdown serves GET /users/{id}/todos.md
+func (h *TransferHandler) ExportMarkdown(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, "text/markdown; charset=utf-8", "todos.md", formats.NewMarkdownEncoder)
+}
+
+// export lists the user's matching todos and writes them out with newEncoder
+func (h *TransferHandler) export(w http.ResponseWriter, r *http.Request, contentType, filename string, newEncoder func(io.Writer) formats.Encoder) {
+	todos, err := h.filtered(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	flusher, _ := w.(http.Flusher)
+	enc := newEncoder(w)
+	for i, t := range todos {
+		if err := enc.Encode(t); err != nil {
+			return
+		}
+		if flusher != nil && (i+1)%exportFlushEvery == 0 {
+			if err := enc.Flush(); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+	enc.Flush()
+}
+
+// filtered lists the user's todos narrowed by the status, q, dueBefore and dueAfter query parameters
+func (h *TransferHandler) filtered(r *http.Request) ([]models.Todo, error) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", services.ErrInvalidInput, err)
+	}
+
+	todos, err := h.Todos.ListTodos(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	matched := todos[:0]
+	for _, t := range todos {
+		if filter.Matches(t) {
+			matched = append(matched, t)
+		}
+	}
+	return matched, nil
+}
+
+func parseFilter(r *http.Request) (models.TodoFilter, error) {
+	q := r.URL.Query()
+	filter := models.TodoFilter{Query: q.Get("q")}
+
+	if v := q.Get("status"); v != "" {
+		status, err := formats.ParseStatus(v)
+		if err != nil {
+			return models.TodoFilter{}, err
+		}
+		filter.Status = &status
+	}
+
+	for param, dst := range map[string]**time.Time{"dueBefore": &filter.DueBefore, "dueAfter": &filter.DueAfter} {
+		if v := q.Get(param); v != "" {
+			t, err := formats.ParseDue(v)
+			if err != nil {
+				return models.TodoFilter{}, err
+			}
+			*dst = t
+		}
+	}
+
+	return filter, nil
+}
+
+// Import serves POST /users/{id}/todos/import. The file is either the raw request body
+// or the "file" field of a multipart form. Its format is taken from the format query parameter
+// (ics, csv or md), then the content type or file name, and is otherwise sniffed.
+func (h *TransferHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	body, hint, err := importBody(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer body.Close()
+
+	if f := r.URL.Query().Get("format"); f != "" {
+		hint = f
+	}
+
+	reader := bufio.NewReader(body)
+	var records []formats.Record
+	switch importFormat(hint, reader) {
+	case "ics":
+		records, err = formats.ParseICal(reader)
+	case "md":
+		records, err = formats.ParseMarkdown(reader)
+	case "csv":
+		records, err = formats.ParseCSV(reader)
+	default:
+		http.Error(w, "unsupported import format", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.Imports.Import(r.Context(), r.PathValue("id"), records)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
+
+// importBody returns the uploaded file and a format hint from its content type or name
+func importBody(r *http.Request) (io.ReadCloser, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, mediaType, nil
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", err
+	}
+
+	hint := path.Ext(header.Filename)
+	if ct, _, err := mime.ParseMediaType(header.Header.Get("Content-Type")); err == nil && ct != "application/octet-stream" {
+		hint = ct
+	}
+	return file, hint, nil
+}
+
+func importFormat(hint string, r *bufio.Reader) string {
+	switch strings.TrimPrefix(strings.ToLower(hint), ".") {
+	case "ics", "ical", "text/calendar":
+		return "ics"
+	case "md", "markdown", "text/markdown":
+		return "md"
+	case "csv", "text/csv":
+		return "csv"
+	}
+
+	head, _ := r.Peek(512)
+	text := strings.TrimSpace(string(head))
+	switch {
+	case strings.HasPrefix(strings.ToUpper(text), "BEGIN:VCALENDAR"):
+		return "ics"
+	case formats.LooksLikeMarkdown(text):
+		return "md"
+	default:
+		return "csv"
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"todoist/internal/services"
+)
+
+type UndoHandler struct {
+	Service services.IUndoService
+}
+
+func NewUndoHandler(s services.IUndoService) *UndoHandler {
+	return &UndoHandler{Service: s}
+}
+
+// Undo serves POST /users/{id}/undo
+func (h *UndoHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	result, err := h.Service.Undo(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// Redo serves POST /users/{id}/redo
+func (h *UndoHandler) Redo(w http.ResponseWriter, r *http.Request) {
+	result, err := h.Service.Redo(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
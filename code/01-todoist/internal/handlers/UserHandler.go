@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+type UserHandler struct {
+	Service services.IUserService
+}
+
+func NewUserHandler(s services.IUserService) *UserHandler {
+	return &UserHandler{Service: s}
+}
+
+// Register serves POST /users
+func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
+	dto := models.RegisterUser{}
+
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Service.Register(r.Context(), dto)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+// Login serves POST /login
+func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+	dto := models.Login{}
+
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	dto.ClientIP = clientIP(r)
+
+	user, err := h.Service.Login(r.Context(), dto)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(user)
+}
+
+// ChangePassword serves PUT /users/{id}/password
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	dto := models.ChangePassword{}
+
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	dto.UserID = r.PathValue("id")
+	dto.ClientIP = clientIP(r)
+
+	if err := h.Service.ChangePassword(r.Context(), dto); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// clientIP is the address of the peer, without the port. Forwarding headers are ignored
+// because anyone can set them.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+type WebhookHandler struct {
+	Service services.IWebhookService
+}
+
+func NewWebhookHandler(s services.IWebhookService) *WebhookHandler {
+	return &WebhookHandler{Service: s}
+}
+
+// Create serves POST /users/{id}/webhooks
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	dto := models.CreateWebhook{}
+
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	dto.UserID = r.PathValue("id")
+
+	hook, err := h.Service.RegisterWebhook(r.Context(), dto)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hook)
+}
+
+// List serves GET /users/{id}/webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.Service.ListWebhooks(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(hooks)
+}
+
+// Delete serves DELETE /users/{id}/webhooks/{webhookID}
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("webhookID"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteWebhook(r.Context(), r.PathValue("id"), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Attempts serves GET /users/{id}/webhooks/{webhookID}/attempts
+func (h *WebhookHandler) Attempts(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("webhookID"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	attempts, err := h.Service.ListAttempts(r.Context(), r.PathValue("id"), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(attempts)
+}
+
+// DeadLetters serves GET /users/{id}/webhooks/deadletters
+func (h *WebhookHandler) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	letters, err := h.Service.ListDeadLetters(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(letters)
+}
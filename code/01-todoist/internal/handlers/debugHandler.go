@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"todoist/internal/repositories"
+)
+
+// CacheStatsHandler serves the hit/miss counters of a caching repository
+func CacheStatsHandler(repo *repositories.CachingTodoRepo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(repo.Stats())
+	}
+}
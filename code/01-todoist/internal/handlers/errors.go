@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"todoist/internal/backup"
+	"todoist/internal/repositories"
+	"todoist/internal/services"
+)
+
+// StatusClientClosedRequest is the non-standard status nginx uses when the client went away before the response
+const StatusClientClosedRequest = 499
+
+// writeError maps the service and repository sentinel errors onto status codes.
+// The body is the sentinel's message so clients can map it back.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrUnknownUser):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, services.ErrInvalidCredentials):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, services.ErrTooManyAttempts):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, backup.ErrBusy):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, backup.ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, services.ErrTooLarge), errors.As(err, new(*http.MaxBytesError)):
+		http.Error(w, services.ErrTooLarge.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, services.ErrChecksumMismatch), errors.Is(err, backup.ErrCorrupt), errors.Is(err, backup.ErrVersion):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, services.ErrInvalidTransition), errors.Is(err, services.ErrBlocked),
+		errors.Is(err, services.ErrDependencyCycle), errors.Is(err, services.ErrNothingToUndo),
+		errors.Is(err, services.ErrStale):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, repositories.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repositories.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, errors.ErrUnsupported):
+		http.Error(w, "not supported by this store", http.StatusNotImplemented)
+	case errors.Is(err, context.DeadlineExceeded):
+		http.Error(w, "request timed out", http.StatusGatewayTimeout)
+	case errors.Is(err, context.Canceled):
+		http.Error(w, "request canceled", StatusClientClosedRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
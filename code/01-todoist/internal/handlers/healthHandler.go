@@ -3,14 +3,80 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"runtime/debug"
+
+	"todoist/internal/health"
 )
 
-func HealthHandler(w http.ResponseWriter, r *http.Request) {
+type HealthHandler struct {
+	Checker *health.Checker
+}
+
+func NewHealthHandler(c *health.Checker) *HealthHandler {
+	return &HealthHandler{Checker: c}
+}
+
+// Livez reports that the process is up; it never touches dependencies
+func (h *HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"status": health.StatusOK})
+}
+
+// Readyz runs the registered dependency checks and returns 503 if any of them fail
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report := h.Checker.Run(r.Context())
+	if report.Status != health.StatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
+
+type versionInfo struct {
+	GoVersion string `json:"goVersion"`
+	Path      string `json:"path"`
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified"`
+}
+
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	if r.Method == http.MethodGet {
-		json.NewEncoder(w).Encode("health check ok")
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		http.Error(w, "build info unavailable", http.StatusInternalServerError)
 		return
 	}
 
-	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	v := versionInfo{
+		GoVersion: info.GoVersion,
+		Path:      info.Main.Path,
+		Version:   info.Main.Version,
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			v.Revision = s.Value
+		case "vcs.time":
+			v.Time = s.Value
+		case "vcs.modified":
+			v.Modified = s.Value == "true"
+		}
+	}
+
+	json.NewEncoder(w).Encode(v)
 }
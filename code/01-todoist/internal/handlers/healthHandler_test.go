@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"todoist/internal/health"
+)
+
+func TestHealthHandler(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	failing := false
+	checker.Register("repository", func(ctx context.Context) error {
+		if failing {
+			return errors.New("unreachable")
+		}
+		return nil
+	})
+	h := NewHealthHandler(checker)
+
+	serve := func(handler http.HandlerFunc, method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(method, "/", nil))
+		return rec
+	}
+
+	t.Run("livez", func(t *testing.T) {
+		if rec := serve(h.Livez, http.MethodGet); rec.Code != http.StatusOK {
+			t.Errorf("got %d want %d", rec.Code, http.StatusOK)
+		}
+		if rec := serve(h.Livez, http.MethodPost); rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("got %d want %d", rec.Code, http.StatusMethodNotAllowed)
+		}
+	})
+
+	t.Run("readyz", func(t *testing.T) {
+		tests := []struct {
+			failing bool
+			code    int
+			status  string
+		}{
+			{false, http.StatusOK, health.StatusOK},
+			{true, http.StatusServiceUnavailable, health.StatusFail},
+		}
+		for _, tt := range tests {
+			failing = tt.failing
+			rec := serve(h.Readyz, http.MethodGet)
+			if rec.Code != tt.code {
+				t.Errorf("failing=%v: got %d want %d", tt.failing, rec.Code, tt.code)
+			}
+
+			var report health.Report
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+			if report.Status != tt.status || len(report.Checks) != 1 || report.Checks[0].Status != tt.status {
+				t.Errorf("failing=%v: got %+v want status %s", tt.failing, report, tt.status)
+			}
+			if tt.failing && report.Checks[0].Error != "unreachable" {
+				t.Errorf("got error %q want the check's error", report.Checks[0].Error)
+			}
+		}
+	})
+
+	t.Run("version", func(t *testing.T) {
+		rec := serve(h.Version, http.MethodGet)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got %d want %d", rec.Code, http.StatusOK)
+		}
+
+		var v versionInfo
+		if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+			t.Fatal(err)
+		}
+		if v.GoVersion != runtime.Version() {
+			t.Errorf("got go version %q want %q", v.GoVersion, runtime.Version())
+		}
+		if rec := serve(h.Version, http.MethodDelete); rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("got %d want %d", rec.Code, http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Timeout gives every request a deadline that the service and repository layers observe
+// through the request context. Requests matching one of the exempt ServeMux patterns, such
+// as long-lived event streams, are left alone.
+func Timeout(d time.Duration, next http.Handler, exempt ...string) http.Handler {
+	skip := matcher(exempt)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if skip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WriteBarrier holds mu shared for every request that may write, so whoever holds it
+// exclusively, such as a backup, sees no write half done. A request holds it while its body
+// arrives too, so the exclusive side should use TryLock, as backup does, rather than queue in
+// Lock and hold up every new write behind a slow client. Routes that take mu themselves must
+// be among the exempt ServeMux patterns.
+func WriteBarrier(mu *sync.RWMutex, next http.Handler, exempt ...string) http.Handler {
+	skip := matcher(exempt)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if skip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mu.RLock()
+		defer mu.RUnlock()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// matcher reports whether a request matches any of the ServeMux patterns
+func matcher(patterns []string) func(*http.Request) bool {
+	mux := http.NewServeMux()
+	for _, pattern := range patterns {
+		mux.Handle(pattern, http.NotFoundHandler())
+	}
+
+	return func(r *http.Request) bool {
+		_, pattern := mux.Handler(r)
+		return pattern != ""
+	}
+}
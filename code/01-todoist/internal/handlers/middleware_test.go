@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"todoist/internal/models"
+	"todoist/internal/services"
+)
+
+// blockingService waits for the request context to end and returns its error
+type blockingService struct {
+	services.ITodoService
+}
+
+func (blockingService) GetTodo(ctx context.Context, id int) (models.Todo, error) {
+	<-ctx.Done()
+	return models.Todo{}, ctx.Err()
+}
+
+func TestTimeout(t *testing.T) {
+	h := NewTodoHandler(blockingService{})
+	server := Timeout(20*time.Millisecond, http.HandlerFunc(h.TodoByIDHandler))
+
+	t.Run("deadline maps to 504", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todos/1", nil))
+
+		if rec.Code != http.StatusGatewayTimeout {
+			t.Errorf("got %d want %d", rec.Code, http.StatusGatewayTimeout)
+		}
+	})
+
+	t.Run("client cancellation maps to 499", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todos/1", nil).WithContext(ctx))
+
+		if rec.Code != StatusClientClosedRequest {
+			t.Errorf("got %d want %d", rec.Code, StatusClientClosedRequest)
+		}
+	})
+
+	t.Run("exempt routes get no deadline", func(t *testing.T) {
+		var deadlineSet bool
+		probe := Timeout(time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, deadlineSet = r.Context().Deadline()
+		}), "GET /users/{id}/events")
+
+		tests := []struct {
+			name   string
+			method string
+			path   string
+			accept string
+			want   bool
+		}{
+			{"stream without accept header", http.MethodGet, "/users/alice/events", "", false},
+			{"stream with parameters in accept", http.MethodGet, "/users/alice/events", "text/event-stream; charset=utf-8", false},
+			{"other route asking for a stream", http.MethodGet, "/todos/1", "text/event-stream", true},
+			{"other method on stream path", http.MethodPost, "/users/alice/events", "", true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, tt.path, nil)
+				if tt.accept != "" {
+					req.Header.Set("Accept", tt.accept)
+				}
+				probe.ServeHTTP(httptest.NewRecorder(), req)
+
+				if deadlineSet != tt.want {
+					t.Errorf("got deadline %v want %v", deadlineSet, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestWriteBarrier(t *testing.T) {
+	var mu sync.RWMutex
+	var held bool
+	probe := WriteBarrier(&mu, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a backup cannot take the lock while a write holds it shared
+		held = !mu.TryLock()
+		if !held {
+			mu.Unlock()
+		}
+	}), "/admin/")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   bool
+	}{
+		{"writes hold the barrier", http.MethodPost, "/todos", true},
+		{"deletes hold the barrier", http.MethodDelete, "/todos/1", true},
+		{"reads pass through", http.MethodGet, "/todos/1", false},
+		{"exempt routes pass through", http.MethodPost, "/admin/restore", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			if held != tt.want {
+				t.Errorf("got barrier held %v want %v", held, tt.want)
+			}
+		})
+	}
+}
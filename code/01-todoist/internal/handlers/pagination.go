@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"todoist/internal/models"
+)
+
+const maxPageSize = 500
+
+var errInvalidPage = errors.New("invalid limit or cursor")
+
+// paginate orders todos by position and returns the page after cursor.
+// The cursor holds the last position and ID seen; the returned cursor is empty on the last page.
+func paginate(todos []models.Todo, limitStr, cursor string) ([]models.Todo, string, error) {
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 || limit > maxPageSize {
+		return nil, "", errInvalidPage
+	}
+
+	var after models.Todo
+	if cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, "", errInvalidPage
+		}
+		// position keys never contain a slash
+		position, id, _ := strings.Cut(string(raw), "/")
+		if after.ID, err = strconv.Atoi(id); err != nil {
+			return nil, "", errInvalidPage
+		}
+		after.Position = position
+	}
+
+	slices.SortFunc(todos, models.ComparePosition)
+	start := sort.Search(len(todos), func(i int) bool { return models.ComparePosition(todos[i], after) > 0 })
+
+	page := todos[start:]
+	if len(page) <= limit {
+		return page, "", nil
+	}
+
+	page = page[:limit]
+	last := page[len(page)-1]
+	next := base64.RawURLEncoding.EncodeToString([]byte(last.Position + "/" + strconv.Itoa(last.ID)))
+	return page, next, nil
+}
@@ -0,0 +1,39 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Heartbeat is beaten by background workers on every loop iteration
+type Heartbeat struct {
+	last atomic.Int64
+}
+
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+func (h *Heartbeat) Last() time.Time {
+	n := h.last.Load()
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// Check fails when the worker has not beaten within maxAge
+func (h *Heartbeat) Check(maxAge time.Duration) CheckFunc {
+	return func(ctx context.Context) error {
+		last := h.Last()
+		if last.IsZero() {
+			return fmt.Errorf("no heartbeat yet")
+		}
+		if age := time.Since(last); age > maxAge {
+			return fmt.Errorf("last heartbeat %s ago", age.Round(time.Millisecond))
+		}
+		return nil
+	}
+}
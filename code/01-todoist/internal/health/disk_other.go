@@ -0,0 +1,12 @@
+//go:build !(linux || darwin)
+
+package health
+
+import "context"
+
+// DiskSpace is not supported on this platform and always passes
+func DiskSpace(path string, minFree uint64) CheckFunc {
+	return func(ctx context.Context) error {
+		return nil
+	}
+}
@@ -0,0 +1,25 @@
+//go:build linux || darwin
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+)
+
+// DiskSpace fails when the filesystem holding path has less than minFree bytes available
+func DiskSpace(path string, minFree uint64) CheckFunc {
+	return func(ctx context.Context) error {
+		var st syscall.Statfs_t
+		if err := syscall.Statfs(path, &st); err != nil {
+			return err
+		}
+
+		free := uint64(st.Bavail) * uint64(st.Bsize)
+		if free < minFree {
+			return fmt.Errorf("%d bytes free, want at least %d", free, minFree)
+		}
+		return nil
+	}
+}
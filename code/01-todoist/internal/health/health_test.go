@@ -0,0 +1,65 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChecker(t *testing.T) {
+	t.Run("all checks passing reports ok", func(t *testing.T) {
+		c := NewChecker(time.Second)
+		c.Register("a", func(ctx context.Context) error { return nil })
+		c.Register("b", func(ctx context.Context) error { return nil })
+
+		report := c.Run(context.Background())
+		if report.Status != StatusOK {
+			t.Errorf("got %q want %q", report.Status, StatusOK)
+		}
+		if len(report.Checks) != 2 {
+			t.Fatalf("got %d checks want 2", len(report.Checks))
+		}
+	})
+
+	t.Run("failing check fails the report", func(t *testing.T) {
+		c := NewChecker(time.Second)
+		c.Register("ok", func(ctx context.Context) error { return nil })
+		c.Register("broken", func(ctx context.Context) error { return errors.New("boom") })
+
+		report := c.Run(context.Background())
+		if report.Status != StatusFail {
+			t.Errorf("got %q want %q", report.Status, StatusFail)
+		}
+		if got := report.Checks[1]; got.Status != StatusFail || got.Error != "boom" {
+			t.Errorf("got %+v want failing check with error boom", got)
+		}
+	})
+
+	t.Run("slow check times out", func(t *testing.T) {
+		c := NewChecker(10 * time.Millisecond)
+		c.Register("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+		report := c.Run(context.Background())
+		if got := report.Checks[0].Error; got != ErrTimeout.Error() {
+			t.Errorf("got %q want %q", got, ErrTimeout.Error())
+		}
+	})
+}
+
+func TestHeartbeat(t *testing.T) {
+	var hb Heartbeat
+	check := hb.Check(time.Minute)
+
+	if err := check(context.Background()); err == nil {
+		t.Error("expected error before first beat")
+	}
+
+	hb.Beat()
+	if err := check(context.Background()); err != nil {
+		t.Errorf("unexpected error after beat: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"io"
+	"time"
+)
+
+// Attachment describes a file stored for a todo. The contents live in a blob store under BlobKey.
+type Attachment struct {
+	ID          int       `json:"id"`
+	TodoID      int       `json:"todoId"`
+	UserID      string    `json:"userid"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	BlobKey     string    `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type UploadAttachment struct {
+	TodoID   int
+	UserID   string
+	Filename string
+	// Checksum is the hex SHA-256 the client expects; the upload is rejected if the stored bytes differ
+	Checksum string
+	Body     io.Reader
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Comment belongs to a todo. Replies point at the comment they answer through ParentID.
+type Comment struct {
+	ID        int       `json:"id"`
+	TodoID    int       `json:"todoId"`
+	UserID    string    `json:"userid"`
+	ParentID  *int      `json:"parentId,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateComment struct {
+	TodoID   int    `json:"-"`
+	UserID   string `json:"userid"`
+	ParentID *int   `json:"parentId"`
+	Body     string `json:"body"`
+}
+
+type UpdateComment struct {
+	ID     int    `json:"-"`
+	TodoID int    `json:"-"`
+	UserID string `json:"userid"`
+	Body   string `json:"body"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// Dependency says TodoID cannot be completed before BlockerID
+type Dependency struct {
+	TodoID    int `json:"todoId"`
+	BlockerID int `json:"blockerId"`
+}
+
+// AddBlocker makes TodoID wait for BlockerID to be completed
+type AddBlocker struct {
+	TodoID    int `json:"-"`
+	BlockerID int `json:"blockerId"`
+}
+
+// PlanStep is a todo in plan order with the todos it waits for
+type PlanStep struct {
+	Todo      Todo  `json:"todo"`
+	BlockedBy []int `json:"blockedBy"`
+	// Ready is true when no blocker is still pending
+	Ready bool `json:"ready"`
+}
+
+// Plan orders a project's todos so every todo comes after its blockers. The critical path is
+// the longest chain of pending todos, each counting as one step, that has to be worked through in order.
+type Plan struct {
+	ProjectID    int        `json:"projectId"`
+	Steps        []PlanStep `json:"steps"`
+	CriticalPath []int      `json:"criticalPath"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// TodoFilter narrows a list of todos; zero fields match everything
+type TodoFilter struct {
+	Status    *TodoStatus
+	Query     string
+	DueBefore *time.Time
+	DueAfter  *time.Time
+}
+
+// Matches reports whether t passes every set field. Query matches title or description case-insensitively.
+func (f TodoFilter) Matches(t Todo) bool {
+	if f.Status != nil && t.Status != *f.Status {
+		return false
+	}
+	if f.Query != "" {
+		q := strings.ToLower(f.Query)
+		if !strings.Contains(strings.ToLower(t.Title), q) && !strings.Contains(strings.ToLower(t.Description), q) {
+			return false
+		}
+	}
+	if f.DueBefore != nil && (t.DueAt == nil || !t.DueAt.Before(*f.DueBefore)) {
+		return false
+	}
+	if f.DueAfter != nil && (t.DueAt == nil || !t.DueAt.After(*f.DueAfter)) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package models
+
+type ImportResult string
+
+const (
+	ImportCreated ImportResult = "created"
+	ImportUpdated ImportResult = "updated"
+	ImportSkipped ImportResult = "skipped"
+	ImportFailed  ImportResult = "error"
+)
+
+// ImportItem reports what happened to one record of an imported file
+type ImportItem struct {
+	Line   int          `json:"line"`
+	UID    string       `json:"uid,omitempty"`
+	ID     int          `json:"id,omitempty"`
+	Result ImportResult `json:"result"`
+	Error  string       `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	Created int          `json:"created"`
+	Updated int          `json:"updated"`
+	Skipped int          `json:"skipped"`
+	Failed  int          `json:"failed"`
+	Items   []ImportItem `json:"items"`
+}
+
+func (r *ImportReport) Add(item ImportItem) {
+	switch item.Result {
+	case ImportCreated:
+		r.Created++
+	case ImportUpdated:
+		r.Updated++
+	case ImportSkipped:
+		r.Skipped++
+	case ImportFailed:
+		r.Failed++
+	}
+	r.Items = append(r.Items, item)
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type Project struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"userid"`
+	Name      string    `json:"name"`
+	Workflow  Workflow  `json:"workflow"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateProject struct {
+	UserID string `json:"-"`
+	Name   string `json:"name"`
+	// Workflow defaults to DefaultWorkflow when omitted
+	Workflow *Workflow `json:"workflow"`
+}
+
+// BoardColumn is one state of a board with its todos in position order
+type BoardColumn struct {
+	State WorkflowState `json:"state"`
+	Todos []Todo        `json:"todos"`
+}
+
+type Board struct {
+	// Project is nil for the board of todos outside any project
+	Project *Project      `json:"project,omitempty"`
+	Columns []BoardColumn `json:"columns"`
+}
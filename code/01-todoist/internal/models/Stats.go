@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// PeriodCount is the activity in one day ("2026-03-10"), ISO week ("2026-W11") or month ("2026-03")
+type PeriodCount struct {
+	Period    string `json:"period"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+type Stats struct {
+	TimeZone string        `json:"timeZone"`
+	Daily    []PeriodCount `json:"daily"`
+	Weekly   []PeriodCount `json:"weekly"`
+	Monthly  []PeriodCount `json:"monthly"`
+	// AverageCompletion is the mean time from creation to completion, as a Go duration string
+	AverageCompletion        string             `json:"averageCompletion"`
+	AverageCompletionSeconds float64            `json:"averageCompletionSeconds"`
+	CurrentStreak            int                `json:"currentStreak"`
+	LongestStreak            int                `json:"longestStreak"`
+	ByStatus                 map[TodoStatus]int `json:"byStatus"`
+}
+
+type StatsQuery struct {
+	UserID string
+	// Location decides where day, week and month boundaries fall; nil means UTC
+	Location *time.Location
+	Days     int
+	Weeks    int
+	Months   int
+}
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+type ConflictStrategy string
+
+const (
+	// LastWriterWins keeps whichever side changed the todo most recently
+	LastWriterWins ConflictStrategy = "lww"
+	// FieldMerge applies the client's fields that the server has not changed since the client's base
+	FieldMerge ConflictStrategy = "merge"
+)
+
+type MutationOp string
+
+const (
+	MutationCreate MutationOp = "create"
+	MutationUpdate MutationOp = "update"
+	MutationDelete MutationOp = "delete"
+)
+
+// TodoFields carries the client-editable fields of a todo; nil means "not set"
+type TodoFields struct {
+	Title       *string     `json:"title,omitempty"`
+	Description *string     `json:"description,omitempty"`
+	Status      *TodoStatus `json:"status,omitempty"`
+}
+
+// SyncMutation is a write the client queued while offline.
+// Base holds the field values the client last saw from the server and is used for field-level merges.
+type SyncMutation struct {
+	ClientRef string      `json:"clientRef"`
+	Op        MutationOp  `json:"op"`
+	ID        int         `json:"id"`
+	Fields    TodoFields  `json:"fields"`
+	Base      *TodoFields `json:"base,omitempty"`
+	// BaseUpdatedAt is the server's updatedAt for the todo when the client last synced it
+	BaseUpdatedAt time.Time `json:"baseUpdatedAt"`
+	// ModifiedAt is when the client made the change
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+type SyncRequest struct {
+	UserID    string           `json:"-"`
+	Token     string           `json:"token"`
+	Strategy  ConflictStrategy `json:"strategy"`
+	Mutations []SyncMutation   `json:"mutations"`
+}
+
+type MutationStatus string
+
+const (
+	MutationApplied  MutationStatus = "applied"
+	MutationConflict MutationStatus = "conflict"
+	MutationRejected MutationStatus = "rejected"
+)
+
+type MutationResult struct {
+	ClientRef string         `json:"clientRef"`
+	ID        int            `json:"id"`
+	Status    MutationStatus `json:"status"`
+	Error     string         `json:"error,omitempty"`
+}
+
+type Tombstone struct {
+	ID        int       `json:"id"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+type SyncResponse struct {
+	Token   string           `json:"token"`
+	Changed []Todo           `json:"changed"`
+	Deleted []Tombstone      `json:"deleted"`
+	Results []MutationResult `json:"results"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// TimeEntry is a span of work on a todo. End is nil while the entry is a running timer.
+type TimeEntry struct {
+	ID        int        `json:"id"`
+	TodoID    int        `json:"todoId"`
+	UserID    string     `json:"userid"`
+	Start     time.Time  `json:"start"`
+	End       *time.Time `json:"end,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (e TimeEntry) Running() bool {
+	return e.End == nil
+}
+
+// Duration measures a running entry up to now
+func (e TimeEntry) Duration(now time.Time) time.Duration {
+	if e.End == nil {
+		return now.Sub(e.Start)
+	}
+	return e.End.Sub(e.Start)
+}
+
+// Overlaps reports whether two entries share any instant. A running entry extends forever,
+// so a user with a running timer cannot have any entry starting after it.
+func (e TimeEntry) Overlaps(o TimeEntry) bool {
+	return (o.End == nil || e.Start.Before(*o.End)) && (e.End == nil || o.Start.Before(*e.End))
+}
+
+type StartTimer struct {
+	TodoID int    `json:"-"`
+	UserID string `json:"userid"`
+	Note   string `json:"note"`
+}
+
+type CreateTimeEntry struct {
+	TodoID int       `json:"-"`
+	UserID string    `json:"userid"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Note   string    `json:"note"`
+}
+
+type TodoTime struct {
+	TodoID  int         `json:"todoId"`
+	Seconds int64       `json:"seconds"`
+	Running bool        `json:"running"`
+	Entries []TimeEntry `json:"entries"`
+}
+
+// TimeQuery selects a user's entries overlapping [From, To). Period is day, week or month
+// and is laid out in Location.
+type TimeQuery struct {
+	UserID   string
+	From     time.Time
+	To       time.Time
+	Period   string
+	Location *time.Location
+}
+
+type TimeTotals struct {
+	Seconds int64         `json:"seconds"`
+	Periods []PeriodTotal `json:"periods"`
+	Todos   []TodoTotal   `json:"todos"`
+}
+
+// PeriodTotal uses the same period keys as Stats: 2026-03-09, 2026-W11 or 2026-03
+type PeriodTotal struct {
+	Period  string `json:"period"`
+	Seconds int64  `json:"seconds"`
+}
+
+type TodoTotal struct {
+	TodoID  int    `json:"todoId"`
+	Title   string `json:"title"`
+	Seconds int64  `json:"seconds"`
+}
+
+// TimesheetRow is an entry with the title of its todo, which is empty when the todo has been deleted
+type TimesheetRow struct {
+	TimeEntry
+	Title string
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type TodoStatus string
 
@@ -10,20 +13,53 @@ const (
 	StatusTrashed   TodoStatus = "TRASHED"
 )
 
+// Priority is empty for todos without one
+type Priority string
+
+const (
+	PriorityLow    Priority = "LOW"
+	PriorityMedium Priority = "MEDIUM"
+	PriorityHigh   Priority = "HIGH"
+	PriorityUrgent Priority = "URGENT"
+)
+
 type Todo struct {
 	ID          int        `json:"id"`
 	UserID      string     `json:"userid"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Status      TodoStatus `json:"status"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	DueAt       *time.Time `json:"dueAt,omitempty"`
+	Labels      []string   `json:"labels,omitempty"`
+	Priority    Priority   `json:"priority,omitempty"`
+	// Recurrence is an RFC 5545 RRULE value such as FREQ=MONTHLY;BYMONTHDAY=1
+	Recurrence string `json:"recurrence,omitempty"`
+	// ProjectID is 0 for todos outside any project
+	ProjectID int `json:"projectId,omitempty"`
+	// State is the key of the todo's state in its project's workflow; Status always follows its category
+	State string `json:"state,omitempty"`
+	// Position is a rank key (see package rank); a user's todos are listed in ascending Position
+	Position string `json:"position,omitempty"`
+	// UID is the identifier a todo was imported under, kept so re-imports update instead of duplicating
+	UID       string    `json:"uid,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 type CreateTodo struct {
 	UserID      string
 	Title       string
 	Description string
+	DueAt       *time.Time
+	Labels      []string
+	Priority    Priority
+	Recurrence  string
+	ProjectID   int
+	// UID is only set by imports, to keep a calendar app's identifier; the server generates one otherwise
+	UID string `json:"-"`
+	// Status is only set by sync, for todos a client created and then changed offline. It moves
+	// the new todo out of its initial state under the same rules as an update.
+	Status TodoStatus `json:"-"`
 }
 
 //Update should allow partial updates, usually via pointers
@@ -32,4 +68,33 @@ type UpdateTodo struct {
 	Title       *string
 	Description *string
 	Status      *TodoStatus
+	DueAt       *time.Time
+	Labels      *[]string
+	Priority    *Priority
+	// State moves the todo to a workflow state; it must agree with Status when both are given
+	State *string
+}
+
+// ComparePosition orders todos by Position, falling back to ID for equal keys
+func ComparePosition(a, b Todo) int {
+	if c := strings.Compare(a.Position, b.Position); c != 0 {
+		return c
+	}
+	return a.ID - b.ID
+}
+
+// MoveTodo places a todo between two of its siblings. Either neighbour may be omitted to move
+// the todo directly after After or directly before Before.
+type MoveTodo struct {
+	ID     int  `json:"-"`
+	After  *int `json:"after"`
+	Before *int `json:"before"`
+}
+
+// QuickAdd is a todo written as one line of text, see package quickadd
+type QuickAdd struct {
+	UserID string `json:"-"`
+	Text   string `json:"text"`
+	// TimeZone is an IANA name such as Europe/Berlin; relative dates are read in it. Empty means UTC.
+	TimeZone string `json:"timeZone"`
 }
@@ -0,0 +1,7 @@
+package models
+
+// UndoResult lists what an undo or redo wrote: todos brought back or changed, and IDs removed
+type UndoResult struct {
+	Changed []Todo `json:"changed"`
+	Deleted []int  `json:"deleted"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// User is an account. ID is the name todos refer to in their UserID.
+type User struct {
+	ID           string    `json:"id"`
+	PasswordHash []byte    `json:"-"`
+	Salt         []byte    `json:"-"`
+	Iterations   int       `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type RegisterUser struct {
+	ID       string `json:"id"`
+	Password string `json:"password"`
+}
+
+type Login struct {
+	ID       string `json:"id"`
+	Password string `json:"password"`
+	ClientIP string `json:"-"`
+}
+
+type ChangePassword struct {
+	UserID      string `json:"-"`
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword"`
+	ClientIP    string `json:"-"`
+}
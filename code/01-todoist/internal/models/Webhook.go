@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+type Webhook struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"userid"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CreateWebhook struct {
+	UserID string
+	URL    string
+	Secret string
+	Events []string
+}
+
+// DeliveryAttempt records one POST of an event to a webhook
+type DeliveryAttempt struct {
+	WebhookID  int       `json:"webhookId"`
+	DeliveryID string    `json:"deliveryId"`
+	EventType  string    `json:"eventType"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Duration   string    `json:"duration"`
+	At         time.Time `json:"at"`
+}
+
+// DeadLetter is a delivery that ran out of attempts
+type DeadLetter struct {
+	WebhookID  int       `json:"webhookId"`
+	UserID     string    `json:"userid"`
+	DeliveryID string    `json:"deliveryId"`
+	EventType  string    `json:"eventType"`
+	Payload    []byte    `json:"payload"`
+	Attempts   int       `json:"attempts"`
+	LastError  string    `json:"lastError"`
+	FailedAt   time.Time `json:"failedAt"`
+}
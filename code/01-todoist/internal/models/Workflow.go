@@ -0,0 +1,109 @@
+package models
+
+// StateCategory tells the rest of the system what a workflow state means, whatever it is called
+type StateCategory string
+
+const (
+	CategoryOpen    StateCategory = "open"
+	CategoryDone    StateCategory = "done"
+	CategoryRemoved StateCategory = "removed"
+)
+
+// Status is the fixed status a todo in a state of this category reports
+func (c StateCategory) Status() TodoStatus {
+	switch c {
+	case CategoryDone:
+		return StatusCompleted
+	case CategoryRemoved:
+		return StatusTrashed
+	}
+	return StatusPending
+}
+
+func (c StateCategory) Valid() bool {
+	return c == CategoryOpen || c == CategoryDone || c == CategoryRemoved
+}
+
+type WorkflowState struct {
+	Key      string        `json:"key"`
+	Name     string        `json:"name"`
+	Category StateCategory `json:"category"`
+}
+
+type Transition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Workflow is an ordered list of states, like the columns of a board. With no transitions
+// listed a todo may move between any two states.
+type Workflow struct {
+	States      []WorkflowState `json:"states"`
+	Transitions []Transition    `json:"transitions,omitempty"`
+	// Initial is the state new todos start in; empty means the first open state
+	Initial string `json:"initial,omitempty"`
+}
+
+// DefaultWorkflow has one state per TodoStatus, keyed by the status itself, so todos
+// outside any project behave exactly as they always have
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		States: []WorkflowState{
+			{Key: string(StatusPending), Name: "Pending", Category: CategoryOpen},
+			{Key: string(StatusCompleted), Name: "Completed", Category: CategoryDone},
+			{Key: string(StatusTrashed), Name: "Trashed", Category: CategoryRemoved},
+		},
+		Initial: string(StatusPending),
+	}
+}
+
+func (w Workflow) State(key string) (WorkflowState, bool) {
+	for _, s := range w.States {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return WorkflowState{}, false
+}
+
+func (w Workflow) InitialState() WorkflowState {
+	if s, ok := w.State(w.Initial); ok {
+		return s
+	}
+	for _, s := range w.States {
+		if s.Category == CategoryOpen {
+			return s
+		}
+	}
+	return WorkflowState{}
+}
+
+// Allows reports whether a todo may move from one state to another. Staying put is always allowed.
+func (w Workflow) Allows(from, to string) bool {
+	if from == to || len(w.Transitions) == 0 {
+		return true
+	}
+	for _, t := range w.Transitions {
+		if t.From == from && t.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StateOf returns the state a todo is in. Todos written before workflows existed have no
+// State and are placed by their status.
+func (w Workflow) StateOf(t Todo) WorkflowState {
+	if s, ok := w.State(t.State); ok {
+		return s
+	}
+	if s, ok := w.State(string(t.Status)); ok {
+		return s
+	}
+	for _, s := range w.States {
+		if s.Category.Status() == t.Status {
+			return s
+		}
+	}
+	return w.InitialState()
+}
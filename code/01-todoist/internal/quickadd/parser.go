@@ -0,0 +1,631 @@
+// Package quickadd turns a single line such as
+// "Pay rent every month on the 1st at 9am #finance !high" into the fields of a todo.
+package quickadd
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todoist/internal/models"
+)
+
+// DefaultHour is the time of day given to due dates that name a day but no time
+const DefaultHour = 9
+
+type PartKind string
+
+const (
+	PartTitle      PartKind = "title"
+	PartDue        PartKind = "due"
+	PartTime       PartKind = "time"
+	PartRecurrence PartKind = "recurrence"
+	PartLabel      PartKind = "label"
+	PartPriority   PartKind = "priority"
+)
+
+// Part is one recognised piece of the input. Start and End are byte offsets so a UI can highlight them.
+type Part struct {
+	Kind  PartKind `json:"kind"`
+	Text  string   `json:"text"`
+	Start int      `json:"start"`
+	End   int      `json:"end"`
+}
+
+// Result is everything Parse understood
+type Result struct {
+	Title      string          `json:"title"`
+	Due        *time.Time      `json:"due,omitempty"`
+	Recurrence *Recurrence     `json:"recurrence,omitempty"`
+	Labels     []string        `json:"labels,omitempty"`
+	Priority   models.Priority `json:"priority,omitempty"`
+	Parts      []Part          `json:"parts"`
+}
+
+type word struct {
+	text  string
+	lower string
+	start int
+	end   int
+}
+
+// dateKind records how a date was given, which decides how it rolls forward when it is already past
+type dateKind int
+
+const (
+	noDate dateKind = iota
+	fixedDate
+	weekdayDate
+	monthDayDate
+	yearlyDate
+)
+
+type parser struct {
+	input string
+	now   time.Time
+	words []word
+	used  []bool
+	res   Result
+
+	kind      dateKind
+	year      int
+	month     time.Month
+	day       int
+	exact     *time.Time
+	hour, min int
+	hasTime   bool
+	defaultAt int
+}
+
+// Parse reads input relative to now in loc. Words that are not recognised make up the title,
+// in their original order and spelling.
+func Parse(input string, now time.Time, loc *time.Location) Result {
+	p := &parser{input: input, now: now.In(loc), words: split(input), defaultAt: DefaultHour}
+	p.used = make([]bool, len(p.words))
+
+	matchers := []func(i int) int{p.label, p.priority, p.recurrence, p.date, p.clock}
+	for i := 0; i < len(p.words); {
+		n := 0
+		for _, m := range matchers {
+			if n = m(i); n > 0 {
+				break
+			}
+		}
+		if n == 0 {
+			n = 1
+		} else {
+			for j := i; j < i+n; j++ {
+				p.used[j] = true
+			}
+		}
+		i += n
+	}
+
+	var title []string
+	for i, w := range p.words {
+		if !p.used[i] {
+			title = append(title, w.text)
+			p.res.Parts = append(p.res.Parts, Part{Kind: PartTitle, Text: w.text, Start: w.start, End: w.end})
+		}
+	}
+	p.res.Title = strings.Join(title, " ")
+	sort.Slice(p.res.Parts, func(i, j int) bool { return p.res.Parts[i].Start < p.res.Parts[j].Start })
+	p.resolve()
+
+	return p.res
+}
+
+func split(input string) []word {
+	var words []word
+	start := -1
+	for i, r := range input + " " {
+		if r == ' ' || r == '\t' || r == '\n' {
+			if start >= 0 {
+				text := input[start:i]
+				words = append(words, word{text: text, lower: strings.Trim(strings.ToLower(text), ",.;"), start: start, end: i})
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	return words
+}
+
+func (p *parser) lower(i int) string {
+	if i < 0 || i >= len(p.words) || p.used[i] {
+		return ""
+	}
+	return p.words[i].lower
+}
+
+func (p *parser) record(kind PartKind, i, n int) {
+	start, end := p.words[i].start, p.words[i+n-1].end
+	text := strings.TrimRight(p.input[start:end], ",.;")
+	p.res.Parts = append(p.res.Parts, Part{Kind: kind, Text: text, Start: start, End: start + len(text)})
+}
+
+var labelPattern = regexp.MustCompile(`^#([\p{L}\p{N}_/-]+)$`)
+
+func (p *parser) label(i int) int {
+	m := labelPattern.FindStringSubmatch(strings.TrimRight(p.words[i].text, ",.;"))
+	if m == nil {
+		return 0
+	}
+	p.res.Labels = append(p.res.Labels, m[1])
+	p.record(PartLabel, i, 1)
+	return 1
+}
+
+var priorities = map[string]models.Priority{
+	"!low": models.PriorityLow, "!p4": models.PriorityLow, "!4": models.PriorityLow,
+	"!medium": models.PriorityMedium, "!med": models.PriorityMedium, "!p3": models.PriorityMedium, "!3": models.PriorityMedium,
+	"!high": models.PriorityHigh, "!p2": models.PriorityHigh, "!2": models.PriorityHigh,
+	"!urgent": models.PriorityUrgent, "!p1": models.PriorityUrgent, "!1": models.PriorityUrgent,
+}
+
+func (p *parser) priority(i int) int {
+	prio, ok := priorities[p.lower(i)]
+	if !ok {
+		return 0
+	}
+	p.res.Priority = prio
+	p.record(PartPriority, i, 1)
+	return 1
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var units = map[string]Frequency{
+	"day": Daily, "days": Daily,
+	"week": Weekly, "weeks": Weekly,
+	"month": Monthly, "months": Monthly,
+	"year": Yearly, "years": Yearly,
+}
+
+var ordinalPattern = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)?$`)
+
+// ordinal reads "1st", "22nd" or a bare "15" as a day of the month
+func ordinal(s string) (int, bool) {
+	m := ordinalPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	d, _ := strconv.Atoi(m[1])
+	return d, d >= 1 && d <= 31
+}
+
+// weekdayList reads "monday", "mon, wed and fri" and similar
+func (p *parser) weekdayList(i int) ([]time.Weekday, int) {
+	var days []time.Weekday
+	n := 0
+	for {
+		d, ok := weekdays[p.lower(i+n)]
+		if !ok {
+			break
+		}
+		days = append(days, d)
+		n++
+		sep := p.lower(i + n)
+		if sep != "and" && sep != "&" {
+			if !strings.HasSuffix(p.words[i+n-1].text, ",") {
+				break
+			}
+			continue
+		}
+		if _, ok := weekdays[p.lower(i+n+1)]; !ok {
+			break
+		}
+		n++
+	}
+	return days, n
+}
+
+func (p *parser) recurrence(i int) int {
+	if p.res.Recurrence != nil {
+		return 0
+	}
+
+	r := Recurrence{Interval: 1}
+	n := 0
+	switch w := p.lower(i); w {
+	case "daily":
+		r.Freq, n = Daily, 1
+	case "weekly":
+		r.Freq, n = Weekly, 1
+	case "monthly":
+		r.Freq, n = Monthly, 1
+	case "yearly", "annually":
+		r.Freq, n = Yearly, 1
+	case "every":
+		next := p.lower(i + 1)
+		if next == "other" {
+			r.Interval = 2
+			next = p.lower(i + 2)
+			n = 1
+		} else if k, err := strconv.Atoi(next); err == nil && k > 0 {
+			r.Interval = k
+			next = p.lower(i + 2)
+			n = 1
+		}
+
+		if f, ok := units[next]; ok {
+			r.Freq, n = f, n+2
+		} else if next == "weekday" && r.Interval == 1 {
+			r.Freq, r.ByDay, n = Weekly, []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, 2
+		} else if days, k := p.weekdayList(i + 1 + n); k > 0 && n == 0 {
+			r.Freq, r.ByDay, n = Weekly, days, 1+k
+		} else {
+			return 0
+		}
+	default:
+		return 0
+	}
+
+	// "every week on monday", "every month on the 1st"
+	if p.lower(i+n) == "on" {
+		switch r.Freq {
+		case Weekly:
+			if days, k := p.weekdayList(i + n + 1); k > 0 && len(r.ByDay) == 0 {
+				r.ByDay = days
+				n += 1 + k
+			}
+		case Monthly:
+			k := 1
+			if p.lower(i+n+k) == "the" {
+				k++
+			}
+			if d, ok := ordinal(p.lower(i + n + k)); ok {
+				r.ByMonthDay = d
+				n += k + 1
+			}
+		}
+	}
+
+	p.res.Recurrence = &r
+	p.record(PartRecurrence, i, n)
+	return n
+}
+
+var isoDatePattern = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+
+// date matches a day, optionally preceded by "on", "by" or "due"
+func (p *parser) date(i int) int {
+	if p.kind != noDate || p.exact != nil {
+		return 0
+	}
+
+	lead := 0
+	switch p.lower(i) {
+	case "on", "by", "due":
+		lead = 1
+	}
+
+	if n := p.dateAt(i + lead); n > 0 {
+		p.record(PartDue, i, lead+n)
+		return lead + n
+	}
+	return 0
+}
+
+func (p *parser) dateAt(i int) int {
+	w := p.lower(i)
+	if w == "" {
+		return 0
+	}
+	today := p.now
+
+	switch w {
+	case "today":
+		p.setDate(fixedDate, today)
+		return 1
+	case "tonight":
+		p.setDate(fixedDate, today)
+		p.defaultAt = 20
+		return 1
+	case "tomorrow", "tmr", "tmrw":
+		p.setDate(fixedDate, today.AddDate(0, 0, 1))
+		return 1
+	case "next":
+		switch next := p.lower(i + 1); next {
+		case "week":
+			p.setDate(fixedDate, nextWeekday(today, time.Monday))
+			return 2
+		case "month":
+			first := time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location())
+			p.setDate(fixedDate, first)
+			return 2
+		case "year":
+			p.setDate(fixedDate, time.Date(today.Year()+1, 1, 1, 0, 0, 0, 0, today.Location()))
+			return 2
+		default:
+			if d, ok := weekdays[next]; ok {
+				p.setDate(fixedDate, nextWeekday(today, d))
+				return 2
+			}
+		}
+		return 0
+	case "in":
+		return p.relative(i)
+	case "the":
+		if d, ok := ordinal(p.lower(i + 1)); ok && p.lower(i+1) != strconv.Itoa(d) {
+			p.kind, p.day = monthDayDate, d
+			return 2
+		}
+		return 0
+	}
+
+	if d, ok := weekdays[w]; ok {
+		p.kind = weekdayDate
+		p.setYMD(nextWeekdayOrToday(today, d))
+		return 1
+	}
+
+	if m := isoDatePattern.FindStringSubmatch(w); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		t := time.Date(y, time.Month(mo), d, 0, 0, 0, 0, today.Location())
+		if t.Month() != time.Month(mo) {
+			return 0
+		}
+		p.setDate(fixedDate, t)
+		return 1
+	}
+
+	// "march 5", "mar 5th 2027"
+	if mo, ok := months[w]; ok {
+		if d, ok := ordinal(p.lower(i + 1)); ok {
+			if n, ok := p.monthDay(mo, d, i+2); ok {
+				return 2 + n
+			}
+		}
+		return 0
+	}
+
+	// "5 march", "5th of march"; a bare number only counts when a month follows
+	if d, ok := ordinal(w); ok {
+		k := 1
+		if p.lower(i+k) == "of" {
+			k++
+		}
+		if mo, ok := months[p.lower(i+k)]; ok {
+			if n, ok := p.monthDay(mo, d, i+k+1); ok {
+				return k + 1 + n
+			}
+			return 0
+		}
+		if w != strconv.Itoa(d) {
+			// an ordinal on its own, "on the 1st" without "the"
+			if i > 0 && p.lower(i-1) == "on" {
+				p.kind, p.day = monthDayDate, d
+				return 1
+			}
+		}
+	}
+
+	return 0
+}
+
+// monthDay records a month and day and returns 1 if a year followed at yearAt. It fails for
+// days the month does not have in that year, or in any year when none is given, so "feb 30"
+// stays in the title instead of becoming March 2.
+func (p *parser) monthDay(mo time.Month, d, yearAt int) (int, bool) {
+	if y, err := strconv.Atoi(p.lower(yearAt)); err == nil && y >= 1970 && y < 3000 {
+		if d > daysIn(mo, y) {
+			return 0, false
+		}
+		p.kind, p.year, p.month, p.day = fixedDate, y, mo, d
+		return 1, true
+	}
+	// 2000 was a leap year, so February may have 29 days
+	if d > daysIn(mo, 2000) {
+		return 0, false
+	}
+	p.kind, p.year, p.month, p.day = yearlyDate, p.now.Year(), mo, d
+	return 0, true
+}
+
+func daysIn(mo time.Month, year int) int {
+	return time.Date(year, mo+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func (p *parser) relative(i int) int {
+	k := 1
+	amount := 0
+	switch w := p.lower(i + k); w {
+	case "a", "an", "one":
+		amount = 1
+	default:
+		n, err := strconv.Atoi(w)
+		if err != nil || n <= 0 {
+			return 0
+		}
+		amount = n
+	}
+	k++
+
+	switch p.lower(i + k) {
+	case "minute", "minutes", "min", "mins":
+		t := p.now.Add(time.Duration(amount) * time.Minute)
+		p.exact = &t
+	case "hour", "hours", "hr", "hrs":
+		t := p.now.Add(time.Duration(amount) * time.Hour)
+		p.exact = &t
+	case "day", "days":
+		p.setDate(fixedDate, p.now.AddDate(0, 0, amount))
+	case "week", "weeks":
+		p.setDate(fixedDate, p.now.AddDate(0, 0, 7*amount))
+	case "month", "months":
+		p.setDate(fixedDate, p.now.AddDate(0, amount, 0))
+	case "year", "years":
+		p.setDate(fixedDate, p.now.AddDate(amount, 0, 0))
+	default:
+		return 0
+	}
+	return k + 1
+}
+
+func (p *parser) setDate(kind dateKind, t time.Time) {
+	p.kind = kind
+	p.setYMD(t)
+}
+
+func (p *parser) setYMD(t time.Time) {
+	p.year, p.month, p.day = t.Year(), t.Month(), t.Day()
+}
+
+func nextWeekday(from time.Time, d time.Weekday) time.Time {
+	days := (int(d) - int(from.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return from.AddDate(0, 0, days)
+}
+
+func nextWeekdayOrToday(from time.Time, d time.Weekday) time.Time {
+	return from.AddDate(0, 0, (int(d)-int(from.Weekday())+7)%7)
+}
+
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm|a\.m\.?|p\.m\.?)?$`)
+
+// clock matches a time of day, optionally preceded by "at" or "@"
+func (p *parser) clock(i int) int {
+	if p.hasTime || p.exact != nil {
+		return 0
+	}
+
+	lead := 0
+	if w := p.lower(i); w == "at" || w == "@" {
+		lead = 1
+	}
+
+	w := p.lower(i + lead)
+	n := 1
+	switch w {
+	case "noon", "midday":
+		p.setClock(12, 0)
+	case "midnight":
+		p.setClock(0, 0)
+	default:
+		// "9 am" is two words
+		if next := p.lower(i + lead + 1); next == "am" || next == "pm" {
+			w += next
+			n = 2
+		}
+		m := clockPattern.FindStringSubmatch(w)
+		if m == nil {
+			return 0
+		}
+		h, _ := strconv.Atoi(m[1])
+		min := 0
+		if m[2] != "" {
+			min, _ = strconv.Atoi(m[2])
+		}
+		suffix := strings.ReplaceAll(m[3], ".", "")
+		switch {
+		case suffix == "" && m[2] == "" && lead == 0:
+			// a bare number is not a time
+			return 0
+		case suffix == "" && (h > 23 || min > 59):
+			return 0
+		case suffix != "" && (h < 1 || h > 12 || min > 59):
+			return 0
+		case suffix == "am" && h == 12:
+			h = 0
+		case suffix == "pm" && h != 12:
+			h += 12
+		}
+		p.setClock(h, min)
+	}
+
+	p.record(PartTime, i, lead+n)
+	return lead + n
+}
+
+func (p *parser) setClock(h, m int) {
+	p.hour, p.min, p.hasTime = h, m, true
+}
+
+// resolve turns the collected pieces into a due time, rolling dates that are already past forward
+func (p *parser) resolve() {
+	if p.exact != nil {
+		p.res.Due = p.exact
+		return
+	}
+
+	r := p.res.Recurrence
+	if r != nil && r.Freq == Monthly && r.ByMonthDay == 0 && p.kind == monthDayDate {
+		r.ByMonthDay, p.kind = p.day, noDate
+	}
+	if r != nil && r.Freq == Weekly && len(r.ByDay) == 0 && p.kind == weekdayDate {
+		r.ByDay, p.kind = []time.Weekday{time.Date(p.year, p.month, p.day, 0, 0, 0, 0, time.UTC).Weekday()}, noDate
+	}
+
+	hour, min := p.defaultAt, 0
+	if p.hasTime {
+		hour, min = p.hour, p.min
+	}
+	loc := p.now.Location()
+
+	var due time.Time
+	switch p.kind {
+	case noDate:
+		switch {
+		case r != nil:
+			due = r.first(p.now, hour, min)
+		case p.hasTime:
+			due = time.Date(p.now.Year(), p.now.Month(), p.now.Day(), hour, min, 0, 0, loc)
+			if due.Before(p.now) {
+				due = due.AddDate(0, 0, 1)
+			}
+		default:
+			return
+		}
+	case fixedDate:
+		due = time.Date(p.year, p.month, p.day, hour, min, 0, 0, loc)
+	case weekdayDate:
+		due = time.Date(p.year, p.month, p.day, hour, min, 0, 0, loc)
+		if due.Before(p.now) {
+			due = due.AddDate(0, 0, 7)
+		}
+	case monthDayDate:
+		due = Recurrence{Freq: Monthly, ByMonthDay: p.day}.first(p.now, hour, min)
+	case yearlyDate:
+		// the next year the date has not passed and exists, which for feb 29 may be years away
+		for y := p.year; ; y++ {
+			due = time.Date(y, p.month, p.day, hour, min, 0, 0, loc)
+			if due.Day() == p.day && !due.Before(p.now) {
+				break
+			}
+		}
+	}
+
+	p.res.Due = &due
+}
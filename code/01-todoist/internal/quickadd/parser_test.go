@@ -0,0 +1,117 @@
+package quickadd
+
+import (
+	"slices"
+	"testing"
+	"time"
+	"todoist/internal/models"
+)
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	// a Tuesday afternoon
+	now := time.Date(2026, 3, 10, 14, 0, 0, 0, berlin)
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, berlin)
+	}
+
+	tests := []struct {
+		input    string
+		title    string
+		due      time.Time
+		rule     string
+		labels   []string
+		priority models.Priority
+	}{
+		{
+			input: "Pay rent every month on the 1st at 9am #finance !high",
+			title: "Pay rent", due: at(time.April, 1, 9, 0), rule: "FREQ=MONTHLY;BYMONTHDAY=1",
+			labels: []string{"finance"}, priority: models.PriorityHigh,
+		},
+		{input: "Call mom tomorrow at 6:30pm", title: "Call mom", due: at(time.March, 11, 18, 30)},
+		{input: "Standup every weekday at 9:15", title: "Standup", due: at(time.March, 11, 9, 15), rule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{input: "Gym every mon, wed and fri at 7am #health", title: "Gym", due: at(time.March, 11, 7, 0), rule: "FREQ=WEEKLY;BYDAY=MO,WE,FR", labels: []string{"health"}},
+		{input: "Water plants every other day", title: "Water plants", due: at(time.March, 11, 9, 0), rule: "FREQ=DAILY;INTERVAL=2"},
+		{input: "Submit report friday !1", title: "Submit report", due: at(time.March, 13, 9, 0), priority: models.PriorityUrgent},
+		{input: "Review PR tuesday at 10am", title: "Review PR", due: at(time.March, 17, 10, 0)},
+		{input: "Dentist on March 5 at noon", title: "Dentist", due: time.Date(2027, 3, 5, 12, 0, 0, 0, berlin)},
+		{input: "Taxes due 2026-04-30", title: "Taxes", due: at(time.April, 30, 9, 0)},
+		{input: "Check oven in 20 minutes", title: "Check oven", due: now.Add(20 * time.Minute)},
+		{input: "Buy 2 apples at 9", title: "Buy 2 apples", due: at(time.March, 11, 9, 0)},
+		{input: "Meet Bob at the cafe", title: "Meet Bob at the cafe"},
+		{input: "Movie tonight", title: "Movie", due: at(time.March, 10, 20, 0)},
+		{input: "Feb 30 dentist", title: "Feb 30 dentist"},
+		{input: "Pay 31 Feb", title: "Pay 31 Feb"},
+		{input: "Feb 29 2027 x", title: "Feb 29 2027 x"},
+		{input: "April 31st of 2026 party", title: "April 31st of 2026 party"},
+		{input: "Leap party Feb 29 2028", title: "Leap party", due: time.Date(2028, 2, 29, 9, 0, 0, 0, berlin)},
+		{input: "Leap party on 29th of feb", title: "Leap party", due: time.Date(2028, 2, 29, 9, 0, 0, 0, berlin)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := Parse(tt.input, now, berlin)
+
+			if got.Title != tt.title {
+				t.Errorf("got title %q want %q", got.Title, tt.title)
+			}
+			switch {
+			case tt.due.IsZero() && got.Due != nil:
+				t.Errorf("got due %v want none", got.Due)
+			case !tt.due.IsZero() && (got.Due == nil || !got.Due.Equal(tt.due)):
+				t.Errorf("got due %v want %v", got.Due, tt.due)
+			}
+
+			rule := ""
+			if got.Recurrence != nil {
+				rule = got.Recurrence.Rule()
+			}
+			if rule != tt.rule {
+				t.Errorf("got rule %q want %q", rule, tt.rule)
+			}
+			if !slices.Equal(got.Labels, tt.labels) {
+				t.Errorf("got labels %v want %v", got.Labels, tt.labels)
+			}
+			if got.Priority != tt.priority {
+				t.Errorf("got priority %q want %q", got.Priority, tt.priority)
+			}
+		})
+	}
+}
+
+func TestParseParts(t *testing.T) {
+	input := "Pay rent every month on the 1st at 9am #finance !high"
+	got := Parse(input, time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC), time.UTC)
+
+	want := []Part{
+		{Kind: PartTitle, Text: "Pay"},
+		{Kind: PartTitle, Text: "rent"},
+		{Kind: PartRecurrence, Text: "every month on the 1st"},
+		{Kind: PartTime, Text: "at 9am"},
+		{Kind: PartLabel, Text: "#finance"},
+		{Kind: PartPriority, Text: "!high"},
+	}
+	if len(got.Parts) != len(want) {
+		t.Fatalf("got %+v want %+v", got.Parts, want)
+	}
+	for i, p := range got.Parts {
+		if p.Kind != want[i].Kind || p.Text != want[i].Text || input[p.Start:p.End] != p.Text {
+			t.Errorf("part %d: got %+v want %+v", i, p, want[i])
+		}
+	}
+}
+
+func TestParseUsesTimeZone(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	// 23:30 UTC is already the next morning in Tokyo
+	now := time.Date(2026, 3, 10, 23, 30, 0, 0, time.UTC)
+
+	got := Parse("Breakfast today at 8am", now, tokyo)
+	want := time.Date(2026, 3, 11, 8, 0, 0, 0, tokyo)
+	if got.Due == nil || !got.Due.Equal(want) {
+		t.Errorf("got %v want %v", got.Due, want)
+	}
+}
@@ -0,0 +1,69 @@
+package quickadd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// Recurrence is the subset of an RFC 5545 RRULE that quick-add can express
+type Recurrence struct {
+	Freq       Frequency      `json:"freq"`
+	Interval   int            `json:"interval,omitempty"`
+	ByDay      []time.Weekday `json:"byDay,omitempty"`
+	ByMonthDay int            `json:"byMonthDay,omitempty"`
+}
+
+var rruleDays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Rule renders the recurrence as an RRULE value, e.g. FREQ=WEEKLY;BYDAY=MO,FR
+func (r Recurrence) Rule() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = rruleDays[d]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.ByMonthDay > 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (r Recurrence) matches(day time.Time) bool {
+	switch {
+	case len(r.ByDay) > 0:
+		return slices.Contains(r.ByDay, day.Weekday())
+	case r.ByMonthDay > 0:
+		return day.Day() == r.ByMonthDay
+	}
+	return true
+}
+
+// first returns the earliest occurrence at hour:min that is not before now.
+// Months without the requested day are skipped, as RRULE does.
+func (r Recurrence) first(now time.Time, hour, min int) time.Time {
+	for offset := range 400 {
+		day := now.AddDate(0, 0, offset)
+		at := time.Date(day.Year(), day.Month(), day.Day(), hour, min, 0, 0, now.Location())
+		if r.matches(at) && !at.Before(now) {
+			return at
+		}
+	}
+	return now
+}
@@ -0,0 +1,130 @@
+// Package rank generates lexicographic position keys. A key is read as a base-62 fraction
+// between 0 and 1, so a new key can always be found between two others and reordering an
+// item only ever rewrites that item's key.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// digits are in ASCII order so that comparing keys as strings compares their values
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// MaxLength is the key length past which callers should Spread their keys again
+const MaxLength = 32
+
+var ErrInvalidRange = errors.New("rank: keys out of order or malformed")
+
+func digit(key string, i int) int {
+	if i >= len(key) {
+		return 0
+	}
+	return strings.IndexByte(digits, key[i])
+}
+
+// Valid reports whether key is non-empty, uses only rank digits and does not end in the zero digit,
+// which would leave no room below it
+func Valid(key string) bool {
+	if key == "" || key[len(key)-1] == digits[0] {
+		return false
+	}
+	for i := range len(key) {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Between returns a key strictly between a and b. An empty a means before everything and an
+// empty b after everything. Appending and prepending step by one digit instead of halving,
+// so keys at the ends of a list grow slowly.
+func Between(a, b string) (string, error) {
+	if (a != "" && !Valid(a)) || (b != "" && !Valid(b)) || (a != "" && b != "" && a >= b) {
+		return "", ErrInvalidRange
+	}
+
+	switch {
+	case b == "":
+		return after(a), nil
+	case a == "":
+		if key, ok := before(b); ok {
+			return key, nil
+		}
+	}
+	return midpoint(a, b), nil
+}
+
+func after(a string) string {
+	for i := 0; ; i++ {
+		if d := digit(a, i); d < base-1 {
+			return a[:min(i, len(a))] + strings.Repeat(digits[:1], max(0, i-len(a))) + string(digits[d+1])
+		}
+	}
+}
+
+func before(b string) (string, bool) {
+	for i := range len(b) {
+		if d := digit(b, i); d > 1 {
+			return b[:i] + string(digits[d-1]), true
+		}
+	}
+	return "", false
+}
+
+// midpoint follows the fractional indexing algorithm: skip the shared prefix, then take the
+// middle digit if there is room, otherwise descend one digit
+func midpoint(a, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(b) && digit(a, n) == digit(b, n) {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(a[min(n, len(a)):], b[n:])
+		}
+	}
+
+	da := digit(a, 0)
+	db := base
+	if b != "" {
+		db = digit(b, 0)
+	}
+
+	if db-da > 1 {
+		return string(digits[(da+db+1)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 0 {
+		rest = a[1:]
+	}
+	return string(digits[da]) + midpoint(rest, "")
+}
+
+// Spread returns n ascending keys spaced evenly across the whole range, all of the shortest
+// length that fits. It is how long keys are rebalanced.
+func Spread(n int) []string {
+	length, span := 1, base
+	for span <= n {
+		length++
+		span *= base
+	}
+
+	keys := make([]string, n)
+	buf := make([]byte, length)
+	for i := range n {
+		v := (i + 1) * span / (n + 1)
+		for j := length - 1; j >= 0; j-- {
+			buf[j] = digits[v%base]
+			v /= base
+		}
+		keys[i] = strings.TrimRight(string(buf), digits[:1])
+	}
+	return keys
+}
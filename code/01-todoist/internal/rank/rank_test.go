@@ -0,0 +1,90 @@
+package rank
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"", ""},
+		{"V", ""},
+		{"", "V"},
+		{"V", "W"},
+		{"V", "V1"},
+		{"", "01"},
+		{"z", ""},
+		{"zz", ""},
+		{"", "1"},
+		{"0V", "1"},
+		{"Az", "B"},
+	}
+	for _, tt := range tests {
+		got, err := Between(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("Between(%q, %q): %v", tt.a, tt.b, err)
+			continue
+		}
+		if !Valid(got) || (tt.a != "" && got <= tt.a) || (tt.b != "" && got >= tt.b) {
+			t.Errorf("Between(%q, %q) = %q, not strictly between", tt.a, tt.b, got)
+		}
+	}
+
+	for _, bad := range [][2]string{{"W", "V"}, {"V", "V"}, {"V0", ""}, {"a-b", ""}} {
+		if _, err := Between(bad[0], bad[1]); err != ErrInvalidRange {
+			t.Errorf("Between(%q, %q): got %v want %v", bad[0], bad[1], err, ErrInvalidRange)
+		}
+	}
+}
+
+// TestRandomInserts keeps inserting at random places and checks the keys stay sorted and unique
+func TestRandomInserts(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	var keys []string
+
+	for range 2000 {
+		i := r.IntN(len(keys) + 1)
+		a, b := "", ""
+		if i > 0 {
+			a = keys[i-1]
+		}
+		if i < len(keys) {
+			b = keys[i]
+		}
+		key, err := Between(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = slices.Insert(keys, i, key)
+	}
+
+	if !slices.IsSorted(keys) || len(slices.Compact(slices.Clone(keys))) != len(keys) {
+		t.Fatal("keys are not strictly ascending")
+	}
+}
+
+func TestAppendGrowsSlowly(t *testing.T) {
+	key := ""
+	for range 1000 {
+		next, _ := Between(key, "")
+		key = next
+	}
+	if len(key) > 20 {
+		t.Errorf("got length %d after 1000 appends", len(key))
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 61, 62, 5000} {
+		keys := Spread(n)
+		if len(keys) != n || !slices.IsSorted(keys) || len(slices.Compact(slices.Clone(keys))) != n {
+			t.Errorf("Spread(%d) is not %d strictly ascending keys", n, n)
+		}
+		for _, k := range keys {
+			if !Valid(k) {
+				t.Errorf("Spread(%d) produced invalid key %q", n, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package reminders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+	"todoist/internal/webhooks"
+)
+
+// Notifier delivers a reminder to its user
+type Notifier interface {
+	Notify(ctx context.Context, r Reminder) error
+}
+
+// LogNotifier writes reminders to a logger, which is handy in development
+type LogNotifier struct {
+	Logger *log.Logger
+}
+
+func (n LogNotifier) Notify(ctx context.Context, r Reminder) error {
+	logger := n.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf("reminder: todo %d %q for %s is due at %s", r.TodoID, r.Title, r.UserID, r.DueAt.Format(time.RFC3339))
+	return nil
+}
+
+// ReminderEvent is the X-Todoist-Event value of reminder deliveries
+const ReminderEvent = "ReminderDue"
+
+// WebhookNotifier POSTs reminders as JSON, signed the same way as todo webhooks
+type WebhookNotifier struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func (n WebhookNotifier) Notify(ctx context.Context, r Reminder) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhooks.EventHeader, ReminderEvent)
+	req.Header.Set(webhooks.TimestampHeader, timestamp)
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(n.Secret, timestamp, body))
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier mails reminders. Recipient maps a user to an address since accounts do not store one.
+type SMTPNotifier struct {
+	Addr      string
+	From      string
+	Auth      smtp.Auth
+	Recipient func(ctx context.Context, userID string) (string, error)
+}
+
+func (n SMTPNotifier) Notify(ctx context.Context, r Reminder) error {
+	if n.Recipient == nil {
+		return errors.New("smtp notifier has no recipient lookup")
+	}
+	to, err := n.Recipient(ctx, r.UserID)
+	if err != nil {
+		return err
+	}
+
+	// net/smtp has no context support, so the deadline is only honoured between sends
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(n.Addr, n.Auth, n.From, []string{to}, n.message(to, r))
+}
+
+func (n SMTPNotifier) message(to string, r Reminder) []byte {
+	// the title is user input, so line breaks are removed before it goes into a header
+	title := strings.NewReplacer("\r", " ", "\n", " ").Replace(r.Title)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", n.From)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: Reminder: %s\r\n", title)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&b, "%q is due at %s.\r\n", title, r.DueAt.Format(time.RFC1123))
+	return b.Bytes()
+}
+
+// Notifiers fans a reminder out to several notifiers and joins their errors
+type Notifiers []Notifier
+
+func (ns Notifiers) Notify(ctx context.Context, r Reminder) error {
+	var errs []error
+	for _, n := range ns {
+		if err := n.Notify(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,239 @@
+package reminders
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/models"
+)
+
+// Reminder is one pending notification for a todo's due date
+type Reminder struct {
+	TodoID int       `json:"todoId"`
+	UserID string    `json:"userid"`
+	Title  string    `json:"title"`
+	DueAt  time.Time `json:"dueAt"`
+	// At is when the reminder fires, DueAt minus the scheduler's lead time
+	At time.Time `json:"at"`
+}
+
+type Config struct {
+	// Lead is how long before the due date the reminder fires
+	Lead time.Duration
+	// Timeout bounds a single Notify call
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Lead:    15 * time.Minute,
+		Timeout: 10 * time.Second,
+	}
+}
+
+// Scheduler keeps one reminder per pending todo with a due date in a priority queue and
+// sleeps until the earliest one is due. It learns about todos from the event bus, so edits
+// move reminders and completions or deletions cancel them; the repository is never scanned.
+type Scheduler struct {
+	clock    Clock
+	notifier Notifier
+	cfg      Config
+
+	mu     sync.Mutex
+	queue  reminderQueue
+	byTodo map[int]*queued
+	// fired remembers the due date a todo was last reminded about so unrelated edits
+	// to an overdue todo do not remind again
+	fired  map[int]time.Time
+	signal chan struct{}
+}
+
+func NewScheduler(clock Clock, notifier Notifier, cfg Config) *Scheduler {
+	return &Scheduler{
+		clock:    clock,
+		notifier: notifier,
+		cfg:      cfg,
+		byTodo:   make(map[int]*queued),
+		fired:    make(map[int]time.Time),
+		signal:   make(chan struct{}, 1),
+	}
+}
+
+// HandleEvent is registered with events.Bus.Listen
+func (s *Scheduler) HandleEvent(e events.Event) {
+	switch e.Type {
+	case events.TodoCreated, events.TodoUpdated:
+		s.Upsert(e.Todo)
+	case events.TodoDeleted:
+		// a delete can be undone, so whether the reminder already fired is kept until the purge
+		s.dequeue(e.Todo.ID)
+	case events.TodoPurged:
+		s.Cancel(e.Todo.ID)
+	}
+}
+
+// Upsert schedules, moves or cancels the reminder for a todo according to its current state
+func (s *Scheduler) Upsert(t models.Todo) {
+	if t.DueAt == nil || t.Status != models.StatusPending {
+		s.Cancel(t.ID)
+		return
+	}
+
+	r := s.reminderFor(t)
+
+	s.mu.Lock()
+	if item, ok := s.byTodo[t.ID]; ok {
+		item.Reminder = r
+		heap.Fix(&s.queue, item.index)
+	} else if fired, ok := s.fired[t.ID]; !ok || !fired.Equal(r.DueAt) {
+		delete(s.fired, t.ID)
+		item := &queued{Reminder: r}
+		heap.Push(&s.queue, item)
+		s.byTodo[t.ID] = item
+	}
+	s.mu.Unlock()
+
+	s.wake()
+}
+
+func (s *Scheduler) reminderFor(t models.Todo) Reminder {
+	return Reminder{
+		TodoID: t.ID,
+		UserID: t.UserID,
+		Title:  t.Title,
+		DueAt:  *t.DueAt,
+		At:     t.DueAt.Add(-s.cfg.Lead),
+	}
+}
+
+// Rebuild replaces every reminder with those for todos, as after a backup is restored.
+// Reminders that were already due are taken as sent rather than sent again.
+func (s *Scheduler) Rebuild(todos []models.Todo) {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	s.queue = nil
+	s.byTodo = make(map[int]*queued)
+	s.fired = make(map[int]time.Time)
+	for _, t := range todos {
+		if t.DueAt == nil || t.Status != models.StatusPending {
+			continue
+		}
+		r := s.reminderFor(t)
+		if !r.At.After(now) {
+			s.fired[t.ID] = r.DueAt
+			continue
+		}
+		item := &queued{Reminder: r}
+		heap.Push(&s.queue, item)
+		s.byTodo[t.ID] = item
+	}
+	s.mu.Unlock()
+
+	s.wake()
+}
+
+func (s *Scheduler) Cancel(todoID int) {
+	s.mu.Lock()
+	s.removeLocked(todoID)
+	delete(s.fired, todoID)
+	s.mu.Unlock()
+
+	s.wake()
+}
+
+// dequeue drops a pending reminder but still remembers one that fired
+func (s *Scheduler) dequeue(todoID int) {
+	s.mu.Lock()
+	s.removeLocked(todoID)
+	s.mu.Unlock()
+
+	s.wake()
+}
+
+func (s *Scheduler) removeLocked(todoID int) {
+	if item, ok := s.byTodo[todoID]; ok {
+		heap.Remove(&s.queue, item.index)
+		delete(s.byTodo, todoID)
+	}
+}
+
+// Pending returns the queued reminders, earliest first
+func (s *Scheduler) Pending() []Reminder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := make([]Reminder, 0, len(s.queue))
+	for _, item := range s.queue {
+		pending = append(pending, item.Reminder)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].At.Before(pending[j].At) })
+	return pending
+}
+
+func (s *Scheduler) wake() {
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Run fires reminders until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		var timer Timer
+		var fire <-chan time.Time
+
+		s.mu.Lock()
+		if next, ok := s.queue.peek(); ok {
+			timer = s.clock.NewTimer(next.At.Sub(s.clock.Now()))
+			fire = timer.C()
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-s.signal:
+		case <-fire:
+			s.fireDue(ctx)
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (s *Scheduler) fireDue(ctx context.Context) {
+	now := s.clock.Now()
+
+	var due []Reminder
+	s.mu.Lock()
+	for {
+		next, ok := s.queue.peek()
+		if !ok || next.At.After(now) {
+			break
+		}
+		heap.Pop(&s.queue)
+		delete(s.byTodo, next.TodoID)
+		s.fired[next.TodoID] = next.DueAt
+		due = append(due, next.Reminder)
+	}
+	s.mu.Unlock()
+
+	for _, r := range due {
+		nctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+		if err := s.notifier.Notify(nctx, r); err != nil {
+			log.Printf("reminder for todo %d: %v", r.TodoID, err)
+		}
+		cancel()
+	}
+}
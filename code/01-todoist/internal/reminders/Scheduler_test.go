@@ -0,0 +1,302 @@
+package reminders
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/webhooks"
+)
+
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	c  chan time.Time
+	at time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	return true
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{c: make(chan time.Time, 1), at: c.now.Add(d)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves time forward and fires every timer that came due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- c.now
+	}
+	c.timers = pending
+}
+
+type recordingNotifier chan Reminder
+
+func (n recordingNotifier) Notify(ctx context.Context, r Reminder) error {
+	n <- r
+	return nil
+}
+
+func expectReminder(t *testing.T, got recordingNotifier, todoID int) {
+	t.Helper()
+	select {
+	case r := <-got:
+		if r.TodoID != todoID {
+			t.Errorf("got reminder for todo %d want %d", r.TodoID, todoID)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("no reminder for todo %d", todoID)
+	}
+}
+
+func expectNone(t *testing.T, got recordingNotifier) {
+	t.Helper()
+	select {
+	case r := <-got:
+		t.Errorf("got unexpected reminder %+v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func startScheduler(t *testing.T) (*Scheduler, *fakeClock, recordingNotifier) {
+	clock := newFakeClock()
+	got := make(recordingNotifier, 16)
+	s := NewScheduler(clock, got, Config{Lead: 15 * time.Minute, Timeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go s.Run(ctx)
+
+	return s, clock, got
+}
+
+func todoDue(id int, due time.Time) models.Todo {
+	return models.Todo{ID: id, UserID: "alice", Title: "pay rent", Status: models.StatusPending, DueAt: &due}
+}
+
+func TestSchedulerFiresInOrder(t *testing.T) {
+	s, clock, got := startScheduler(t)
+	now := clock.Now()
+
+	s.HandleEvent(events.Event{Type: events.TodoCreated, Todo: todoDue(2, now.Add(2*time.Hour))})
+	s.HandleEvent(events.Event{Type: events.TodoCreated, Todo: todoDue(1, now.Add(time.Hour))})
+
+	clock.Advance(30 * time.Minute)
+	expectNone(t, got)
+
+	clock.Advance(15 * time.Minute)
+	expectReminder(t, got, 1)
+	expectNone(t, got)
+
+	clock.Advance(time.Hour)
+	expectReminder(t, got, 2)
+
+	if pending := s.Pending(); len(pending) != 0 {
+		t.Errorf("got %d pending want 0", len(pending))
+	}
+}
+
+func TestSchedulerFollowsEdits(t *testing.T) {
+	s, clock, got := startScheduler(t)
+	now := clock.Now()
+
+	s.HandleEvent(events.Event{Type: events.TodoCreated, Todo: todoDue(1, now.Add(time.Hour))})
+	s.HandleEvent(events.Event{Type: events.TodoCreated, Todo: todoDue(2, now.Add(time.Hour))})
+	s.HandleEvent(events.Event{Type: events.TodoCreated, Todo: todoDue(3, now.Add(time.Hour))})
+
+	// postponed, completed and deleted
+	s.HandleEvent(events.Event{Type: events.TodoUpdated, Todo: todoDue(1, now.Add(3*time.Hour))})
+	done := todoDue(2, now.Add(time.Hour))
+	done.Status = models.StatusCompleted
+	s.HandleEvent(events.Event{Type: events.TodoUpdated, Todo: done})
+	s.HandleEvent(events.Event{Type: events.TodoDeleted, Todo: todoDue(3, now.Add(time.Hour))})
+
+	clock.Advance(time.Hour)
+	expectNone(t, got)
+
+	clock.Advance(2 * time.Hour)
+	expectReminder(t, got, 1)
+}
+
+func TestSchedulerDoesNotRepeatOverdueReminders(t *testing.T) {
+	s, clock, got := startScheduler(t)
+	now := clock.Now()
+
+	overdue := todoDue(1, now.Add(-time.Hour))
+	s.HandleEvent(events.Event{Type: events.TodoCreated, Todo: overdue})
+	expectReminder(t, got, 1)
+
+	overdue.Title = "pay rent today"
+	s.HandleEvent(events.Event{Type: events.TodoUpdated, Todo: overdue})
+	expectNone(t, got)
+
+	s.HandleEvent(events.Event{Type: events.TodoUpdated, Todo: todoDue(1, now.Add(time.Hour))})
+	clock.Advance(time.Hour)
+	expectReminder(t, got, 1)
+}
+
+func TestSchedulerRemembersFiredRemindersUntilPurge(t *testing.T) {
+	s, clock, got := startScheduler(t)
+	now := clock.Now()
+
+	overdue := todoDue(1, now.Add(-time.Hour))
+	s.HandleEvent(events.Event{Type: events.TodoCreated, Todo: overdue})
+	expectReminder(t, got, 1)
+
+	// deleted and brought back by undo
+	s.HandleEvent(events.Event{Type: events.TodoDeleted, Todo: overdue})
+	s.HandleEvent(events.Event{Type: events.TodoCreated, Todo: overdue})
+	expectNone(t, got)
+
+	s.HandleEvent(events.Event{Type: events.TodoDeleted, Todo: overdue})
+	s.HandleEvent(events.Event{Type: events.TodoPurged, Todo: overdue})
+	s.HandleEvent(events.Event{Type: events.TodoCreated, Todo: overdue})
+	expectReminder(t, got, 1)
+}
+
+// smtpStandIn speaks just enough SMTP for net/smtp.SendMail and hands back each message
+func smtpStandIn(t *testing.T) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	messages := make(chan string, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, messages)
+		}
+	}()
+	return ln.Addr().String(), messages
+}
+
+func serveSMTP(conn net.Conn, messages chan<- string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "DATA"):
+			reply("354 go ahead")
+			var msg strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				msg.WriteString(l)
+			}
+			messages <- msg.String()
+			reply("250 queued")
+		case strings.HasPrefix(cmd, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	addr, messages := smtpStandIn(t)
+	n := SMTPNotifier{
+		Addr: addr,
+		From: "reminders@todoist.local",
+		Recipient: func(ctx context.Context, userID string) (string, error) {
+			return userID + "@example.com", nil
+		},
+	}
+
+	due := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	if err := n.Notify(context.Background(), Reminder{TodoID: 1, UserID: "alice", Title: "pay\r\nBcc: evil@example.com", DueAt: due}); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := <-messages
+	if !strings.Contains(msg, "To: alice@example.com\r\n") || !strings.Contains(msg, "Subject: Reminder: pay  Bcc: evil@example.com\r\n") {
+		t.Errorf("got message %q", msg)
+	}
+	if strings.Contains(msg, "\r\nBcc:") {
+		t.Error("title injected a header")
+	}
+}
+
+func TestWebhookNotifierSigns(t *testing.T) {
+	var valid bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		valid = webhooks.Verify("s3cret", r.Header.Get(webhooks.TimestampHeader), body, r.Header.Get(webhooks.SignatureHeader)) &&
+			r.Header.Get(webhooks.EventHeader) == ReminderEvent
+	}))
+	defer srv.Close()
+
+	n := WebhookNotifier{URL: srv.URL, Secret: "s3cret"}
+	if err := n.Notify(context.Background(), Reminder{TodoID: 1, UserID: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if !valid {
+		t.Error("receiver could not verify the reminder")
+	}
+}
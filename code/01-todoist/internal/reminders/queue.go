@@ -0,0 +1,51 @@
+package reminders
+
+import "container/heap"
+
+// reminderQueue is a min-heap on At. Each item knows its index so a reminder can be
+// moved or removed in O(log n) when its todo is edited or deleted.
+type reminderQueue []*queued
+
+type queued struct {
+	Reminder
+	index int
+}
+
+func (q reminderQueue) Len() int {
+	return len(q)
+}
+
+func (q reminderQueue) Less(i, j int) bool {
+	return q[i].At.Before(q[j].At)
+}
+
+func (q reminderQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *reminderQueue) Push(x any) {
+	item := x.(*queued)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *reminderQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*q = old[:n-1]
+	return item
+}
+
+func (q reminderQueue) peek() (*queued, bool) {
+	if len(q) == 0 {
+		return nil, false
+	}
+	return q[0], true
+}
+
+var _ heap.Interface = (*reminderQueue)(nil)
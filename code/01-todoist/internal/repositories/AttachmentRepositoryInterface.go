@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+	"todoist/internal/models"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, a models.Attachment) (models.Attachment, error)
+	GetByID(ctx context.Context, id int) (models.Attachment, error)
+	ListByTodo(ctx context.Context, todoID int) ([]models.Attachment, error)
+	Delete(ctx context.Context, id int) error
+}
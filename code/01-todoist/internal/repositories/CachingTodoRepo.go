@@ -0,0 +1,318 @@
+package repositories
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"todoist/internal/models"
+)
+
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Collapsed uint64 `json:"collapsed"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+}
+
+type cacheEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+// flight is a load in progress that concurrent misses for the same key wait on
+type flight struct {
+	done  chan struct{}
+	value any
+	err   error
+	// stale is set under mu when a write invalidates the key while the load runs; its result is then not cached
+	stale bool
+}
+
+// CachingTodoRepo is a read-through cache in front of any TodoRepository.
+// GetByID and ListByUser results are kept in a bounded LRU with a TTL; writes go straight
+// to the wrapped repository and then invalidate the entries they affect.
+type CachingTodoRepo struct {
+	next     TodoRepository
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	flights map[string]*flight
+
+	hits, misses, collapsed, evictions atomic.Uint64
+}
+
+func NewCachingTodoRepo(next TodoRepository, capacity int, ttl time.Duration) *CachingTodoRepo {
+	return &CachingTodoRepo{
+		next:     next,
+		capacity: capacity,
+		ttl:      ttl,
+		now:      time.Now,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		flights:  make(map[string]*flight),
+	}
+}
+
+func idKey(id int) string {
+	return "id:" + strconv.Itoa(id)
+}
+
+func userKey(userID string) string {
+	return "user:" + userID
+}
+
+func (r *CachingTodoRepo) Stats() CacheStats {
+	r.mu.Lock()
+	entries := r.lru.Len()
+	r.mu.Unlock()
+
+	return CacheStats{
+		Hits:      r.hits.Load(),
+		Misses:    r.misses.Load(),
+		Collapsed: r.collapsed.Load(),
+		Evictions: r.evictions.Load(),
+		Entries:   entries,
+	}
+}
+
+// load returns the cached value for key or calls fetch, sharing one fetch between concurrent callers.
+// The fetch runs detached from any one caller's context, so a caller that gives up does not fail
+// the others; each caller stops waiting when its own context ends.
+func (r *CachingTodoRepo) load(ctx context.Context, key string, fetch func(context.Context) (any, error)) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+
+	if el, ok := r.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		if r.now().Before(entry.expires) {
+			r.lru.MoveToFront(el)
+			r.mu.Unlock()
+			r.hits.Add(1)
+			return entry.value, nil
+		}
+		r.removeLocked(el)
+	}
+
+	if f, ok := r.flights[key]; ok {
+		r.mu.Unlock()
+		r.collapsed.Add(1)
+		return f.wait(ctx)
+	}
+
+	f := &flight{done: make(chan struct{})}
+	r.flights[key] = f
+	r.mu.Unlock()
+	r.misses.Add(1)
+
+	go r.fill(context.WithoutCancel(ctx), key, f, fetch)
+	return f.wait(ctx)
+}
+
+func (r *CachingTodoRepo) fill(ctx context.Context, key string, f *flight, fetch func(context.Context) (any, error)) {
+	value, err := fetch(ctx)
+
+	r.mu.Lock()
+	f.value, f.err = value, err
+	if r.flights[key] == f {
+		delete(r.flights, key)
+	}
+	if err == nil && !f.stale {
+		r.storeLocked(key, value)
+	}
+	r.mu.Unlock()
+
+	close(f.done)
+}
+
+func (f *flight) wait(ctx context.Context) (any, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *CachingTodoRepo) storeLocked(key string, value any) {
+	if el, ok := r.entries[key]; ok {
+		r.removeLocked(el)
+	}
+
+	r.entries[key] = r.lru.PushFront(&cacheEntry{key: key, value: value, expires: r.now().Add(r.ttl)})
+
+	for r.lru.Len() > r.capacity {
+		r.removeLocked(r.lru.Back())
+		r.evictions.Add(1)
+	}
+}
+
+func (r *CachingTodoRepo) removeLocked(el *list.Element) {
+	r.lru.Remove(el)
+	delete(r.entries, el.Value.(*cacheEntry).key)
+}
+
+func (r *CachingTodoRepo) invalidate(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := r.entries[key]; ok {
+			r.removeLocked(el)
+		}
+		// later misses must not join a load that started before the write
+		if f, ok := r.flights[key]; ok {
+			f.stale = true
+			delete(r.flights, key)
+		}
+	}
+}
+
+// ownerOf finds which user's list a todo sits in before it is changed, preferring the cache
+func (r *CachingTodoRepo) ownerOf(ctx context.Context, id int) string {
+	r.mu.Lock()
+	if el, ok := r.entries[idKey(id)]; ok {
+		owner := el.Value.(*cacheEntry).value.(models.Todo).UserID
+		r.mu.Unlock()
+		return owner
+	}
+	r.mu.Unlock()
+
+	t, err := r.next.GetByID(ctx, id)
+	if err != nil {
+		return ""
+	}
+	return t.UserID
+}
+
+func (r *CachingTodoRepo) Create(ctx context.Context, t models.Todo) (models.Todo, error) {
+	created, err := r.next.Create(ctx, t)
+	if err != nil {
+		return models.Todo{}, err
+	}
+
+	r.invalidate(userKey(created.UserID), idKey(created.ID))
+	return created, nil
+}
+
+func (r *CachingTodoRepo) GetByID(ctx context.Context, id int) (models.Todo, error) {
+	v, err := r.load(ctx, idKey(id), func(ctx context.Context) (any, error) {
+		return r.next.GetByID(ctx, id)
+	})
+	if err != nil {
+		return models.Todo{}, err
+	}
+	return v.(models.Todo), nil
+}
+
+func (r *CachingTodoRepo) ListByUser(ctx context.Context, userID string) ([]models.Todo, error) {
+	v, err := r.load(ctx, userKey(userID), func(ctx context.Context) (any, error) {
+		return r.next.ListByUser(ctx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// callers may sort or filter in place, hand out a copy
+	cached := v.([]models.Todo)
+	todos := make([]models.Todo, len(cached))
+	copy(todos, cached)
+	return todos, nil
+}
+
+func (r *CachingTodoRepo) Update(ctx context.Context, t models.Todo) (models.Todo, error) {
+	previousOwner := r.ownerOf(ctx, t.ID)
+
+	updated, err := r.next.Update(ctx, t)
+	if err != nil {
+		return models.Todo{}, err
+	}
+
+	r.invalidate(idKey(updated.ID), userKey(updated.UserID), userKey(previousOwner))
+	return updated, nil
+}
+
+func (r *CachingTodoRepo) Delete(ctx context.Context, id int) error {
+	owner := r.ownerOf(ctx, id)
+
+	if err := r.next.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidate(idKey(id), userKey(owner))
+	return nil
+}
+
+// Restore forwards to the wrapped repository when it supports it
+func (r *CachingTodoRepo) Restore(ctx context.Context, t models.Todo) (models.Todo, error) {
+	restorer, ok := r.next.(Restorer)
+	if !ok {
+		return models.Todo{}, errors.ErrUnsupported
+	}
+
+	restored, err := restorer.Restore(ctx, t)
+	if err != nil {
+		return models.Todo{}, err
+	}
+
+	r.invalidate(idKey(restored.ID), userKey(restored.UserID))
+	return restored, nil
+}
+
+// Snapshot reads the wrapped repository directly so the copy never mixes in cached entries
+func (r *CachingTodoRepo) Snapshot(ctx context.Context) ([]models.Todo, error) {
+	if s, ok := r.next.(Snapshotter[models.Todo]); ok {
+		return s.Snapshot(ctx)
+	}
+	return nil, errors.ErrUnsupported
+}
+
+// ReplaceAll forwards to the wrapped repository and then empties the cache
+func (r *CachingTodoRepo) ReplaceAll(ctx context.Context, records []models.Todo) error {
+	s, ok := r.next.(Snapshotter[models.Todo])
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	if err := s.ReplaceAll(ctx, records); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lru.Init()
+	clear(r.entries)
+	for _, f := range r.flights {
+		f.stale = true
+	}
+	clear(r.flights)
+	return nil
+}
+
+// Ping forwards to the wrapped repository when it supports it
+func (r *CachingTodoRepo) Ping(ctx context.Context) error {
+	if p, ok := r.next.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ChangesSince forwards to the wrapped repository; the change log is never cached
+func (r *CachingTodoRepo) ChangesSince(ctx context.Context, userID string, since uint64) ([]Change, uint64, error) {
+	if l, ok := r.next.(ChangeLog); ok {
+		return l.ChangesSince(ctx, userID, since)
+	}
+	return nil, 0, errors.ErrUnsupported
+}
@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todoist/internal/models"
+)
+
+// countingRepo counts reads reaching the wrapped repository and can hold them until released
+type countingRepo struct {
+	TodoRepository
+	gets, lists atomic.Int32
+	gate        chan struct{}
+}
+
+func (c *countingRepo) GetByID(ctx context.Context, id int) (models.Todo, error) {
+	c.gets.Add(1)
+	if c.gate != nil {
+		<-c.gate
+	}
+	return c.TodoRepository.GetByID(ctx, id)
+}
+
+func (c *countingRepo) ListByUser(ctx context.Context, userID string) ([]models.Todo, error) {
+	c.lists.Add(1)
+	return c.TodoRepository.ListByUser(ctx, userID)
+}
+
+func newCachingFixture(capacity int, ttl time.Duration) (*CachingTodoRepo, *countingRepo) {
+	backend := &countingRepo{TodoRepository: NewInMemoryTodoRepo()}
+	return NewCachingTodoRepo(backend, capacity, ttl), backend
+}
+
+func TestCachingTodoRepoReads(t *testing.T) {
+	ctx := context.Background()
+	cache, backend := newCachingFixture(10, time.Minute)
+
+	todo, _ := cache.Create(ctx, models.Todo{UserID: "alice", Title: "a"})
+
+	for range 3 {
+		if _, err := cache.GetByID(ctx, todo.ID); err != nil {
+			t.Fatal(err)
+		}
+		cache.ListByUser(ctx, "alice")
+	}
+
+	if backend.gets.Load() != 1 || backend.lists.Load() != 1 {
+		t.Errorf("got %d gets, %d lists want 1 each", backend.gets.Load(), backend.lists.Load())
+	}
+	if s := cache.Stats(); s.Hits != 4 || s.Misses != 2 {
+		t.Errorf("got %+v want 4 hits, 2 misses", s)
+	}
+
+	if _, err := cache.GetByID(ctx, 999); err != ErrNotFound {
+		t.Errorf("got %v want %v", err, ErrNotFound)
+	}
+}
+
+func TestCachingTodoRepoInvalidation(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newCachingFixture(10, time.Minute)
+
+	todo, _ := cache.Create(ctx, models.Todo{UserID: "alice", Title: "before"})
+	cache.GetByID(ctx, todo.ID)
+	cache.ListByUser(ctx, "alice")
+	cache.ListByUser(ctx, "bob")
+
+	t.Run("update refreshes the todo and both owners' lists", func(t *testing.T) {
+		todo.Title = "after"
+		todo.UserID = "bob"
+		cache.Update(ctx, todo)
+
+		got, _ := cache.GetByID(ctx, todo.ID)
+		if got.Title != "after" {
+			t.Errorf("got %q want after", got.Title)
+		}
+		if alice, _ := cache.ListByUser(ctx, "alice"); len(alice) != 0 {
+			t.Errorf("got %d todos for alice want 0", len(alice))
+		}
+		if bob, _ := cache.ListByUser(ctx, "bob"); len(bob) != 1 {
+			t.Errorf("got %d todos for bob want 1", len(bob))
+		}
+	})
+
+	t.Run("create refreshes the owner's list", func(t *testing.T) {
+		cache.Create(ctx, models.Todo{UserID: "bob", Title: "second"})
+		if bob, _ := cache.ListByUser(ctx, "bob"); len(bob) != 2 {
+			t.Errorf("got %d todos for bob want 2", len(bob))
+		}
+	})
+
+	t.Run("delete drops the todo", func(t *testing.T) {
+		cache.Delete(ctx, todo.ID)
+		if _, err := cache.GetByID(ctx, todo.ID); err != ErrNotFound {
+			t.Errorf("got %v want %v", err, ErrNotFound)
+		}
+		if bob, _ := cache.ListByUser(ctx, "bob"); len(bob) != 1 {
+			t.Errorf("got %d todos for bob want 1", len(bob))
+		}
+	})
+}
+
+func TestCachingTodoRepoEviction(t *testing.T) {
+	ctx := context.Background()
+	cache, backend := newCachingFixture(2, time.Minute)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	a, _ := cache.Create(ctx, models.Todo{UserID: "u", Title: "a"})
+	b, _ := cache.Create(ctx, models.Todo{UserID: "u", Title: "b"})
+	c, _ := cache.Create(ctx, models.Todo{UserID: "u", Title: "c"})
+
+	cache.GetByID(ctx, a.ID)
+	cache.GetByID(ctx, b.ID)
+	cache.GetByID(ctx, a.ID) // a is now most recently used
+	cache.GetByID(ctx, c.ID) // evicts b
+
+	backend.gets.Store(0)
+	cache.GetByID(ctx, a.ID)
+	cache.GetByID(ctx, b.ID)
+	if got := backend.gets.Load(); got != 1 {
+		t.Errorf("got %d backend reads want 1 for the evicted entry", got)
+	}
+	if s := cache.Stats(); s.Evictions < 1 || s.Entries != 2 {
+		t.Errorf("got %+v want evictions and 2 entries", s)
+	}
+
+	t.Run("expired entries are reloaded", func(t *testing.T) {
+		backend.gets.Store(0)
+		now = now.Add(2 * time.Minute)
+		cache.GetByID(ctx, b.ID)
+		if got := backend.gets.Load(); got != 1 {
+			t.Errorf("got %d backend reads want 1", got)
+		}
+	})
+}
+
+func TestCachingTodoRepoSingleFlight(t *testing.T) {
+	ctx := context.Background()
+	cache, backend := newCachingFixture(10, time.Minute)
+	todo, _ := cache.Create(ctx, models.Todo{UserID: "alice", Title: "hot"})
+
+	backend.gate = make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := cache.GetByID(ctx, todo.ID); err != nil || got.Title != "hot" {
+				t.Errorf("got %+v, %v", got, err)
+			}
+		}()
+	}
+
+	// let the readers pile up behind the first load
+	for cache.Stats().Collapsed < 19 {
+		time.Sleep(time.Millisecond)
+	}
+	close(backend.gate)
+	wg.Wait()
+
+	if got := backend.gets.Load(); got != 1 {
+		t.Errorf("got %d backend reads want 1", got)
+	}
+}
+
+func TestCachingTodoRepoLeaderCancellation(t *testing.T) {
+	ctx := context.Background()
+	cache, backend := newCachingFixture(10, time.Minute)
+	todo, _ := cache.Create(ctx, models.Todo{UserID: "alice", Title: "hot"})
+
+	backend.gate = make(chan struct{})
+	leaderCtx, cancel := context.WithCancel(ctx)
+	leader := make(chan error)
+	go func() {
+		_, err := cache.GetByID(leaderCtx, todo.ID)
+		leader <- err
+	}()
+	for backend.gets.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	follower := make(chan error)
+	go func() {
+		got, err := cache.GetByID(ctx, todo.ID)
+		if err == nil && got.Title != "hot" {
+			t.Errorf("got %+v", got)
+		}
+		follower <- err
+	}()
+	for cache.Stats().Collapsed == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the leader's request goes away; the follower's must not fail with it
+	cancel()
+	if err := <-leader; err != context.Canceled {
+		t.Errorf("leader: got %v want %v", err, context.Canceled)
+	}
+	close(backend.gate)
+	if err := <-follower; err != nil {
+		t.Errorf("follower: got %v want the shared load to finish", err)
+	}
+
+	if _, err := cache.GetByID(ctx, todo.ID); err != nil || backend.gets.Load() != 1 {
+		t.Errorf("got %v after %d backend reads want the result cached", err, backend.gets.Load())
+	}
+}
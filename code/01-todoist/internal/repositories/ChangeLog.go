@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"todoist/internal/models"
+)
+
+type ChangeOp string
+
+const (
+	OpUpsert ChangeOp = "upsert"
+	OpDelete ChangeOp = "delete"
+)
+
+// Change is the latest write recorded for a todo. A delete keeps the last known
+// state of the todo as a tombstone so syncing clients learn about the removal.
+type Change struct {
+	Seq  uint64
+	Op   ChangeOp
+	Todo models.Todo
+	At   time.Time
+}
+
+// ChangeLog is implemented by repositories that can report what changed since a sequence number
+type ChangeLog interface {
+	// ChangesSince returns the user's changes with Seq greater than since, ordered by Seq,
+	// together with the current head sequence to use as the next starting point
+	ChangesSince(ctx context.Context, userID string, since uint64) ([]Change, uint64, error)
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+	"todoist/internal/models"
+)
+
+type CommentRepository interface {
+	Create(ctx context.Context, c models.Comment) (models.Comment, error)
+	GetByID(ctx context.Context, id int) (models.Comment, error)
+	// ListByTodo returns a todo's comments oldest first
+	ListByTodo(ctx context.Context, todoID int) ([]models.Comment, error)
+	Update(ctx context.Context, c models.Comment) (models.Comment, error)
+	// Delete removes the comment and every reply below it
+	Delete(ctx context.Context, id int) error
+}
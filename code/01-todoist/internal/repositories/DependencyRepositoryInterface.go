@@ -0,0 +1,17 @@
+package repositories
+
+import "context"
+
+// DependencyRepository stores blocked-by relations between todos. It does not look for
+// cycles; callers must serialise Add with their own check.
+type DependencyRepository interface {
+	// Add returns ErrConflict if the relation already exists
+	Add(ctx context.Context, todoID, blockerID int) error
+	Remove(ctx context.Context, todoID, blockerID int) error
+	// Blockers returns the IDs todoID waits for, in ascending order
+	Blockers(ctx context.Context, todoID int) ([]int, error)
+	// Dependents returns the IDs waiting for todoID, in ascending order
+	Dependents(ctx context.Context, todoID int) ([]int, error)
+	// RemoveTodo drops every relation the todo takes part in
+	RemoveTodo(ctx context.Context, todoID int) error
+}
@@ -0,0 +1,331 @@
+package repositories
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+	"todoist/internal/models"
+)
+
+// EventSourcedTodoRepo keeps the event store as the source of truth. Writes are turned into
+// events and appended; the current state and the per-user index are projections that
+// Rebuild can recreate from the store at any time.
+type EventSourcedTodoRepo struct {
+	store EventStore
+
+	// mu serialises commands so the projection always reflects a prefix of the log
+	mu      sync.RWMutex
+	todos   map[int]models.Todo
+	byUser  map[string]map[int]struct{}
+	deleted map[int]TodoEvent
+	lastSeq uint64
+	nextID  int
+}
+
+// NewEventSourcedTodoRepo builds the projections from whatever the store already holds
+func NewEventSourcedTodoRepo(ctx context.Context, store EventStore) (*EventSourcedTodoRepo, error) {
+	r := &EventSourcedTodoRepo{store: store}
+	if err := r.Rebuild(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Rebuild discards the projections and replays the whole event log
+func (r *EventSourcedTodoRepo) Rebuild(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events, err := r.store.Load(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	r.todos = make(map[int]models.Todo)
+	r.byUser = make(map[string]map[int]struct{})
+	r.deleted = make(map[int]TodoEvent)
+	r.lastSeq = 0
+	r.nextID = 1
+
+	for _, e := range events {
+		r.project(e)
+	}
+	return nil
+}
+
+// project applies one stored event to the projections; callers hold the write lock
+func (r *EventSourcedTodoRepo) project(e TodoEvent) {
+	current := r.todos[e.TodoID]
+	next, alive := e.Apply(current)
+
+	if !alive {
+		delete(r.todos, e.TodoID)
+		if ids := r.byUser[current.UserID]; ids != nil {
+			delete(ids, e.TodoID)
+		}
+		r.deleted[e.TodoID] = e
+	} else {
+		if current.UserID != "" && current.UserID != next.UserID {
+			delete(r.byUser[current.UserID], e.TodoID)
+		}
+		r.todos[e.TodoID] = next
+		delete(r.deleted, e.TodoID)
+		if r.byUser[next.UserID] == nil {
+			r.byUser[next.UserID] = make(map[int]struct{})
+		}
+		r.byUser[next.UserID][e.TodoID] = struct{}{}
+	}
+
+	r.lastSeq = e.Seq
+	if e.TodoID >= r.nextID {
+		r.nextID = e.TodoID + 1
+	}
+}
+
+func (r *EventSourcedTodoRepo) commit(ctx context.Context, events ...TodoEvent) error {
+	stored, err := r.store.Append(ctx, events...)
+	if err != nil {
+		return err
+	}
+	for _, e := range stored {
+		r.project(e)
+	}
+	return nil
+}
+
+func (r *EventSourcedTodoRepo) Create(ctx context.Context, t models.Todo) (models.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
+	t.ID = r.nextID
+	snapshot := t
+	event := TodoEvent{TodoID: t.ID, UserID: t.UserID, Type: TodoCreated, At: t.CreatedAt, Snapshot: &snapshot}
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	if err := r.commit(ctx, event); err != nil {
+		return models.Todo{}, err
+	}
+	return r.todos[t.ID], nil
+}
+
+// Restore appends a second TodoCreated to the todo's stream, so its history shows the gap
+func (r *EventSourcedTodoRepo) Restore(ctx context.Context, t models.Todo) (models.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
+	if _, ok := r.todos[t.ID]; ok || t.ID <= 0 || t.ID >= r.nextID {
+		return models.Todo{}, ErrConflict
+	}
+
+	snapshot := t
+	if err := r.commit(ctx, TodoEvent{TodoID: t.ID, UserID: t.UserID, Type: TodoCreated, At: time.Now(), Snapshot: &snapshot}); err != nil {
+		return models.Todo{}, err
+	}
+	return r.todos[t.ID], nil
+}
+
+func (r *EventSourcedTodoRepo) GetByID(ctx context.Context, id int) (models.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
+	t, ok := r.todos[id]
+	if !ok {
+		return models.Todo{}, ErrNotFound
+	}
+	return t, nil
+}
+
+func (r *EventSourcedTodoRepo) ListByUser(ctx context.Context, userID string) ([]models.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	todos := make([]models.Todo, 0, len(r.byUser[userID]))
+	for id := range r.byUser[userID] {
+		todos = append(todos, r.todos[id])
+	}
+	return todos, nil
+}
+
+// Update records one event per changed field, so the history says what changed rather than just that something did
+func (r *EventSourcedTodoRepo) Update(ctx context.Context, t models.Todo) (models.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
+	current, ok := r.todos[t.ID]
+	if !ok {
+		return models.Todo{}, ErrNotFound
+	}
+
+	at := t.UpdatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	base := TodoEvent{TodoID: t.ID, UserID: t.UserID, At: at}
+
+	// anything outside the fields with their own events goes into a snapshot
+	rest := t
+	rest.Title, rest.Description, rest.Status, rest.DueAt = current.Title, current.Description, current.Status, current.DueAt
+	rest.UpdatedAt = current.UpdatedAt
+
+	var events []TodoEvent
+	if !reflect.DeepEqual(rest, current) {
+		e := base
+		e.Type = TodoAmended
+		snapshot := t
+		snapshot.UpdatedAt = at
+		e.Snapshot = &snapshot
+		events = append(events, e)
+	} else {
+		if t.Title != current.Title {
+			e := base
+			e.Type, e.Title = TitleChanged, t.Title
+			events = append(events, e)
+		}
+		if t.Description != current.Description {
+			e := base
+			e.Type, e.Description = DescriptionChanged, t.Description
+			events = append(events, e)
+		}
+		if t.Status != current.Status {
+			e := base
+			e.Type, e.Status = StatusChanged, t.Status
+			events = append(events, e)
+		}
+		if !sameTime(t.DueAt, current.DueAt) {
+			e := base
+			e.Type, e.DueAt = DueChanged, t.DueAt
+			events = append(events, e)
+		}
+	}
+
+	if len(events) == 0 {
+		return current, nil
+	}
+	if err := r.commit(ctx, events...); err != nil {
+		return models.Todo{}, err
+	}
+	return r.todos[t.ID], nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func (r *EventSourcedTodoRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	current, ok := r.todos[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	snapshot := current
+	return r.commit(ctx, TodoEvent{TodoID: id, UserID: current.UserID, Type: TodoDeleted, At: time.Now(), Snapshot: &snapshot})
+}
+
+// GetAsOf replays a todo's own stream up to and including at
+func (r *EventSourcedTodoRepo) GetAsOf(ctx context.Context, id int, at time.Time) (models.Todo, error) {
+	events, err := r.store.LoadStream(ctx, id)
+	if err != nil {
+		return models.Todo{}, err
+	}
+
+	var t models.Todo
+	exists := false
+	for _, e := range events {
+		if e.At.After(at) {
+			break
+		}
+		t, exists = e.Apply(t)
+	}
+
+	if !exists {
+		return models.Todo{}, ErrNotFound
+	}
+	return t, nil
+}
+
+// History returns every event recorded for a todo, deleted or not
+func (r *EventSourcedTodoRepo) History(ctx context.Context, id int) ([]TodoEvent, error) {
+	events, err := r.store.LoadStream(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, ErrNotFound
+	}
+	return events, nil
+}
+
+// ChangesSince derives the sync change log from the events after since
+func (r *EventSourcedTodoRepo) ChangesSince(ctx context.Context, userID string, since uint64) ([]Change, uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events, err := r.store.Load(ctx, since)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	latest := make(map[int]TodoEvent)
+	for _, e := range events {
+		if e.Seq > r.lastSeq {
+			// appended by a command that has not been projected yet
+			break
+		}
+		latest[e.TodoID] = e
+	}
+
+	changes := make([]Change, 0)
+	for id, e := range latest {
+		if t, ok := r.todos[id]; ok && t.UserID == userID {
+			changes = append(changes, Change{Seq: e.Seq, Op: OpUpsert, Todo: t, At: e.At})
+			continue
+		}
+		if d, ok := r.deleted[id]; ok && d.UserID == userID && d.Seq == e.Seq {
+			changes = append(changes, Change{Seq: e.Seq, Op: OpDelete, Todo: *d.Snapshot, At: d.At})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Seq < changes[j].Seq })
+
+	return changes, r.lastSeq, nil
+}
+
+func (r *EventSourcedTodoRepo) Ping(ctx context.Context) error {
+	_, err := r.store.LoadStream(ctx, 0)
+	return err
+}
+
+var _ TodoHistoryReader = (*EventSourcedTodoRepo)(nil)
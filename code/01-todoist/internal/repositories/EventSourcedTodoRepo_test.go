@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todoist/internal/models"
+)
+
+func TestEventSourcedTodoRepo(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryEventStore()
+	repo, err := NewEventSourcedTodoRepo(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	todo, _ := repo.Create(ctx, models.Todo{UserID: "alice", Title: "draft", Status: models.StatusPending, CreatedAt: t0, UpdatedAt: t0})
+
+	todo.Title = "final"
+	todo.Status = models.StatusCompleted
+	todo.UpdatedAt = t0.Add(time.Hour)
+	if _, err := repo.Update(ctx, todo); err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := repo.Create(ctx, models.Todo{UserID: "alice", Title: "other", CreatedAt: t0, UpdatedAt: t0})
+	if err := repo.Delete(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("updates record one event per field", func(t *testing.T) {
+		history, _ := repo.History(ctx, todo.ID)
+		var types []TodoEventType
+		for _, e := range history {
+			types = append(types, e.Type)
+		}
+		want := []TodoEventType{TodoCreated, TitleChanged, StatusChanged}
+		if len(types) != len(want) {
+			t.Fatalf("got %v want %v", types, want)
+		}
+		for i := range want {
+			if types[i] != want[i] {
+				t.Errorf("got %v want %v", types, want)
+			}
+		}
+	})
+
+	t.Run("reads past states", func(t *testing.T) {
+		before, err := repo.GetAsOf(ctx, todo.ID, t0.Add(time.Minute))
+		if err != nil || before.Title != "draft" || before.Status != models.StatusPending {
+			t.Errorf("got %+v, %v want the draft", before, err)
+		}
+
+		after, _ := repo.GetAsOf(ctx, todo.ID, t0.Add(2*time.Hour))
+		if after.Title != "final" {
+			t.Errorf("got %q want final", after.Title)
+		}
+
+		if _, err := repo.GetAsOf(ctx, todo.ID, t0.Add(-time.Minute)); err != ErrNotFound {
+			t.Errorf("got %v want %v before creation", err, ErrNotFound)
+		}
+		if _, err := repo.GetAsOf(ctx, other.ID, time.Now().Add(time.Minute)); err != ErrNotFound {
+			t.Errorf("got %v want %v after deletion", err, ErrNotFound)
+		}
+	})
+
+	t.Run("rebuilt projections match", func(t *testing.T) {
+		rebuilt, err := NewEventSourcedTodoRepo(ctx, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, _ := rebuilt.GetByID(ctx, todo.ID)
+		want, _ := repo.GetByID(ctx, todo.ID)
+		if got.Title != want.Title || got.Status != want.Status || !got.UpdatedAt.Equal(want.UpdatedAt) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+
+		list, _ := rebuilt.ListByUser(ctx, "alice")
+		if len(list) != 1 {
+			t.Errorf("got %d todos want 1", len(list))
+		}
+
+		next, _ := rebuilt.Create(ctx, models.Todo{UserID: "bob", Title: "fresh"})
+		if next.ID != other.ID+1 {
+			t.Errorf("got id %d want %d, ids must not be reused", next.ID, other.ID+1)
+		}
+	})
+
+	t.Run("change log includes tombstones", func(t *testing.T) {
+		changes, head, err := repo.ChangesSince(ctx, "alice", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changes) != 2 || changes[0].Op != OpUpsert || changes[1].Op != OpDelete {
+			t.Errorf("got %+v want an upsert then a delete", changes)
+		}
+
+		none, _, _ := repo.ChangesSince(ctx, "alice", head)
+		if len(none) != 0 {
+			t.Errorf("got %d changes after head want 0", len(none))
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+	"todoist/internal/models"
+)
+
+type TodoEventType string
+
+const (
+	TodoCreated        TodoEventType = "TodoCreated"
+	TitleChanged       TodoEventType = "TitleChanged"
+	DescriptionChanged TodoEventType = "DescriptionChanged"
+	StatusChanged      TodoEventType = "StatusChanged"
+	DueChanged         TodoEventType = "DueChanged"
+	// TodoAmended carries a full snapshot for changes to fields without a dedicated event
+	TodoAmended TodoEventType = "TodoAmended"
+	TodoDeleted TodoEventType = "TodoDeleted"
+)
+
+// TodoEvent is one fact in a todo's history. Only the fields relevant to Type are set;
+// Created, Amended and Deleted events carry the whole todo in Snapshot.
+type TodoEvent struct {
+	Seq         uint64            `json:"seq"`
+	TodoID      int               `json:"todoId"`
+	UserID      string            `json:"userid"`
+	Type        TodoEventType     `json:"type"`
+	At          time.Time         `json:"at"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Status      models.TodoStatus `json:"status,omitempty"`
+	DueAt       *time.Time        `json:"dueAt,omitempty"`
+	Snapshot    *models.Todo      `json:"snapshot,omitempty"`
+}
+
+// Apply folds the event into the todo's state. It reports false once the todo is deleted.
+func (e TodoEvent) Apply(t models.Todo) (models.Todo, bool) {
+	switch e.Type {
+	case TodoCreated, TodoAmended:
+		return *e.Snapshot, true
+	case TitleChanged:
+		t.Title = e.Title
+	case DescriptionChanged:
+		t.Description = e.Description
+	case StatusChanged:
+		t.Status = e.Status
+	case DueChanged:
+		t.DueAt = e.DueAt
+	case TodoDeleted:
+		return t, false
+	}
+	t.UpdatedAt = e.At
+	return t, true
+}
+
+// EventStore is an append-only log of todo events
+type EventStore interface {
+	// Append assigns sequence numbers and stores the events as one atomic batch
+	Append(ctx context.Context, events ...TodoEvent) ([]TodoEvent, error)
+	// Load returns every event with a sequence number greater than since, in order
+	Load(ctx context.Context, since uint64) ([]TodoEvent, error)
+	// LoadStream returns the events of one todo, in order
+	LoadStream(ctx context.Context, todoID int) ([]TodoEvent, error)
+}
+
+// TodoHistoryReader is implemented by repositories that can answer questions about the past
+type TodoHistoryReader interface {
+	// GetAsOf returns the todo as it was at the given instant, or ErrNotFound if it did not exist then
+	GetAsOf(ctx context.Context, id int, at time.Time) (models.Todo, error)
+	History(ctx context.Context, id int) ([]TodoEvent, error)
+}
+
+type InMemoryEventStore struct {
+	log     []TodoEvent
+	streams map[int][]int
+	mu      sync.RWMutex
+}
+
+func NewInMemoryEventStore() *InMemoryEventStore {
+	return &InMemoryEventStore{
+		streams: make(map[int][]int),
+	}
+}
+
+func (s *InMemoryEventStore) Append(ctx context.Context, events ...TodoEvent) ([]TodoEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	appended := make([]TodoEvent, len(events))
+	for i, e := range events {
+		e.Seq = uint64(len(s.log) + 1)
+		s.streams[e.TodoID] = append(s.streams[e.TodoID], len(s.log))
+		s.log = append(s.log, e)
+		appended[i] = e
+	}
+	return appended, nil
+}
+
+func (s *InMemoryEventStore) Load(ctx context.Context, since uint64) ([]TodoEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if since >= uint64(len(s.log)) {
+		return []TodoEvent{}, nil
+	}
+
+	events := make([]TodoEvent, len(s.log)-int(since))
+	copy(events, s.log[since:])
+	return events, nil
+}
+
+func (s *InMemoryEventStore) LoadStream(ctx context.Context, todoID int) ([]TodoEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	events := make([]TodoEvent, 0, len(s.streams[todoID]))
+	for _, i := range s.streams[todoID] {
+		events = append(events, s.log[i])
+	}
+	return events, nil
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"todoist/internal/models"
+)
+
+type InMemoryAttachmentRepo struct {
+	data   map[int]models.Attachment
+	autoID int
+	mu     sync.RWMutex
+}
+
+func NewInMemoryAttachmentRepo() *InMemoryAttachmentRepo {
+	return &InMemoryAttachmentRepo{
+		data:   make(map[int]models.Attachment),
+		autoID: 1,
+	}
+}
+
+func (r *InMemoryAttachmentRepo) Create(ctx context.Context, a models.Attachment) (models.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Attachment{}, err
+	}
+
+	a.ID = r.autoID
+	r.autoID++
+	r.data[a.ID] = a
+	return a, nil
+}
+
+func (r *InMemoryAttachmentRepo) GetByID(ctx context.Context, id int) (models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Attachment{}, err
+	}
+
+	a, ok := r.data[id]
+	if !ok {
+		return models.Attachment{}, ErrNotFound
+	}
+	return a, nil
+}
+
+func (r *InMemoryAttachmentRepo) ListByTodo(ctx context.Context, todoID int) ([]models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	attachments := make([]models.Attachment, 0)
+	for _, a := range r.data {
+		if a.TodoID == todoID {
+			attachments = append(attachments, a)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].ID < attachments[j].ID })
+
+	return attachments, nil
+}
+
+func (r *InMemoryAttachmentRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.data[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.data, id)
+	return nil
+}
+
+func (r *InMemoryAttachmentRepo) Snapshot(ctx context.Context) ([]models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return snapshotMap(r.data), nil
+}
+
+func (r *InMemoryAttachmentRepo) ReplaceAll(ctx context.Context, records []models.Attachment) error {
+	data, err := indexRecords(records, func(a models.Attachment) int { return a.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.data = data
+	r.autoID = nextID(r.autoID, data)
+	return nil
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"todoist/internal/models"
+)
+
+type InMemoryCommentRepo struct {
+	data   map[int]models.Comment
+	autoID int
+	mu     sync.RWMutex
+}
+
+func NewInMemoryCommentRepo() *InMemoryCommentRepo {
+	return &InMemoryCommentRepo{
+		data:   make(map[int]models.Comment),
+		autoID: 1,
+	}
+}
+
+func (r *InMemoryCommentRepo) Create(ctx context.Context, c models.Comment) (models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Comment{}, err
+	}
+
+	c.ID = r.autoID
+	r.autoID++
+	r.data[c.ID] = c
+	return c, nil
+}
+
+func (r *InMemoryCommentRepo) GetByID(ctx context.Context, id int) (models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Comment{}, err
+	}
+
+	c, ok := r.data[id]
+	if !ok {
+		return models.Comment{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *InMemoryCommentRepo) ListByTodo(ctx context.Context, todoID int) ([]models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	comments := make([]models.Comment, 0)
+	for _, c := range r.data {
+		if c.TodoID == todoID {
+			comments = append(comments, c)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+
+	return comments, nil
+}
+
+func (r *InMemoryCommentRepo) Update(ctx context.Context, c models.Comment) (models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Comment{}, err
+	}
+
+	if _, ok := r.data[c.ID]; !ok {
+		return models.Comment{}, ErrNotFound
+	}
+	r.data[c.ID] = c
+	return c, nil
+}
+
+func (r *InMemoryCommentRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.data[id]; !ok {
+		return ErrNotFound
+	}
+
+	// replies always have higher IDs than their parent, so one pass in ID order finds the whole subtree
+	ids := make([]int, 0, len(r.data))
+	for cid := range r.data {
+		ids = append(ids, cid)
+	}
+	sort.Ints(ids)
+
+	doomed := map[int]bool{id: true}
+	for _, cid := range ids {
+		if p := r.data[cid].ParentID; p != nil && doomed[*p] {
+			doomed[cid] = true
+		}
+	}
+	for cid := range doomed {
+		delete(r.data, cid)
+	}
+	return nil
+}
+
+func (r *InMemoryCommentRepo) Snapshot(ctx context.Context) ([]models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return snapshotMap(r.data), nil
+}
+
+func (r *InMemoryCommentRepo) ReplaceAll(ctx context.Context, records []models.Comment) error {
+	data, err := indexRecords(records, func(c models.Comment) int { return c.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.data = data
+	r.autoID = nextID(r.autoID, data)
+	return nil
+}
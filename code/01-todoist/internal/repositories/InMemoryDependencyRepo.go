@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"todoist/internal/models"
+)
+
+// InMemoryDependencyRepo indexes relations in both directions
+type InMemoryDependencyRepo struct {
+	blockers   map[int]map[int]bool
+	dependents map[int]map[int]bool
+	mu         sync.RWMutex
+}
+
+func NewInMemoryDependencyRepo() *InMemoryDependencyRepo {
+	return &InMemoryDependencyRepo{
+		blockers:   make(map[int]map[int]bool),
+		dependents: make(map[int]map[int]bool),
+	}
+}
+
+func link(index map[int]map[int]bool, from, to int) {
+	if index[from] == nil {
+		index[from] = make(map[int]bool)
+	}
+	index[from][to] = true
+}
+
+func unlink(index map[int]map[int]bool, from, to int) {
+	delete(index[from], to)
+	if len(index[from]) == 0 {
+		delete(index, from)
+	}
+}
+
+func (r *InMemoryDependencyRepo) Add(ctx context.Context, todoID, blockerID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if r.blockers[todoID][blockerID] {
+		return ErrConflict
+	}
+	link(r.blockers, todoID, blockerID)
+	link(r.dependents, blockerID, todoID)
+	return nil
+}
+
+func (r *InMemoryDependencyRepo) Remove(ctx context.Context, todoID, blockerID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !r.blockers[todoID][blockerID] {
+		return ErrNotFound
+	}
+	unlink(r.blockers, todoID, blockerID)
+	unlink(r.dependents, blockerID, todoID)
+	return nil
+}
+
+func (r *InMemoryDependencyRepo) Blockers(ctx context.Context, todoID int) ([]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return sortedKeys(r.blockers[todoID]), nil
+}
+
+func (r *InMemoryDependencyRepo) Dependents(ctx context.Context, todoID int) ([]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return sortedKeys(r.dependents[todoID]), nil
+}
+
+func (r *InMemoryDependencyRepo) RemoveTodo(ctx context.Context, todoID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for blocker := range r.blockers[todoID] {
+		unlink(r.dependents, blocker, todoID)
+	}
+	for dependent := range r.dependents[todoID] {
+		unlink(r.blockers, dependent, todoID)
+	}
+	delete(r.blockers, todoID)
+	delete(r.dependents, todoID)
+	return nil
+}
+
+func (r *InMemoryDependencyRepo) Snapshot(ctx context.Context) ([]models.Dependency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	deps := make([]models.Dependency, 0)
+	for _, todoID := range sortedKeys(keySet(r.blockers)) {
+		for _, blockerID := range sortedKeys(r.blockers[todoID]) {
+			deps = append(deps, models.Dependency{TodoID: todoID, BlockerID: blockerID})
+		}
+	}
+	return deps, nil
+}
+
+func (r *InMemoryDependencyRepo) ReplaceAll(ctx context.Context, records []models.Dependency) error {
+	blockers := make(map[int]map[int]bool)
+	dependents := make(map[int]map[int]bool)
+	for _, d := range records {
+		if blockers[d.TodoID][d.BlockerID] {
+			return ErrConflict
+		}
+		link(blockers, d.TodoID, d.BlockerID)
+		link(dependents, d.BlockerID, d.TodoID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.blockers, r.dependents = blockers, dependents
+	return nil
+}
+
+func keySet[V any](m map[int]V) map[int]bool {
+	set := make(map[int]bool, len(m))
+	for k := range m {
+		set[k] = true
+	}
+	return set
+}
+
+func sortedKeys(set map[int]bool) []int {
+	keys := make([]int, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
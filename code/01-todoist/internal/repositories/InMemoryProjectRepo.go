@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"todoist/internal/models"
+)
+
+type InMemoryProjectRepo struct {
+	data   map[int]models.Project
+	autoID int
+	mu     sync.RWMutex
+}
+
+func NewInMemoryProjectRepo() *InMemoryProjectRepo {
+	return &InMemoryProjectRepo{
+		data:   make(map[int]models.Project),
+		autoID: 1,
+	}
+}
+
+func (r *InMemoryProjectRepo) Create(ctx context.Context, p models.Project) (models.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Project{}, err
+	}
+
+	p.ID = r.autoID
+	r.autoID++
+	r.data[p.ID] = p
+	return p, nil
+}
+
+func (r *InMemoryProjectRepo) GetByID(ctx context.Context, id int) (models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Project{}, err
+	}
+
+	p, ok := r.data[id]
+	if !ok {
+		return models.Project{}, ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *InMemoryProjectRepo) ListByUser(ctx context.Context, userID string) ([]models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	projects := make([]models.Project, 0)
+	for _, p := range r.data {
+		if p.UserID == userID {
+			projects = append(projects, p)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+
+	return projects, nil
+}
+
+func (r *InMemoryProjectRepo) Update(ctx context.Context, p models.Project) (models.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Project{}, err
+	}
+
+	if _, ok := r.data[p.ID]; !ok {
+		return models.Project{}, ErrNotFound
+	}
+	r.data[p.ID] = p
+	return p, nil
+}
+
+func (r *InMemoryProjectRepo) Snapshot(ctx context.Context) ([]models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return snapshotMap(r.data), nil
+}
+
+func (r *InMemoryProjectRepo) ReplaceAll(ctx context.Context, records []models.Project) error {
+	data, err := indexRecords(records, func(p models.Project) int { return p.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.data = data
+	r.autoID = nextID(r.autoID, data)
+	return nil
+}
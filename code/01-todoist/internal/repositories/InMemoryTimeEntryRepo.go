@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"todoist/internal/models"
+)
+
+type InMemoryTimeEntryRepo struct {
+	data   map[int]models.TimeEntry
+	autoID int
+	mu     sync.RWMutex
+}
+
+func NewInMemoryTimeEntryRepo() *InMemoryTimeEntryRepo {
+	return &InMemoryTimeEntryRepo{
+		data:   make(map[int]models.TimeEntry),
+		autoID: 1,
+	}
+}
+
+// overlapping reports whether e overlaps any other entry of its user; the caller holds the lock
+func (r *InMemoryTimeEntryRepo) overlapping(e models.TimeEntry) bool {
+	for _, other := range r.data {
+		if other.ID != e.ID && other.UserID == e.UserID && e.Overlaps(other) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InMemoryTimeEntryRepo) Create(ctx context.Context, e models.TimeEntry) (models.TimeEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	e.ID = 0
+	if r.overlapping(e) {
+		return models.TimeEntry{}, ErrConflict
+	}
+
+	e.ID = r.autoID
+	r.autoID++
+	r.data[e.ID] = e
+	return e, nil
+}
+
+func (r *InMemoryTimeEntryRepo) GetByID(ctx context.Context, id int) (models.TimeEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	e, ok := r.data[id]
+	if !ok {
+		return models.TimeEntry{}, ErrNotFound
+	}
+	return e, nil
+}
+
+func (r *InMemoryTimeEntryRepo) Update(ctx context.Context, e models.TimeEntry) (models.TimeEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	if _, ok := r.data[e.ID]; !ok {
+		return models.TimeEntry{}, ErrNotFound
+	}
+	if r.overlapping(e) {
+		return models.TimeEntry{}, ErrConflict
+	}
+	r.data[e.ID] = e
+	return e, nil
+}
+
+func (r *InMemoryTimeEntryRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := r.data[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.data, id)
+	return nil
+}
+
+func (r *InMemoryTimeEntryRepo) ListByTodo(ctx context.Context, todoID int) ([]models.TimeEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.TimeEntry, 0)
+	for _, e := range r.data {
+		if e.TodoID == todoID {
+			entries = append(entries, e)
+		}
+	}
+	sortByStart(entries)
+
+	return entries, nil
+}
+
+func (r *InMemoryTimeEntryRepo) ListByUser(ctx context.Context, userID string, from, to time.Time) ([]models.TimeEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	window := models.TimeEntry{Start: from, End: &to}
+	entries := make([]models.TimeEntry, 0)
+	for _, e := range r.data {
+		if e.UserID == userID && e.Overlaps(window) {
+			entries = append(entries, e)
+		}
+	}
+	sortByStart(entries)
+
+	return entries, nil
+}
+
+func (r *InMemoryTimeEntryRepo) Running(ctx context.Context, userID string) (models.TimeEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	for _, e := range r.data {
+		if e.UserID == userID && e.Running() {
+			return e, nil
+		}
+	}
+	return models.TimeEntry{}, ErrNotFound
+}
+
+func sortByStart(entries []models.TimeEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Start.Equal(entries[j].Start) {
+			return entries[i].Start.Before(entries[j].Start)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+}
+
+func (r *InMemoryTimeEntryRepo) Snapshot(ctx context.Context) ([]models.TimeEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return snapshotMap(r.data), nil
+}
+
+func (r *InMemoryTimeEntryRepo) ReplaceAll(ctx context.Context, records []models.TimeEntry) error {
+	data, err := indexRecords(records, func(e models.TimeEntry) int { return e.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.data = data
+	r.autoID = nextID(r.autoID, data)
+	return nil
+}
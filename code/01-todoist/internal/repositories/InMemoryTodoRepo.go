@@ -2,31 +2,69 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"sync"
+	"time"
 	"todoist/internal/models"
 )
 
+// scanCheckInterval is how many records a scan visits between context checks
+const scanCheckInterval = 1024
+
 type InMemoryTodoRepo struct {
 	data   map[int]models.Todo
 	autoID int
 	mu     sync.RWMutex
+
+	// changes holds the latest change per todo ID, including tombstones for deleted todos
+	changes map[int]Change
+	seq     uint64
 }
 
 func NewInMemoryTodoRepo() *InMemoryTodoRepo {
 	return &InMemoryTodoRepo{
-		data:   make(map[int]models.Todo),
-		autoID: 1,
+		data:    make(map[int]models.Todo),
+		autoID:  1,
+		changes: make(map[int]Change),
 	}
 }
 
+// record must be called with the write lock held
+func (r *InMemoryTodoRepo) record(op ChangeOp, t models.Todo) {
+	r.seq++
+	r.changes[t.ID] = Change{Seq: r.seq, Op: op, Todo: t, At: time.Now()}
+}
+
 // Create(ctx context.Context, t models.Todo) (models.Todo, error)
 func (r *InMemoryTodoRepo) Create(ctx context.Context, t models.Todo) (models.Todo, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
 	t.ID = r.autoID
 	r.autoID++
 	r.data[t.ID] = t
+	r.record(OpUpsert, t)
+	return t, nil
+}
+
+func (r *InMemoryTodoRepo) Restore(ctx context.Context, t models.Todo) (models.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
+	if _, ok := r.data[t.ID]; ok || t.ID <= 0 || t.ID >= r.autoID {
+		return models.Todo{}, ErrConflict
+	}
+	r.data[t.ID] = t
+	r.record(OpUpsert, t)
 	return t, nil
 }
 
@@ -35,6 +73,10 @@ func (r *InMemoryTodoRepo) GetByID(ctx context.Context, id int) (models.Todo, er
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
 	t, ok := r.data[id]
 	if !ok {
 		return models.Todo{}, ErrNotFound
@@ -48,8 +90,19 @@ func (r *InMemoryTodoRepo) ListByUser(ctx context.Context, userID string) ([]mod
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	todos := make([]models.Todo, 0, len(r.data))
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	todos := make([]models.Todo, 0)
+	i := 0
 	for _, t := range r.data {
+		// a full scan can be long, give up as soon as the caller does
+		if i++; i%scanCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		if t.UserID == userID {
 			todos = append(todos, t)
 		}
@@ -63,12 +116,17 @@ func (r *InMemoryTodoRepo) Update(ctx context.Context, t models.Todo) (models.To
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
 	id := t.ID
 	if _, ok := r.data[id]; !ok {
 		return models.Todo{}, ErrNotFound
 	}
 
 	r.data[id] = t
+	r.record(OpUpsert, t)
 
 	return t, nil
 }
@@ -78,9 +136,94 @@ func (r *InMemoryTodoRepo) Delete(ctx context.Context, id int) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.data[id]; !ok {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t, ok := r.data[id]
+	if !ok {
 		return ErrNotFound
 	}
 	delete(r.data, id)
+	r.record(OpDelete, t)
+	return nil
+}
+
+// Ping(ctx context.Context) error
+func (r *InMemoryTodoRepo) Ping(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if r.data == nil {
+		return errors.New("repository not initialised")
+	}
+	return nil
+}
+
+// ChangesSince(ctx context.Context, userID string, since uint64) ([]Change, uint64, error)
+func (r *InMemoryTodoRepo) ChangesSince(ctx context.Context, userID string, since uint64) ([]Change, uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	changes := make([]Change, 0)
+	i := 0
+	for _, c := range r.changes {
+		if i++; i%scanCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, 0, err
+			}
+		}
+		if c.Seq > since && c.Todo.UserID == userID {
+			changes = append(changes, c)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Seq < changes[j].Seq })
+
+	return changes, r.seq, nil
+}
+
+func (r *InMemoryTodoRepo) Snapshot(ctx context.Context) ([]models.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return snapshotMap(r.data), nil
+}
+
+// ReplaceAll records the swap in the change log, as deletes of todos that are gone and
+// upserts of everything restored, so syncing clients catch up like after any other write
+func (r *InMemoryTodoRepo) ReplaceAll(ctx context.Context, records []models.Todo) error {
+	data, err := indexRecords(records, func(t models.Todo) int { return t.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for id, t := range r.data {
+		if _, ok := data[id]; !ok {
+			r.record(OpDelete, t)
+		}
+	}
+	r.data = data
+	r.autoID = nextID(r.autoID, data)
+	for _, t := range snapshotMap(data) {
+		r.record(OpUpsert, t)
+	}
 	return nil
 }
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todoist/internal/models"
+)
+
+// cancelAfter reports no error for the first n calls to Err and context.Canceled afterwards,
+// which lets a test cancel in the middle of a scan
+type cancelAfter struct {
+	context.Context
+	n     int32
+	calls atomic.Int32
+}
+
+func (c *cancelAfter) Err() error {
+	if c.calls.Add(1) > c.n {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestInMemoryTodoRepoHonoursCancellation(t *testing.T) {
+	repo := NewInMemoryTodoRepo()
+	todo, _ := repo.Create(context.Background(), models.Todo{UserID: "alice", Title: "a"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := map[string]func() error{
+		"Create": func() error {
+			_, err := repo.Create(ctx, models.Todo{UserID: "alice"})
+			return err
+		},
+		"GetByID": func() error {
+			_, err := repo.GetByID(ctx, todo.ID)
+			return err
+		},
+		"ListByUser": func() error {
+			_, err := repo.ListByUser(ctx, "alice")
+			return err
+		},
+		"Update": func() error {
+			_, err := repo.Update(ctx, todo)
+			return err
+		},
+		"Delete": func() error {
+			return repo.Delete(ctx, todo.ID)
+		},
+		"ChangesSince": func() error {
+			_, _, err := repo.ChangesSince(ctx, "alice", 0)
+			return err
+		},
+		"Ping": func() error {
+			return repo.Ping(ctx)
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			if err := call(); !errors.Is(err, context.Canceled) {
+				t.Errorf("got %v want %v", err, context.Canceled)
+			}
+		})
+	}
+
+	if _, err := repo.GetByID(context.Background(), todo.ID); err != nil {
+		t.Errorf("cancelled writes changed the repository: %v", err)
+	}
+}
+
+func TestInMemoryTodoRepoListStopsMidScan(t *testing.T) {
+	repo := NewInMemoryTodoRepo()
+	for range 5 * scanCheckInterval {
+		repo.Create(context.Background(), models.Todo{UserID: "alice"})
+	}
+
+	ctx := &cancelAfter{Context: context.Background(), n: 2}
+	if _, err := repo.ListByUser(ctx, "alice"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v want %v", err, context.Canceled)
+	}
+	if got := ctx.calls.Load(); got != 3 {
+		t.Errorf("got %d context checks want the scan to stop at the third", got)
+	}
+}
+
+func TestInMemoryTodoRepoDeadline(t *testing.T) {
+	repo := NewInMemoryTodoRepo()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	if _, err := repo.ListByUser(ctx, "alice"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v want %v", err, context.DeadlineExceeded)
+	}
+}
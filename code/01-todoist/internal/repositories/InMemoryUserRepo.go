@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"todoist/internal/models"
+)
+
+type InMemoryUserRepo struct {
+	data map[string]models.User
+	mu   sync.RWMutex
+}
+
+func NewInMemoryUserRepo() *InMemoryUserRepo {
+	return &InMemoryUserRepo{
+		data: make(map[string]models.User),
+	}
+}
+
+func (r *InMemoryUserRepo) Create(ctx context.Context, u models.User) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.User{}, err
+	}
+
+	if _, ok := r.data[u.ID]; ok {
+		return models.User{}, ErrConflict
+	}
+	r.data[u.ID] = u
+	return u, nil
+}
+
+func (r *InMemoryUserRepo) GetByID(ctx context.Context, id string) (models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.User{}, err
+	}
+
+	u, ok := r.data[id]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return u, nil
+}
+
+func (r *InMemoryUserRepo) Update(ctx context.Context, u models.User) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.User{}, err
+	}
+
+	if _, ok := r.data[u.ID]; !ok {
+		return models.User{}, ErrNotFound
+	}
+	r.data[u.ID] = u
+	return u, nil
+}
+
+func (r *InMemoryUserRepo) Snapshot(ctx context.Context) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return snapshotMap(r.data), nil
+}
+
+func (r *InMemoryUserRepo) ReplaceAll(ctx context.Context, records []models.User) error {
+	data, err := indexRecords(records, func(u models.User) string { return u.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.data = data
+	return nil
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"todoist/internal/models"
+)
+
+const (
+	// maxAttemptsPerWebhook bounds the delivery history kept for each webhook
+	maxAttemptsPerWebhook = 100
+	// maxDeadLettersPerWebhook bounds the failed deliveries kept for each webhook; the oldest go first
+	maxDeadLettersPerWebhook = 100
+)
+
+type InMemoryWebhookRepo struct {
+	data        map[int]models.Webhook
+	attempts    map[int][]models.DeliveryAttempt
+	deadLetters map[int][]models.DeadLetter
+	autoID      int
+	mu          sync.RWMutex
+}
+
+func NewInMemoryWebhookRepo() *InMemoryWebhookRepo {
+	return &InMemoryWebhookRepo{
+		data:        make(map[int]models.Webhook),
+		attempts:    make(map[int][]models.DeliveryAttempt),
+		deadLetters: make(map[int][]models.DeadLetter),
+		autoID:      1,
+	}
+}
+
+func (r *InMemoryWebhookRepo) Create(ctx context.Context, w models.Webhook) (models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w.ID = r.autoID
+	r.autoID++
+	r.data[w.ID] = w
+	return w, nil
+}
+
+func (r *InMemoryWebhookRepo) GetByID(ctx context.Context, id int) (models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.data[id]
+	if !ok {
+		return models.Webhook{}, ErrNotFound
+	}
+	return w, nil
+}
+
+func (r *InMemoryWebhookRepo) ListByUser(ctx context.Context, userID string) ([]models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hooks := make([]models.Webhook, 0)
+	for _, w := range r.data {
+		if w.UserID == userID {
+			hooks = append(hooks, w)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].ID < hooks[j].ID })
+
+	return hooks, nil
+}
+
+func (r *InMemoryWebhookRepo) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.data[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.data, id)
+	delete(r.attempts, id)
+	delete(r.deadLetters, id)
+	return nil
+}
+
+func (r *InMemoryWebhookRepo) AddAttempt(ctx context.Context, a models.DeliveryAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts := append(r.attempts[a.WebhookID], a)
+	if len(attempts) > maxAttemptsPerWebhook {
+		attempts = attempts[len(attempts)-maxAttemptsPerWebhook:]
+	}
+	r.attempts[a.WebhookID] = attempts
+	return nil
+}
+
+func (r *InMemoryWebhookRepo) ListAttempts(ctx context.Context, webhookID int) ([]models.DeliveryAttempt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attempts := make([]models.DeliveryAttempt, len(r.attempts[webhookID]))
+	copy(attempts, r.attempts[webhookID])
+	return attempts, nil
+}
+
+// AddDeadLetter drops letters of webhooks deleted while their delivery was still being retried
+func (r *InMemoryWebhookRepo) AddDeadLetter(ctx context.Context, d models.DeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.data[d.WebhookID]; !ok {
+		return nil
+	}
+	letters := append(r.deadLetters[d.WebhookID], d)
+	if len(letters) > maxDeadLettersPerWebhook {
+		letters = letters[len(letters)-maxDeadLettersPerWebhook:]
+	}
+	r.deadLetters[d.WebhookID] = letters
+	return nil
+}
+
+func (r *InMemoryWebhookRepo) ListDeadLetters(ctx context.Context, userID string) ([]models.DeadLetter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	letters := make([]models.DeadLetter, 0)
+	for _, perHook := range r.deadLetters {
+		for _, d := range perHook {
+			if d.UserID == userID {
+				letters = append(letters, d)
+			}
+		}
+	}
+	sort.SliceStable(letters, func(i, j int) bool { return letters[i].FailedAt.Before(letters[j].FailedAt) })
+	return letters, nil
+}
+
+func (r *InMemoryWebhookRepo) Snapshot(ctx context.Context) ([]models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return snapshotMap(r.data), nil
+}
+
+// ReplaceAll restores the webhooks themselves; delivery attempts and dead letters describe
+// deliveries of the replaced contents and are dropped
+func (r *InMemoryWebhookRepo) ReplaceAll(ctx context.Context, records []models.Webhook) error {
+	data, err := indexRecords(records, func(w models.Webhook) int { return w.ID })
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.data = data
+	r.autoID = nextID(r.autoID, data)
+	r.attempts = make(map[int][]models.DeliveryAttempt)
+	r.deadLetters = make(map[int][]models.DeadLetter)
+	return nil
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todoist/internal/models"
+)
+
+func TestInMemoryWebhookRepoDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryWebhookRepo()
+	kept, _ := repo.Create(ctx, models.Webhook{UserID: "alice", URL: "https://example.com/a"})
+	deleted, _ := repo.Create(ctx, models.Webhook{UserID: "alice", URL: "https://example.com/b"})
+
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range maxDeadLettersPerWebhook + 5 {
+		for _, hook := range []models.Webhook{kept, deleted} {
+			repo.AddDeadLetter(ctx, models.DeadLetter{WebhookID: hook.ID, UserID: "alice", Attempts: i, FailedAt: t0.Add(time.Duration(i) * time.Minute)})
+		}
+	}
+
+	letters, _ := repo.ListDeadLetters(ctx, "alice")
+	if len(letters) != 2*maxDeadLettersPerWebhook {
+		t.Fatalf("got %d letters want %d per webhook", len(letters), maxDeadLettersPerWebhook)
+	}
+	if letters[0].Attempts != 5 {
+		t.Errorf("got oldest letter %+v want the first five dropped", letters[0])
+	}
+
+	repo.Delete(ctx, deleted.ID)
+	repo.AddDeadLetter(ctx, models.DeadLetter{WebhookID: deleted.ID, UserID: "alice"})
+	letters, _ = repo.ListDeadLetters(ctx, "alice")
+	if len(letters) != maxDeadLettersPerWebhook {
+		t.Fatalf("got %d letters want only the remaining webhook's", len(letters))
+	}
+	for _, d := range letters {
+		if d.WebhookID != kept.ID {
+			t.Errorf("got letter %+v of a deleted webhook", d)
+		}
+	}
+}
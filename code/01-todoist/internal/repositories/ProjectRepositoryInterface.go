@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+	"todoist/internal/models"
+)
+
+type ProjectRepository interface {
+	Create(ctx context.Context, p models.Project) (models.Project, error)
+	GetByID(ctx context.Context, id int) (models.Project, error)
+	ListByUser(ctx context.Context, userID string) ([]models.Project, error)
+	Update(ctx context.Context, p models.Project) (models.Project, error)
+}
@@ -0,0 +1,391 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"hash/maphash"
+	"math/bits"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+	"todoist/internal/models"
+	"unsafe"
+)
+
+// ShardedTodoRepo is an in-memory TodoRepository for many concurrent users. Todos are spread
+// over stripes by ID, each with its own lock, so requests for different todos rarely wait
+// for each other. A per-user index, striped by user ID, answers ListByUser without scanning
+// everyone else's todos, and IDs come from an atomic counter instead of a shared lock.
+//
+// Locks are always taken todo stripe first, then user stripes in index order, which keeps
+// a todo's record, its index entry and its change log entry moving together.
+type ShardedTodoRepo struct {
+	todos  []todoStripe
+	users  []userStripe
+	mask   int
+	seed   maphash.Seed
+	lastID atomic.Int64
+	seq    atomic.Uint64
+}
+
+// stripeSize is what each stripe is padded to, so neighbouring stripes' locks are never on the
+// same cache line, nor on the pair of lines some CPUs fetch together
+const stripeSize = 128
+
+type todoStripe struct {
+	todoShard
+	_ [stripeSize - unsafe.Sizeof(todoShard{})%stripeSize]byte
+}
+
+type todoShard struct {
+	mu   sync.RWMutex
+	data map[int]models.Todo
+}
+
+type userStripe struct {
+	userShard
+	_ [stripeSize - unsafe.Sizeof(userShard{})%stripeSize]byte
+}
+
+type userShard struct {
+	mu  sync.RWMutex
+	ids map[string]map[int]struct{}
+	// changes holds the latest change per todo of each user, including tombstones
+	changes map[string]map[int]Change
+}
+
+// NewShardedTodoRepo splits the repository into the given number of stripes, rounded up to a
+// power of two. Zero or less picks four stripes per CPU.
+func NewShardedTodoRepo(stripes int) *ShardedTodoRepo {
+	if stripes <= 0 {
+		stripes = 4 * runtime.GOMAXPROCS(0)
+	}
+	stripes = 1 << bits.Len(uint(stripes-1))
+
+	r := &ShardedTodoRepo{
+		todos: make([]todoStripe, stripes),
+		users: make([]userStripe, stripes),
+		mask:  stripes - 1,
+		seed:  maphash.MakeSeed(),
+	}
+	for i := range r.todos {
+		r.todos[i].data = make(map[int]models.Todo)
+		r.users[i].ids = make(map[string]map[int]struct{})
+		r.users[i].changes = make(map[string]map[int]Change)
+	}
+	return r
+}
+
+func (r *ShardedTodoRepo) todoStripe(id int) *todoStripe {
+	return &r.todos[id&r.mask]
+}
+
+func (r *ShardedTodoRepo) userIndex(userID string) int {
+	return int(maphash.String(r.seed, userID)) & r.mask
+}
+
+// lockUsers write-locks the stripes of both users in index order and returns the unlock
+func (r *ShardedTodoRepo) lockUsers(a, b string) func() {
+	i, j := r.userIndex(a), r.userIndex(b)
+	if i > j {
+		i, j = j, i
+	}
+	r.users[i].mu.Lock()
+	if j == i {
+		return r.users[i].mu.Unlock
+	}
+	r.users[j].mu.Lock()
+	return func() {
+		r.users[j].mu.Unlock()
+		r.users[i].mu.Unlock()
+	}
+}
+
+// index adds t to its user's index and change log; the caller holds the user's stripe
+func (r *ShardedTodoRepo) index(op ChangeOp, t models.Todo) {
+	s := &r.users[r.userIndex(t.UserID)]
+	if op == OpUpsert {
+		ids, ok := s.ids[t.UserID]
+		if !ok {
+			ids = make(map[int]struct{})
+			s.ids[t.UserID] = ids
+		}
+		ids[t.ID] = struct{}{}
+	}
+
+	changes, ok := s.changes[t.UserID]
+	if !ok {
+		changes = make(map[int]Change)
+		s.changes[t.UserID] = changes
+	}
+	changes[t.ID] = Change{Seq: r.seq.Add(1), Op: op, Todo: t, At: time.Now()}
+}
+
+// unindex removes t from its user's index, and its change too when the todo moves to another user
+func (r *ShardedTodoRepo) unindex(t models.Todo, dropChange bool) {
+	s := &r.users[r.userIndex(t.UserID)]
+	if ids := s.ids[t.UserID]; ids != nil {
+		delete(ids, t.ID)
+		if len(ids) == 0 {
+			delete(s.ids, t.UserID)
+		}
+	}
+	if dropChange {
+		delete(s.changes[t.UserID], t.ID)
+	}
+}
+
+func (r *ShardedTodoRepo) Create(ctx context.Context, t models.Todo) (models.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
+	t.ID = int(r.lastID.Add(1))
+	s := r.todoStripe(t.ID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock := r.lockUsers(t.UserID, t.UserID)
+	defer unlock()
+
+	s.data[t.ID] = t
+	r.index(OpUpsert, t)
+	return t, nil
+}
+
+func (r *ShardedTodoRepo) Restore(ctx context.Context, t models.Todo) (models.Todo, error) {
+	s := r.todoStripe(t.ID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+	if _, ok := s.data[t.ID]; ok || t.ID <= 0 || int64(t.ID) > r.lastID.Load() {
+		return models.Todo{}, ErrConflict
+	}
+
+	unlock := r.lockUsers(t.UserID, t.UserID)
+	defer unlock()
+
+	s.data[t.ID] = t
+	r.index(OpUpsert, t)
+	return t, nil
+}
+
+func (r *ShardedTodoRepo) GetByID(ctx context.Context, id int) (models.Todo, error) {
+	s := r.todoStripe(id)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
+	t, ok := s.data[id]
+	if !ok {
+		return models.Todo{}, ErrNotFound
+	}
+	return t, nil
+}
+
+// ListByUser reads the user's IDs from the index and then each todo from its stripe. A todo
+// written in between is returned as it is after that write; one deleted in between is left out.
+func (r *ShardedTodoRepo) ListByUser(ctx context.Context, userID string) ([]models.Todo, error) {
+	u := &r.users[r.userIndex(userID)]
+	u.mu.RLock()
+	if err := ctx.Err(); err != nil {
+		u.mu.RUnlock()
+		return nil, err
+	}
+	ids := make([]int, 0, len(u.ids[userID]))
+	for id := range u.ids[userID] {
+		ids = append(ids, id)
+	}
+	u.mu.RUnlock()
+	slices.Sort(ids)
+
+	todos := make([]models.Todo, 0, len(ids))
+	for i, id := range ids {
+		if i%scanCheckInterval == scanCheckInterval-1 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		s := r.todoStripe(id)
+		s.mu.RLock()
+		t, ok := s.data[id]
+		s.mu.RUnlock()
+		if ok && t.UserID == userID {
+			todos = append(todos, t)
+		}
+	}
+	return todos, nil
+}
+
+func (r *ShardedTodoRepo) Update(ctx context.Context, t models.Todo) (models.Todo, error) {
+	s := r.todoStripe(t.ID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return models.Todo{}, err
+	}
+
+	old, ok := s.data[t.ID]
+	if !ok {
+		return models.Todo{}, ErrNotFound
+	}
+
+	unlock := r.lockUsers(old.UserID, t.UserID)
+	defer unlock()
+
+	if old.UserID != t.UserID {
+		r.unindex(old, true)
+	}
+	s.data[t.ID] = t
+	r.index(OpUpsert, t)
+	return t, nil
+}
+
+func (r *ShardedTodoRepo) Delete(ctx context.Context, id int) error {
+	s := r.todoStripe(id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t, ok := s.data[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	unlock := r.lockUsers(t.UserID, t.UserID)
+	defer unlock()
+
+	delete(s.data, id)
+	r.unindex(t, false)
+	r.index(OpDelete, t)
+	return nil
+}
+
+func (r *ShardedTodoRepo) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// ChangesSince only reads the user's stripe. Sequence numbers come from a counter shared by
+// all users, but every change of one user is numbered under that user's stripe lock, so
+// none of them can appear later with a number below the returned head.
+func (r *ShardedTodoRepo) ChangesSince(ctx context.Context, userID string, since uint64) ([]Change, uint64, error) {
+	u := &r.users[r.userIndex(userID)]
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	changes := make([]Change, 0)
+	for _, c := range u.changes[userID] {
+		if c.Seq > since {
+			changes = append(changes, c)
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int { return cmp.Compare(a.Seq, b.Seq) })
+
+	return changes, r.seq.Load(), nil
+}
+
+// lockAll takes every lock in the documented order
+func (r *ShardedTodoRepo) lockAll(write bool) func() {
+	for i := range r.todos {
+		if write {
+			r.todos[i].mu.Lock()
+		} else {
+			r.todos[i].mu.RLock()
+		}
+	}
+	for i := range r.users {
+		if write {
+			r.users[i].mu.Lock()
+		} else {
+			r.users[i].mu.RLock()
+		}
+	}
+	return func() {
+		for i := range r.users {
+			if write {
+				r.users[i].mu.Unlock()
+			} else {
+				r.users[i].mu.RUnlock()
+			}
+		}
+		for i := range r.todos {
+			if write {
+				r.todos[i].mu.Unlock()
+			} else {
+				r.todos[i].mu.RUnlock()
+			}
+		}
+	}
+}
+
+// Snapshot holds every stripe's read lock while copying, so the copy is consistent across stripes
+func (r *ShardedTodoRepo) Snapshot(ctx context.Context) ([]models.Todo, error) {
+	unlock := r.lockAll(false)
+	defer unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	todos := make([]models.Todo, 0)
+	for i := range r.todos {
+		for _, t := range r.todos[i].data {
+			todos = append(todos, t)
+		}
+	}
+	slices.SortFunc(todos, func(a, b models.Todo) int { return a.ID - b.ID })
+	return todos, nil
+}
+
+// ReplaceAll records the swap in the change log like InMemoryTodoRepo.ReplaceAll
+func (r *ShardedTodoRepo) ReplaceAll(ctx context.Context, records []models.Todo) error {
+	data, err := indexRecords(records, func(t models.Todo) int { return t.ID })
+	if err != nil {
+		return err
+	}
+
+	unlock := r.lockAll(true)
+	defer unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for i := range r.todos {
+		for id, old := range r.todos[i].data {
+			switch t, ok := data[id]; {
+			case !ok:
+				r.unindex(old, false)
+				r.index(OpDelete, old)
+			case t.UserID != old.UserID:
+				r.unindex(old, true)
+			}
+		}
+		r.todos[i].data = make(map[int]models.Todo)
+	}
+	for _, t := range snapshotMap(data) {
+		r.todoStripe(t.ID).data[t.ID] = t
+		r.index(OpUpsert, t)
+	}
+	if last := int64(nextID(int(r.lastID.Load())+1, data) - 1); last > r.lastID.Load() {
+		r.lastID.Store(last)
+	}
+	return nil
+}
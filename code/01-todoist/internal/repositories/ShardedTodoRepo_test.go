@@ -0,0 +1,200 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"todoist/internal/models"
+	"unsafe"
+)
+
+func TestShardedTodoRepoStripes(t *testing.T) {
+	for stripes, want := range map[int]int{1: 1, 3: 4, 16: 16, 17: 32} {
+		if got := len(NewShardedTodoRepo(stripes).todos); got != want {
+			t.Errorf("%d: got %d stripes want %d", stripes, got, want)
+		}
+	}
+	if got := len(NewShardedTodoRepo(0).todos); got < 4 {
+		t.Errorf("got %d default stripes want at least 4", got)
+	}
+	if a, b := unsafe.Sizeof(todoStripe{}), unsafe.Sizeof(userStripe{}); a%stripeSize != 0 || b%stripeSize != 0 {
+		t.Errorf("got stripes of %d and %d bytes want multiples of %d", a, b, stripeSize)
+	}
+}
+
+func TestShardedTodoRepoChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := NewShardedTodoRepo(4)
+
+	a, _ := repo.Create(ctx, models.Todo{UserID: "alice", Title: "a"})
+	b, _ := repo.Create(ctx, models.Todo{UserID: "alice", Title: "b"})
+	repo.Create(ctx, models.Todo{UserID: "bob", Title: "c"})
+	_, head, _ := repo.ChangesSince(ctx, "alice", 0)
+
+	a.Title = "a2"
+	repo.Update(ctx, a)
+	repo.Delete(ctx, b.ID)
+
+	changes, next, err := repo.ChangesSince(ctx, "alice", head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Todo.Title != "a2" || changes[1].Op != OpDelete || changes[1].Todo.ID != b.ID {
+		t.Errorf("got %+v want the update then the delete", changes)
+	}
+	if next <= head {
+		t.Errorf("got head %d want above %d", next, head)
+	}
+
+	// moving a todo to another user takes its index entry and change with it
+	a.UserID = "bob"
+	repo.Update(ctx, a)
+	if alice, _ := repo.ListByUser(ctx, "alice"); len(alice) != 0 {
+		t.Errorf("got %+v want alice's list empty", alice)
+	}
+	if bob, _ := repo.ListByUser(ctx, "bob"); len(bob) != 2 {
+		t.Errorf("got %+v want both of bob's todos", bob)
+	}
+	if changes, _, _ := repo.ChangesSince(ctx, "alice", next); len(changes) != 0 {
+		t.Errorf("got %+v want no changes for alice", changes)
+	}
+}
+
+func TestShardedTodoRepoRestoreAndReplace(t *testing.T) {
+	ctx := context.Background()
+	repo := NewShardedTodoRepo(4)
+
+	a, _ := repo.Create(ctx, models.Todo{UserID: "alice", Title: "a"})
+	repo.Delete(ctx, a.ID)
+	if _, err := repo.Restore(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{a.ID, 0, a.ID + 1} {
+		if _, err := repo.Restore(ctx, models.Todo{ID: id, UserID: "alice"}); err != ErrConflict {
+			t.Errorf("restore %d: got %v want %v", id, err, ErrConflict)
+		}
+	}
+
+	replacement := []models.Todo{{ID: 7, UserID: "bob", Title: "x"}, {ID: a.ID, UserID: "bob", Title: "moved"}}
+	if err := repo.ReplaceAll(ctx, replacement); err != nil {
+		t.Fatal(err)
+	}
+	if alice, _ := repo.ListByUser(ctx, "alice"); len(alice) != 0 {
+		t.Errorf("got %+v want alice's todos replaced", alice)
+	}
+	snapshot, _ := repo.Snapshot(ctx)
+	if len(snapshot) != 2 || snapshot[0].ID != a.ID || snapshot[1].ID != 7 {
+		t.Errorf("got %+v want the replacement", snapshot)
+	}
+	if next, _ := repo.Create(ctx, models.Todo{UserID: "bob"}); next.ID != 8 {
+		t.Errorf("got id %d want 8", next.ID)
+	}
+}
+
+// The benchmarks compare the single-lock and the sharded repository holding benchTodos todos
+// spread over benchUsers users, at several multiples of GOMAXPROCS goroutines:
+//
+//	go test ./internal/repositories -run '^$' -bench TodoRepos -benchmem
+const benchUsers = 10_000
+
+func benchTodos() int {
+	if testing.Short() {
+		return 100_000
+	}
+	return 1_000_000
+}
+
+type benchFixture struct {
+	op   string
+	repo TodoRepository
+}
+
+var (
+	benchMu       sync.Mutex
+	benchFixtures = make(map[string]benchFixture)
+)
+
+// benchRepo fills a repository of each kind for op, shared with the benchmarks before it that
+// asked for the same op. Only one fixture per kind is kept, so a million todos fit in memory.
+func benchRepo(b *testing.B, name, op string) TodoRepository {
+	b.Helper()
+
+	benchMu.Lock()
+	defer benchMu.Unlock()
+
+	if f, ok := benchFixtures[name]; ok && f.op == op {
+		return f.repo
+	}
+	delete(benchFixtures, name)
+
+	var repo TodoRepository = NewInMemoryTodoRepo()
+	if name == "Sharded" {
+		repo = NewShardedTodoRepo(0)
+	}
+	ctx := context.Background()
+	for i := range benchTodos() {
+		repo.Create(ctx, models.Todo{UserID: benchUser(i), Title: "todo", Status: models.StatusPending})
+	}
+	benchFixtures[name] = benchFixture{op, repo}
+	return repo
+}
+
+func benchUser(n int) string {
+	return fmt.Sprint("user", n%benchUsers)
+}
+
+func BenchmarkTodoRepos(b *testing.B) {
+	ctx := context.Background()
+	ops := map[string]func(repo TodoRepository, n int){
+		"GetByID": func(repo TodoRepository, n int) {
+			repo.GetByID(ctx, 1+rand.IntN(n))
+		},
+		"ListByUser": func(repo TodoRepository, n int) {
+			repo.ListByUser(ctx, benchUser(rand.IntN(benchUsers)))
+		},
+		"Update": func(repo TodoRepository, n int) {
+			id := 1 + rand.IntN(n)
+			repo.Update(ctx, models.Todo{ID: id, UserID: benchUser(id - 1), Title: "updated", Status: models.StatusCompleted})
+		},
+		"Create": func(repo TodoRepository, n int) {
+			repo.Create(ctx, models.Todo{UserID: benchUser(rand.IntN(benchUsers)), Title: "new"})
+		},
+		// Mixed is mostly point reads with some writes and the occasional list, like the API sees
+		"Mixed": func(repo TodoRepository, n int) {
+			switch p := rand.IntN(100); {
+			case p < 80:
+				repo.GetByID(ctx, 1+rand.IntN(n))
+			case p < 95:
+				id := 1 + rand.IntN(n)
+				repo.Update(ctx, models.Todo{ID: id, UserID: benchUser(id - 1), Title: "updated"})
+			default:
+				repo.ListByUser(ctx, benchUser(rand.IntN(benchUsers)))
+			}
+		},
+	}
+
+	// the reads and Mixed share one fixture, whose size Mixed does not change; Update and Create
+	// run last on fixtures of their own, so neither repository has been written to more than the other
+	for _, op := range []string{"GetByID", "ListByUser", "Mixed", "Update", "Create"} {
+		fixture := op
+		if op == "GetByID" || op == "ListByUser" || op == "Mixed" {
+			fixture = "reads"
+		}
+		for _, name := range []string{"InMemory", "Sharded"} {
+			for _, parallelism := range []int{1, 16, 256} {
+				b.Run(fmt.Sprintf("%s/%s/parallelism=%d", op, name, parallelism), func(b *testing.B) {
+					repo, n := benchRepo(b, name, fixture), benchTodos()
+					b.SetParallelism(parallelism)
+					b.ResetTimer()
+					b.RunParallel(func(pb *testing.PB) {
+						for pb.Next() {
+							ops[op](repo, n)
+						}
+					})
+				})
+			}
+		}
+	}
+}
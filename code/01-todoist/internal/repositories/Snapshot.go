@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// Snapshotter is implemented by repositories that can be backed up and restored whole.
+// Snapshot copies every record under the read lock, so writers only wait for the copy.
+// ReplaceAll swaps in a new set of records under the write lock in one step; it returns
+// ErrConflict, and changes nothing, if two records share a key.
+type Snapshotter[T any] interface {
+	Snapshot(ctx context.Context) ([]T, error)
+	ReplaceAll(ctx context.Context, records []T) error
+}
+
+// snapshotMap copies the values of m ordered by key; callers hold the read lock
+func snapshotMap[K cmp.Ordered, V any](m map[K]V) []V {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	out := make([]V, len(keys))
+	for i, k := range keys {
+		out[i] = m[k]
+	}
+	return out
+}
+
+// indexRecords builds the map ReplaceAll swaps in
+func indexRecords[K comparable, V any](records []V, key func(V) K) (map[K]V, error) {
+	m := make(map[K]V, len(records))
+	for _, r := range records {
+		k := key(r)
+		if _, ok := m[k]; ok {
+			return nil, ErrConflict
+		}
+		m[k] = r
+	}
+	return m, nil
+}
+
+// nextID keeps handing out IDs above both the restored records and anything issued before,
+// so an ID never names two different records over the life of the process
+func nextID[V any](current int, records map[int]V) int {
+	for id := range records {
+		current = max(current, id+1)
+	}
+	return current
+}
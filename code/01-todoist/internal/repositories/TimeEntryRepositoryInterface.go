@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"todoist/internal/models"
+)
+
+// TimeEntryRepository stores time entries. Create and Update return ErrConflict when the entry
+// overlaps another of the same user's entries; since a running entry is open ended this also
+// keeps each user to one running timer.
+type TimeEntryRepository interface {
+	Create(ctx context.Context, e models.TimeEntry) (models.TimeEntry, error)
+	GetByID(ctx context.Context, id int) (models.TimeEntry, error)
+	Update(ctx context.Context, e models.TimeEntry) (models.TimeEntry, error)
+	Delete(ctx context.Context, id int) error
+	ListByTodo(ctx context.Context, todoID int) ([]models.TimeEntry, error)
+	// ListByUser returns the user's entries overlapping [from, to) ordered by start
+	ListByUser(ctx context.Context, userID string, from, to time.Time) ([]models.TimeEntry, error)
+	// Running returns ErrNotFound when the user has no running timer
+	Running(ctx context.Context, userID string) (models.TimeEntry, error)
+}
@@ -12,3 +12,14 @@ type TodoRepository interface {
 	Update(ctx context.Context, t models.Todo) (models.Todo, error)
 	Delete(ctx context.Context, id int) error
 }
+
+// Pinger is implemented by repositories that can report whether their storage is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Restorer is implemented by repositories that can bring a deleted todo back under its old ID
+type Restorer interface {
+	// Restore returns ErrConflict if the ID is in use or was never handed out
+	Restore(ctx context.Context, t models.Todo) (models.Todo, error)
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+	"todoist/internal/models"
+)
+
+type UserRepository interface {
+	// Create returns ErrConflict when the ID is already taken
+	Create(ctx context.Context, u models.User) (models.User, error)
+	GetByID(ctx context.Context, id string) (models.User, error)
+	Update(ctx context.Context, u models.User) (models.User, error)
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"todoist/internal/models"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, w models.Webhook) (models.Webhook, error)
+	GetByID(ctx context.Context, id int) (models.Webhook, error)
+	ListByUser(ctx context.Context, userID string) ([]models.Webhook, error)
+	Delete(ctx context.Context, id int) error
+
+	AddAttempt(ctx context.Context, a models.DeliveryAttempt) error
+	ListAttempts(ctx context.Context, webhookID int) ([]models.DeliveryAttempt, error)
+	AddDeadLetter(ctx context.Context, d models.DeadLetter) error
+	ListDeadLetters(ctx context.Context, userID string) ([]models.DeadLetter, error)
+}
@@ -0,0 +1,42 @@
+package repositories_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"todoist/internal/repositories"
+	"todoist/internal/repositories/repositorytest"
+)
+
+func TestInMemoryTodoRepoContract(t *testing.T) {
+	repositorytest.TestTodoRepository(t, func(t *testing.T) repositories.TodoRepository {
+		return repositories.NewInMemoryTodoRepo()
+	})
+}
+
+func TestEventSourcedTodoRepoContract(t *testing.T) {
+	repositorytest.TestTodoRepository(t, func(t *testing.T) repositories.TodoRepository {
+		repo, err := repositories.NewEventSourcedTodoRepo(context.Background(), repositories.NewInMemoryEventStore())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repo
+	})
+}
+
+func TestCachingTodoRepoContract(t *testing.T) {
+	repositorytest.TestTodoRepository(t, func(t *testing.T) repositories.TodoRepository {
+		return repositories.NewCachingTodoRepo(repositories.NewInMemoryTodoRepo(), 64, time.Minute)
+	})
+}
+
+func TestShardedTodoRepoContract(t *testing.T) {
+	for _, stripes := range []int{1, 16} {
+		t.Run(fmt.Sprint(stripes, " stripes"), func(t *testing.T) {
+			repositorytest.TestTodoRepository(t, func(t *testing.T) repositories.TodoRepository {
+				return repositories.NewShardedTodoRepo(stripes)
+			})
+		})
+	}
+}
@@ -0,0 +1,399 @@
+// Package repositorytest holds the behaviour every repositories.TodoRepository must share.
+//
+// A new backend runs the suite from its own tests:
+//
+//	func TestMyTodoRepo(t *testing.T) {
+//		repositorytest.TestTodoRepository(t, func(t *testing.T) repositories.TodoRepository {
+//			return NewMyTodoRepo()
+//		})
+//	}
+//
+// Run it with -race; the concurrency checks are only meaningful under the race detector.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+// Factory returns an empty repository. It is called once per subtest, so state never leaks between them.
+type Factory func(t *testing.T) repositories.TodoRepository
+
+// TestTodoRepository runs the whole contract against repositories made by newRepo
+func TestTodoRepository(t *testing.T, newRepo Factory) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, newRepo(t)) })
+	t.Run("GetByID", func(t *testing.T) { testGetByID(t, newRepo(t)) })
+	t.Run("ListByUser", func(t *testing.T) { testListByUser(t, newRepo(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepo(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newRepo(t)) })
+	t.Run("Cancellation", func(t *testing.T) { testCancellation(t, newRepo(t)) })
+	t.Run("Deadline", func(t *testing.T) { testDeadline(t, newRepo(t)) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, newRepo(t)) })
+}
+
+var created = time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+// todo is a fully populated record, so a backend that drops a field fails the comparisons
+func todo(userID, title string) models.Todo {
+	due := created.Add(48 * time.Hour)
+	return models.Todo{
+		UserID:      userID,
+		Title:       title,
+		Description: "about " + title,
+		Status:      models.StatusPending,
+		DueAt:       &due,
+		Labels:      []string{"home", "errand"},
+		Priority:    models.PriorityHigh,
+		Recurrence:  "FREQ=WEEKLY",
+		Position:    "a0",
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+}
+
+func create(t *testing.T, repo repositories.TodoRepository, userID, title string) models.Todo {
+	t.Helper()
+
+	got, err := repo.Create(context.Background(), todo(userID, title))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return got
+}
+
+func equal(t *testing.T, got, want models.Todo) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+}
+
+func ids(todos []models.Todo) []int {
+	out := make([]int, len(todos))
+	for i, t := range todos {
+		out[i] = t.ID
+	}
+	slices.Sort(out)
+	return out
+}
+
+func testCreate(t *testing.T, repo repositories.TodoRepository) {
+	ctx := context.Background()
+
+	in := todo("alice", "first")
+	in.ID = 42
+	first, err := repo.Create(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID <= 0 {
+		t.Fatalf("got id %d want a positive id assigned by the repository", first.ID)
+	}
+	in.ID = first.ID
+	equal(t, first, in)
+
+	got, err := repo.GetByID(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equal(t, got, first)
+
+	last := first.ID
+	for i := range 10 {
+		next := create(t, repo, "alice", fmt.Sprint("todo ", i))
+		if next.ID <= last {
+			t.Fatalf("got id %d after %d want increasing ids", next.ID, last)
+		}
+		last = next.ID
+	}
+}
+
+func testGetByID(t *testing.T, repo repositories.TodoRepository) {
+	ctx := context.Background()
+	existing := create(t, repo, "alice", "a")
+
+	for _, id := range []int{0, -1, existing.ID + 1000} {
+		if _, err := repo.GetByID(ctx, id); !errors.Is(err, repositories.ErrNotFound) {
+			t.Errorf("GetByID(%d): got %v want %v", id, err, repositories.ErrNotFound)
+		}
+	}
+}
+
+func testListByUser(t *testing.T, repo repositories.TodoRepository) {
+	ctx := context.Background()
+
+	var alice, bob []models.Todo
+	for i := range 3 {
+		alice = append(alice, create(t, repo, "alice", fmt.Sprint("alice ", i)))
+		bob = append(bob, create(t, repo, "bob", fmt.Sprint("bob ", i)))
+	}
+
+	for user, want := range map[string][]models.Todo{"alice": alice, "bob": bob} {
+		got, err := repo.ListByUser(ctx, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(ids(got), ids(want)) {
+			t.Errorf("%s: got ids %v want %v", user, ids(got), ids(want))
+		}
+		for _, g := range got {
+			if g.UserID != user {
+				t.Errorf("%s: listed %+v", user, g)
+			}
+		}
+	}
+
+	// handlers encode the result directly, and a nil slice would become null instead of []
+	got, err := repo.ListByUser(ctx, "nobody")
+	if err != nil || got == nil || len(got) != 0 {
+		t.Errorf("got %v, %v want an empty non-nil list", got, err)
+	}
+}
+
+func testUpdate(t *testing.T, repo repositories.TodoRepository) {
+	ctx := context.Background()
+	target := create(t, repo, "alice", "target")
+	other := create(t, repo, "alice", "other")
+
+	changed := target
+	changed.Title = "renamed"
+	changed.Status = models.StatusCompleted
+	changed.Labels = []string{"work"}
+	changed.DueAt = nil
+	changed.UpdatedAt = created.Add(time.Hour)
+
+	got, err := repo.Update(ctx, changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equal(t, got, changed)
+
+	stored, err := repo.GetByID(ctx, target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equal(t, stored, changed)
+
+	untouched, err := repo.GetByID(ctx, other.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equal(t, untouched, other)
+
+	missing := todo("alice", "ghost")
+	missing.ID = other.ID + 1000
+	if _, err := repo.Update(ctx, missing); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("got %v want %v", err, repositories.ErrNotFound)
+	}
+	if _, err := repo.GetByID(ctx, missing.ID); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("updating a missing todo created it: %v", err)
+	}
+}
+
+func testDelete(t *testing.T, repo repositories.TodoRepository) {
+	ctx := context.Background()
+	gone := create(t, repo, "alice", "gone")
+	kept := create(t, repo, "alice", "kept")
+
+	if err := repo.Delete(ctx, gone.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByID(ctx, gone.ID); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("got %v want %v", err, repositories.ErrNotFound)
+	}
+	if err := repo.Delete(ctx, gone.ID); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("second delete: got %v want %v", err, repositories.ErrNotFound)
+	}
+	if _, err := repo.Update(ctx, gone); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("update after delete: got %v want %v", err, repositories.ErrNotFound)
+	}
+
+	list, err := repo.ListByUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(list); !slices.Equal(got, []int{kept.ID}) {
+		t.Errorf("got ids %v want %v", got, []int{kept.ID})
+	}
+
+	// sync clients and undo refer to todos by ID long after they are gone, so IDs are never reused
+	if next := create(t, repo, "alice", "next"); next.ID <= kept.ID {
+		t.Errorf("got id %d want an id above %d", next.ID, kept.ID)
+	}
+}
+
+func testCancellation(t *testing.T, repo repositories.TodoRepository) {
+	existing := create(t, repo, "alice", "existing")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	changed := existing
+	changed.Title = "changed"
+	calls := map[string]func() error{
+		"Create": func() error {
+			_, err := repo.Create(ctx, todo("alice", "new"))
+			return err
+		},
+		"GetByID": func() error {
+			_, err := repo.GetByID(ctx, existing.ID)
+			return err
+		},
+		"ListByUser": func() error {
+			_, err := repo.ListByUser(ctx, "alice")
+			return err
+		},
+		"Update": func() error {
+			_, err := repo.Update(ctx, changed)
+			return err
+		},
+		"Delete": func() error {
+			return repo.Delete(ctx, existing.ID)
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			if err := call(); !errors.Is(err, context.Canceled) {
+				t.Errorf("got %v want %v", err, context.Canceled)
+			}
+		})
+	}
+
+	list, err := repo.ListByUser(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("cancelled calls changed the repository: %+v", list)
+	}
+	equal(t, list[0], existing)
+}
+
+func testDeadline(t *testing.T, repo repositories.TodoRepository) {
+	create(t, repo, "alice", "a")
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	if _, err := repo.ListByUser(ctx, "alice"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// testConcurrency has one writer per user racing with a reader per user. Each writer
+// knows exactly what its user should end up with, so lost or misfiled writes show up.
+func testConcurrency(t *testing.T, repo repositories.TodoRepository) {
+	const writers, perWriter = 8, 40
+	ctx := context.Background()
+
+	users := make([]string, writers)
+	for i := range users {
+		users[i] = fmt.Sprint("user", i)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created = make(map[int]string)
+		want    = make(map[string][]int)
+		errs    = make(chan error, 2*writers*perWriter)
+	)
+
+	for _, user := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var kept []int
+			for i := range perWriter {
+				td, err := repo.Create(ctx, todo(user, fmt.Sprint(user, " ", i)))
+				if err != nil {
+					errs <- err
+					return
+				}
+				mu.Lock()
+				if owner, dup := created[td.ID]; dup {
+					errs <- fmt.Errorf("id %d handed to %s and %s", td.ID, owner, user)
+				}
+				created[td.ID] = user
+				mu.Unlock()
+
+				td.Title += " updated"
+				if _, err := repo.Update(ctx, td); err != nil {
+					errs <- err
+					return
+				}
+				if i%3 == 0 {
+					if err := repo.Delete(ctx, td.ID); err != nil {
+						errs <- err
+						return
+					}
+					continue
+				}
+				kept = append(kept, td.ID)
+			}
+
+			slices.Sort(kept)
+			mu.Lock()
+			want[user] = kept
+			mu.Unlock()
+		}()
+	}
+
+	var readers sync.WaitGroup
+	for _, user := range users {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for range perWriter {
+				list, err := repo.ListByUser(ctx, user)
+				if err != nil {
+					errs <- err
+					return
+				}
+				for _, td := range list {
+					if td.UserID != user {
+						errs <- fmt.Errorf("%s listed a todo of %s", user, td.UserID)
+						return
+					}
+					if _, err := repo.GetByID(ctx, td.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+						errs <- err
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	readers.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for _, user := range users {
+		list, err := repo.ListByUser(ctx, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(list); !slices.Equal(got, want[user]) {
+			t.Errorf("%s: got ids %v want %v", user, got, want[user])
+		}
+		for _, td := range list {
+			if !strings.HasSuffix(td.Title, " updated") {
+				t.Errorf("%s: lost update of %+v", user, td)
+			}
+		}
+	}
+}
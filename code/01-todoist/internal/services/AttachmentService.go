@@ -0,0 +1,223 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"todoist/internal/blobs"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+// DefaultMaxAttachmentSize is the largest upload accepted unless configured otherwise
+const DefaultMaxAttachmentSize = 10 << 20
+
+// sniffLen is how much of an upload http.DetectContentType looks at
+const sniffLen = 512
+
+type IAttachmentService interface {
+	Upload(ctx context.Context, dto models.UploadAttachment) (models.Attachment, error)
+	ListAttachments(ctx context.Context, todoID int, userID string) ([]models.Attachment, error)
+	// Open returns the attachment with its contents; the caller closes the reader
+	Open(ctx context.Context, todoID int, userID string, id int) (models.Attachment, io.ReadSeekCloser, error)
+	DeleteAttachment(ctx context.Context, todoID int, userID string, id int) error
+}
+
+type AttachmentService struct {
+	repo    repositories.AttachmentRepository
+	blobs   blobs.BlobStore
+	todos   ITodoService
+	maxSize int64
+}
+
+// AttachmentOption configures an AttachmentService
+type AttachmentOption func(*AttachmentService)
+
+func WithMaxAttachmentSize(n int64) AttachmentOption {
+	return func(s *AttachmentService) {
+		s.maxSize = n
+	}
+}
+
+func NewAttachmentService(repo repositories.AttachmentRepository, store blobs.BlobStore, todos ITodoService, opts ...AttachmentOption) *AttachmentService {
+	s := &AttachmentService{
+		repo:    repo,
+		blobs:   store,
+		todos:   todos,
+		maxSize: DefaultMaxAttachmentSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// cleanFilename keeps only the base name and drops control characters, since the name is echoed back in headers
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" || name == "" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
+
+// Upload streams the body into the blob store while hashing it. The content type is sniffed
+// from the bytes rather than taken from the client, and the blob is discarded if the upload is
+// too large or does not match the checksum the client sent.
+func (s *AttachmentService) Upload(ctx context.Context, dto models.UploadAttachment) (models.Attachment, error) {
+	if dto.UserID == "" || dto.Body == nil {
+		return models.Attachment{}, ErrInvalidInput
+	}
+
+	checksum := strings.ToLower(dto.Checksum)
+	if checksum != "" {
+		if b, err := hex.DecodeString(checksum); err != nil || len(b) != sha256.Size {
+			return models.Attachment{}, ErrInvalidInput
+		}
+	}
+
+	if _, err := ownedTodo(ctx, s.todos, dto.TodoID, dto.UserID); err != nil {
+		return models.Attachment{}, err
+	}
+
+	// one byte over the limit is enough to know the upload is too large
+	body := bufio.NewReaderSize(io.LimitReader(dto.Body, s.maxSize+1), sniffLen)
+	head, err := body.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return models.Attachment{}, err
+	}
+	contentType := http.DetectContentType(head)
+
+	key, err := newBlobKey()
+	if err != nil {
+		return models.Attachment{}, err
+	}
+
+	hash := sha256.New()
+	size, err := s.blobs.Put(ctx, key, io.TeeReader(body, hash))
+	if err != nil {
+		return models.Attachment{}, err
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	switch {
+	case size > s.maxSize:
+		err = ErrTooLarge
+	case checksum != "" && checksum != sum:
+		err = ErrChecksumMismatch
+	}
+	if err != nil {
+		s.blobs.Delete(context.WithoutCancel(ctx), key)
+		return models.Attachment{}, err
+	}
+
+	a, err := s.repo.Create(ctx, models.Attachment{
+		TodoID:      dto.TodoID,
+		UserID:      dto.UserID,
+		Filename:    cleanFilename(dto.Filename),
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      sum,
+		BlobKey:     key,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		s.blobs.Delete(context.WithoutCancel(ctx), key)
+		return models.Attachment{}, err
+	}
+	return a, nil
+}
+
+func newBlobKey() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (s *AttachmentService) ListAttachments(ctx context.Context, todoID int, userID string) ([]models.Attachment, error) {
+	if _, err := ownedTodo(ctx, s.todos, todoID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByTodo(ctx, todoID)
+}
+
+func (s *AttachmentService) Open(ctx context.Context, todoID int, userID string, id int) (models.Attachment, io.ReadSeekCloser, error) {
+	if _, err := ownedTodo(ctx, s.todos, todoID, userID); err != nil {
+		return models.Attachment{}, nil, err
+	}
+	a, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+	if a.TodoID != todoID {
+		return models.Attachment{}, nil, repositories.ErrNotFound
+	}
+
+	f, err := s.blobs.Open(ctx, a.BlobKey)
+	if errors.Is(err, blobs.ErrNotFound) {
+		return models.Attachment{}, nil, repositories.ErrNotFound
+	}
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+	return a, f, nil
+}
+
+// DeleteAttachment is only allowed for the uploader; anyone else gets ErrNotFound
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, todoID int, userID string, id int) error {
+	if _, err := ownedTodo(ctx, s.todos, todoID, userID); err != nil {
+		return err
+	}
+	a, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if a.TodoID != todoID || a.UserID != userID {
+		return repositories.ErrNotFound
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := s.blobs.Delete(ctx, a.BlobKey); err != nil && !errors.Is(err, blobs.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// HandleEvent is registered with events.Bus.Listen and removes the files of purged todos
+func (s *AttachmentService) HandleEvent(e events.Event) {
+	if e.Type != events.TodoPurged {
+		return
+	}
+
+	ctx := context.Background()
+	attachments, err := s.repo.ListByTodo(ctx, e.Todo.ID)
+	if err != nil {
+		return
+	}
+	for _, a := range attachments {
+		s.repo.Delete(ctx, a.ID)
+		s.blobs.Delete(ctx, a.BlobKey)
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"todoist/internal/blobs"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func newAttachmentFixture(t *testing.T) (*TodoService, *AttachmentService, models.Todo) {
+	store, err := blobs.NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo())
+	attachments := NewAttachmentService(repositories.NewInMemoryAttachmentRepo(), store, todos, WithMaxAttachmentSize(1024))
+	todo := mustCreate(t, todos, "with files")
+	return todos, attachments, todo
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAttachmentUpload(t *testing.T) {
+	ctx := context.Background()
+	_, attachments, todo := newAttachmentFixture(t)
+
+	body := "<html><body>hi</body></html>"
+	a, err := attachments.Upload(ctx, models.UploadAttachment{
+		TodoID:   todo.ID,
+		UserID:   "alice",
+		Filename: `C:\Users\alice\..\notes "draft".txt`,
+		Checksum: strings.ToUpper(sha256Hex(body)),
+		Body:     strings.NewReader(body),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("got content type %q want it sniffed from the bytes", a.ContentType)
+	}
+	if a.Filename != "notes draft.txt" {
+		t.Errorf("got filename %q want the cleaned base name", a.Filename)
+	}
+	if a.Size != int64(len(body)) || a.SHA256 != sha256Hex(body) {
+		t.Errorf("got size %d sum %s", a.Size, a.SHA256)
+	}
+
+	_, f, err := attachments.Open(ctx, todo.ID, "alice", a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := io.ReadAll(f)
+	f.Close()
+	if string(stored) != body {
+		t.Errorf("got %q want %q", stored, body)
+	}
+
+	if _, _, err := attachments.Open(ctx, todo.ID+1, "alice", a.ID); err != repositories.ErrNotFound {
+		t.Errorf("got %v want %v through another todo", err, repositories.ErrNotFound)
+	}
+	if _, _, err := attachments.Open(ctx, todo.ID, "bob", a.ID); err != repositories.ErrNotFound {
+		t.Errorf("got %v want %v for someone else's todo", err, repositories.ErrNotFound)
+	}
+	if _, err := attachments.ListAttachments(ctx, todo.ID, "bob"); err != repositories.ErrNotFound {
+		t.Errorf("got %v want %v listing someone else's todo", err, repositories.ErrNotFound)
+	}
+}
+
+func TestAttachmentUploadRejections(t *testing.T) {
+	ctx := context.Background()
+	_, attachments, todo := newAttachmentFixture(t)
+
+	tests := []struct {
+		name string
+		dto  models.UploadAttachment
+		want error
+	}{
+		{"too large", models.UploadAttachment{Body: strings.NewReader(strings.Repeat("x", 1025))}, ErrTooLarge},
+		{"checksum mismatch", models.UploadAttachment{Checksum: sha256Hex("other"), Body: strings.NewReader("data")}, ErrChecksumMismatch},
+		{"malformed checksum", models.UploadAttachment{Checksum: "abc", Body: strings.NewReader("data")}, ErrInvalidInput},
+		{"unknown todo", models.UploadAttachment{TodoID: 999, Body: strings.NewReader("data")}, repositories.ErrNotFound},
+		{"someone else's todo", models.UploadAttachment{UserID: "bob", Body: strings.NewReader("data")}, repositories.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.dto.UserID == "" {
+				tt.dto.UserID = "alice"
+			}
+			if tt.dto.TodoID == 0 {
+				tt.dto.TodoID = todo.ID
+			}
+			if _, err := attachments.Upload(ctx, tt.dto); err != tt.want {
+				t.Errorf("got %v want %v", err, tt.want)
+			}
+		})
+	}
+
+	list, _ := attachments.ListAttachments(ctx, todo.ID, "alice")
+	if len(list) != 0 {
+		t.Errorf("got %d attachments want none kept from rejected uploads", len(list))
+	}
+}
+
+func TestAttachmentsFollowTodoLifecycle(t *testing.T) {
+	ctx := context.Background()
+	todos, attachments, todo := newAttachmentFixture(t)
+	bus := events.NewBus(16)
+	bus.Listen(attachments.HandleEvent)
+	todos.events = bus
+
+	a, _ := attachments.Upload(ctx, models.UploadAttachment{TodoID: todo.ID, UserID: "alice", Body: strings.NewReader("data")})
+
+	if err := attachments.DeleteAttachment(ctx, todo.ID, "bob", a.ID); err != repositories.ErrNotFound {
+		t.Errorf("got %v want %v deleting someone else's upload", err, repositories.ErrNotFound)
+	}
+
+	if err := todos.DeleteTodo(ctx, todo.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := attachments.Open(ctx, todo.ID, "alice", a.ID); err != repositories.ErrNotFound {
+		t.Errorf("got %v want %v after the todo was deleted", err, repositories.ErrNotFound)
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+// maxCommentLength bounds a comment body in bytes
+const maxCommentLength = 10_000
+
+type ICommentService interface {
+	AddComment(ctx context.Context, dto models.CreateComment) (models.Comment, error)
+	EditComment(ctx context.Context, dto models.UpdateComment) (models.Comment, error)
+	DeleteComment(ctx context.Context, todoID int, userID string, id int) error
+	ListComments(ctx context.Context, todoID int, userID string) ([]models.Comment, error)
+}
+
+type CommentService struct {
+	repo  repositories.CommentRepository
+	todos ITodoService
+}
+
+func NewCommentService(repo repositories.CommentRepository, todos ITodoService) *CommentService {
+	return &CommentService{
+		repo:  repo,
+		todos: todos,
+	}
+}
+
+func validateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" || len(body) > maxCommentLength {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// AddComment starts a thread, or replies within one when ParentID is set
+func (s *CommentService) AddComment(ctx context.Context, dto models.CreateComment) (models.Comment, error) {
+	if dto.UserID == "" {
+		return models.Comment{}, ErrInvalidInput
+	}
+	if err := validateCommentBody(dto.Body); err != nil {
+		return models.Comment{}, err
+	}
+
+	if _, err := ownedTodo(ctx, s.todos, dto.TodoID, dto.UserID); err != nil {
+		return models.Comment{}, err
+	}
+
+	if dto.ParentID != nil {
+		parent, err := s.repo.GetByID(ctx, *dto.ParentID)
+		if err != nil || parent.TodoID != dto.TodoID {
+			// a reply must stay on the same todo as the comment it answers
+			return models.Comment{}, ErrInvalidInput
+		}
+	}
+
+	now := time.Now()
+	return s.repo.Create(ctx, models.Comment{
+		TodoID:    dto.TodoID,
+		UserID:    dto.UserID,
+		ParentID:  dto.ParentID,
+		Body:      dto.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// owned returns the comment only if it is on the todo and was written by the user.
+// Anything else looks like a missing comment so IDs cannot be probed.
+func (s *CommentService) owned(ctx context.Context, todoID int, userID string, id int) (models.Comment, error) {
+	if _, err := ownedTodo(ctx, s.todos, todoID, userID); err != nil {
+		return models.Comment{}, err
+	}
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	if c.TodoID != todoID || c.UserID != userID {
+		return models.Comment{}, repositories.ErrNotFound
+	}
+	return c, nil
+}
+
+func (s *CommentService) EditComment(ctx context.Context, dto models.UpdateComment) (models.Comment, error) {
+	if err := validateCommentBody(dto.Body); err != nil {
+		return models.Comment{}, err
+	}
+
+	c, err := s.owned(ctx, dto.TodoID, dto.UserID, dto.ID)
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	c.Body = dto.Body
+	c.UpdatedAt = time.Now()
+	return s.repo.Update(ctx, c)
+}
+
+// DeleteComment removes the comment together with its replies
+func (s *CommentService) DeleteComment(ctx context.Context, todoID int, userID string, id int) error {
+	if _, err := s.owned(ctx, todoID, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *CommentService) ListComments(ctx context.Context, todoID int, userID string) ([]models.Comment, error) {
+	if _, err := ownedTodo(ctx, s.todos, todoID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByTodo(ctx, todoID)
+}
+
+// HandleEvent is registered with events.Bus.Listen and drops the comments of purged todos
+func (s *CommentService) HandleEvent(e events.Event) {
+	if e.Type != events.TodoPurged {
+		return
+	}
+
+	ctx := context.Background()
+	comments, err := s.repo.ListByTodo(ctx, e.Todo.ID)
+	if err != nil {
+		return
+	}
+	for _, c := range comments {
+		if c.ParentID == nil {
+			s.repo.Delete(ctx, c.ID)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func TestCommentThreads(t *testing.T) {
+	ctx := context.Background()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo())
+	comments := NewCommentService(repositories.NewInMemoryCommentRepo(), todos)
+	todo := mustCreate(t, todos, "discuss")
+	other := mustCreate(t, todos, "elsewhere")
+
+	root, err := comments.AddComment(ctx, models.CreateComment{TodoID: todo.ID, UserID: "alice", Body: "thoughts?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, _ := comments.AddComment(ctx, models.CreateComment{TodoID: todo.ID, UserID: "alice", ParentID: &root.ID, Body: "looks good"})
+	comments.AddComment(ctx, models.CreateComment{TodoID: todo.ID, UserID: "alice", ParentID: &reply.ID, Body: "thanks"})
+
+	if _, err := comments.AddComment(ctx, models.CreateComment{TodoID: other.ID, UserID: "alice", ParentID: &root.ID, Body: "wrong thread"}); err != ErrInvalidInput {
+		t.Errorf("got %v want %v replying across todos", err, ErrInvalidInput)
+	}
+	if _, err := comments.AddComment(ctx, models.CreateComment{TodoID: todo.ID, UserID: "alice", Body: "   "}); err != ErrInvalidInput {
+		t.Errorf("got %v want %v for a blank body", err, ErrInvalidInput)
+	}
+	if _, err := comments.AddComment(ctx, models.CreateComment{TodoID: 999, UserID: "alice", Body: "hello"}); err != repositories.ErrNotFound {
+		t.Errorf("got %v want %v for an unknown todo", err, repositories.ErrNotFound)
+	}
+	if _, err := comments.AddComment(ctx, models.CreateComment{TodoID: todo.ID, UserID: "bob", Body: "hello"}); err != repositories.ErrNotFound {
+		t.Errorf("got %v want %v commenting on someone else's todo", err, repositories.ErrNotFound)
+	}
+	if _, err := comments.ListComments(ctx, todo.ID, "bob"); err != repositories.ErrNotFound {
+		t.Errorf("got %v want %v listing someone else's comments", err, repositories.ErrNotFound)
+	}
+
+	if _, err := comments.EditComment(ctx, models.UpdateComment{ID: reply.ID, TodoID: todo.ID, UserID: "bob", Body: "hijacked"}); err != repositories.ErrNotFound {
+		t.Errorf("got %v want %v editing someone else's comment", err, repositories.ErrNotFound)
+	}
+	edited, err := comments.EditComment(ctx, models.UpdateComment{ID: reply.ID, TodoID: todo.ID, UserID: "alice", Body: "looks great"})
+	if err != nil || edited.Body != "looks great" {
+		t.Errorf("got %+v, %v want edited body", edited, err)
+	}
+
+	if err := comments.DeleteComment(ctx, todo.ID, "alice", reply.ID); err != nil {
+		t.Fatal(err)
+	}
+	list, _ := comments.ListComments(ctx, todo.ID, "alice")
+	if len(list) != 1 || list[0].ID != root.ID {
+		t.Errorf("got %+v want only the root left once the reply and its answer are gone", list)
+	}
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+type IDependencyService interface {
+	AddBlocker(ctx context.Context, dto models.AddBlocker) error
+	RemoveBlocker(ctx context.Context, todoID, blockerID int) error
+	ListBlockers(ctx context.Context, todoID int) ([]models.Todo, error)
+	Plan(ctx context.Context, projectID int) (models.Plan, error)
+}
+
+type DependencyService struct {
+	repo     repositories.DependencyRepository
+	todos    ITodoService
+	projects repositories.ProjectRepository
+
+	// mu makes the cycle check and the insert one step
+	mu sync.Mutex
+}
+
+func NewDependencyService(repo repositories.DependencyRepository, todos ITodoService, projects repositories.ProjectRepository) *DependencyService {
+	return &DependencyService{
+		repo:     repo,
+		todos:    todos,
+		projects: projects,
+	}
+}
+
+// AddBlocker relates two todos of the same user. It fails with ErrDependencyCycle when the
+// blocker already waits, directly or not, for the todo.
+func (s *DependencyService) AddBlocker(ctx context.Context, dto models.AddBlocker) error {
+	if dto.TodoID == dto.BlockerID {
+		return ErrDependencyCycle
+	}
+
+	todo, err := s.todos.GetTodo(ctx, dto.TodoID)
+	if err != nil {
+		return err
+	}
+	blocker, err := s.todos.GetTodo(ctx, dto.BlockerID)
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && blocker.UserID != todo.UserID) {
+		return ErrInvalidInput
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	waits, err := s.reaches(ctx, dto.BlockerID, dto.TodoID)
+	if err != nil {
+		return err
+	}
+	if waits {
+		return ErrDependencyCycle
+	}
+	return s.repo.Add(ctx, dto.TodoID, dto.BlockerID)
+}
+
+// reaches walks blockers depth first from id and reports whether target is among them
+func (s *DependencyService) reaches(ctx context.Context, id, target int) (bool, error) {
+	seen := map[int]bool{id: true}
+	stack := []int{id}
+	for len(stack) > 0 {
+		next := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		blockers, err := s.repo.Blockers(ctx, next)
+		if err != nil {
+			return false, err
+		}
+		for _, b := range blockers {
+			if b == target {
+				return true, nil
+			}
+			if !seen[b] {
+				seen[b] = true
+				stack = append(stack, b)
+			}
+		}
+	}
+	return false, nil
+}
+
+func (s *DependencyService) RemoveBlocker(ctx context.Context, todoID, blockerID int) error {
+	return s.repo.Remove(ctx, todoID, blockerID)
+}
+
+func (s *DependencyService) ListBlockers(ctx context.Context, todoID int) ([]models.Todo, error) {
+	if _, err := s.todos.GetTodo(ctx, todoID); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.repo.Blockers(ctx, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	blockers := make([]models.Todo, 0, len(ids))
+	for _, id := range ids {
+		t, err := s.todos.GetTodo(ctx, id)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		blockers = append(blockers, t)
+	}
+	return blockers, nil
+}
+
+// Plan sorts the project's todos, leaving out trashed ones, so blockers come first and todos
+// that could go either way keep their list order. Blockers outside the project count towards
+// Ready but are not part of the plan.
+func (s *DependencyService) Plan(ctx context.Context, projectID int) (models.Plan, error) {
+	project, err := s.projects.GetByID(ctx, projectID)
+	if err != nil {
+		return models.Plan{}, err
+	}
+
+	all, err := s.todos.ListTodos(ctx, project.UserID)
+	if err != nil {
+		return models.Plan{}, err
+	}
+
+	status := make(map[int]models.TodoStatus, len(all))
+	order := make(map[int]int)
+	todos := make(map[int]models.Todo)
+	for _, t := range all {
+		status[t.ID] = t.Status
+		if t.ProjectID == projectID && t.Status != models.StatusTrashed {
+			order[t.ID] = len(order)
+			todos[t.ID] = t
+		}
+	}
+
+	blockedBy := make(map[int][]int, len(todos))
+	dependents := make(map[int][]int)
+	waiting := make(map[int]int, len(todos))
+	for id := range todos {
+		blockers, err := s.repo.Blockers(ctx, id)
+		if err != nil {
+			return models.Plan{}, err
+		}
+		// relations of deleted todos are kept until the delete can no longer be undone
+		blockers = slices.DeleteFunc(blockers, func(b int) bool {
+			_, ok := status[b]
+			return !ok
+		})
+		blockedBy[id] = blockers
+		for _, b := range blockers {
+			if _, ok := todos[b]; ok {
+				dependents[b] = append(dependents[b], id)
+				waiting[id]++
+			}
+		}
+	}
+
+	// Kahn's algorithm, always taking the earliest todo in list order among those ready
+	byOrder := func(a, b int) int { return order[a] - order[b] }
+	ready := make([]int, 0)
+	for id := range todos {
+		if waiting[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+	slices.SortFunc(ready, byOrder)
+
+	plan := models.Plan{ProjectID: projectID, Steps: make([]models.PlanStep, 0, len(todos)), CriticalPath: make([]int, 0)}
+	length := make(map[int]int, len(todos))
+	prev := make(map[int]int, len(todos))
+	end := 0
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+
+		step := models.PlanStep{Todo: todos[id], BlockedBy: blockedBy[id], Ready: true}
+		for _, b := range blockedBy[id] {
+			if status[b] == models.StatusPending {
+				step.Ready = false
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+
+		// blockers are always placed first, so their chain lengths are final by now
+		if todos[id].Status == models.StatusPending {
+			length[id]++
+		}
+		if length[id] > length[end] {
+			end = id
+		}
+		for _, d := range dependents[id] {
+			if length[id] > length[d] {
+				length[d], prev[d] = length[id], id
+			}
+			if waiting[d]--; waiting[d] == 0 {
+				i, _ := slices.BinarySearchFunc(ready, d, byOrder)
+				ready = slices.Insert(ready, i, d)
+			}
+		}
+	}
+	if len(plan.Steps) != len(todos) {
+		// AddBlocker refuses cycles, so this only happens if the store was edited behind its back
+		return models.Plan{}, ErrDependencyCycle
+	}
+
+	for id := end; length[id] > 0; id = prev[id] {
+		if todos[id].Status == models.StatusPending {
+			plan.CriticalPath = append(plan.CriticalPath, id)
+		}
+		if _, ok := prev[id]; !ok {
+			break
+		}
+	}
+	slices.Reverse(plan.CriticalPath)
+
+	return plan, nil
+}
+
+// HandleEvent is registered with events.Bus.Listen and drops the relations of purged todos
+func (s *DependencyService) HandleEvent(e events.Event) {
+	if e.Type != events.TodoPurged {
+		return
+	}
+	s.repo.RemoveTodo(context.Background(), e.Todo.ID)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func newDependencyService(t *testing.T) (*DependencyService, *TodoService, models.Project) {
+	t.Helper()
+	deps := repositories.NewInMemoryDependencyRepo()
+	projectRepo := repositories.NewInMemoryProjectRepo()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo(), WithProjects(projectRepo), WithDependencies(deps))
+	project, err := NewProjectService(projectRepo, todos).CreateProject(context.Background(), models.CreateProject{UserID: "alice", Name: "house"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewDependencyService(deps, todos, projectRepo), todos, project
+}
+
+func TestBlockers(t *testing.T) {
+	ctx := context.Background()
+	s, todos, _ := newDependencyService(t)
+	a := mustCreate(t, todos, "a")
+	b := mustCreate(t, todos, "b")
+	c := mustCreate(t, todos, "c")
+
+	// c waits for b, which waits for a
+	if err := s.AddBlocker(ctx, models.AddBlocker{TodoID: b.ID, BlockerID: a.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddBlocker(ctx, models.AddBlocker{TodoID: c.ID, BlockerID: b.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	bob, _ := todos.CreateTodo(ctx, models.CreateTodo{UserID: "bob", Title: "bob's"})
+	tests := []struct {
+		name string
+		dto  models.AddBlocker
+		want error
+	}{
+		{"self", models.AddBlocker{TodoID: a.ID, BlockerID: a.ID}, ErrDependencyCycle},
+		{"direct cycle", models.AddBlocker{TodoID: a.ID, BlockerID: b.ID}, ErrDependencyCycle},
+		{"transitive cycle", models.AddBlocker{TodoID: a.ID, BlockerID: c.ID}, ErrDependencyCycle},
+		{"duplicate", models.AddBlocker{TodoID: b.ID, BlockerID: a.ID}, repositories.ErrConflict},
+		{"other user", models.AddBlocker{TodoID: a.ID, BlockerID: bob.ID}, ErrInvalidInput},
+		{"shortcut", models.AddBlocker{TodoID: c.ID, BlockerID: a.ID}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.AddBlocker(ctx, tt.dto); !errors.Is(err, tt.want) {
+				t.Errorf("got %v want %v", err, tt.want)
+			}
+		})
+	}
+
+	completed := models.StatusCompleted
+	if _, err := todos.UpdateTodo(ctx, models.UpdateTodo{ID: b.ID, Status: &completed}); !errors.Is(err, ErrBlocked) {
+		t.Errorf("got %v want %v while a is pending", err, ErrBlocked)
+	}
+	if _, err := todos.UpdateTodo(ctx, models.UpdateTodo{ID: a.ID, Status: &completed}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := todos.UpdateTodo(ctx, models.UpdateTodo{ID: b.ID, Status: &completed}); err != nil {
+		t.Errorf("got %v want b completable once a is done", err)
+	}
+
+	// deleting a todo releases the todos it blocked
+	d := mustCreate(t, todos, "d")
+	s.AddBlocker(ctx, models.AddBlocker{TodoID: d.ID, BlockerID: c.ID})
+	todos.DeleteTodo(ctx, c.ID)
+	s.HandleEvent(events.Event{Type: events.TodoDeleted, Todo: c})
+	if blockers, _ := s.ListBlockers(ctx, d.ID); len(blockers) != 0 {
+		t.Errorf("got %v want no blockers left", blockers)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+	s, todos, project := newDependencyService(t)
+
+	ids := map[string]int{}
+	for _, title := range []string{"paint", "buy paint", "tape", "clean", "dry", "move in"} {
+		todo, err := todos.CreateTodo(ctx, models.CreateTodo{UserID: "alice", Title: title, ProjectID: project.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[title] = todo.ID
+	}
+	mustCreate(t, todos, "outside the project")
+
+	for _, rel := range [][2]string{
+		{"paint", "buy paint"},
+		{"paint", "tape"},
+		{"tape", "clean"},
+		{"dry", "paint"},
+		{"move in", "dry"},
+		{"move in", "clean"},
+	} {
+		if err := s.AddBlocker(ctx, models.AddBlocker{TodoID: ids[rel[0]], BlockerID: ids[rel[1]]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	completed := models.StatusCompleted
+	todos.UpdateTodo(ctx, models.UpdateTodo{ID: ids["buy paint"], Status: &completed})
+
+	plan, err := s.Plan(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var order []string
+	ready := map[string]bool{}
+	for _, step := range plan.Steps {
+		order = append(order, step.Todo.Title)
+		ready[step.Todo.Title] = step.Ready
+	}
+	if got := fmt.Sprint(order); got != "[buy paint clean tape paint dry move in]" {
+		t.Errorf("got order %s", got)
+	}
+	if !ready["clean"] || ready["tape"] || ready["paint"] {
+		t.Errorf("got ready %v want only clean and buy paint ready", ready)
+	}
+
+	var path []string
+	for _, id := range plan.CriticalPath {
+		todo, _ := todos.GetTodo(ctx, id)
+		path = append(path, todo.Title)
+	}
+	if got := fmt.Sprint(path); got != "[clean tape paint dry move in]" {
+		t.Errorf("got critical path %s", got)
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+type IHistoryService interface {
+	GetTodoAsOf(ctx context.Context, id int, at time.Time) (models.Todo, error)
+	TodoHistory(ctx context.Context, id int) ([]repositories.TodoEvent, error)
+}
+
+type HistoryService struct {
+	reader repositories.TodoHistoryReader
+}
+
+// NewHistoryService accepts a nil reader for stores that keep no history; every call then fails with errors.ErrUnsupported
+func NewHistoryService(reader repositories.TodoHistoryReader) *HistoryService {
+	return &HistoryService{reader: reader}
+}
+
+func (s *HistoryService) GetTodoAsOf(ctx context.Context, id int, at time.Time) (models.Todo, error) {
+	if s.reader == nil {
+		return models.Todo{}, errors.ErrUnsupported
+	}
+	if id <= 0 || at.IsZero() {
+		return models.Todo{}, ErrInvalidInput
+	}
+	return s.reader.GetAsOf(ctx, id, at)
+}
+
+func (s *HistoryService) TodoHistory(ctx context.Context, id int) ([]repositories.TodoEvent, error) {
+	if s.reader == nil {
+		return nil, errors.ErrUnsupported
+	}
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.reader.History(ctx, id)
+}
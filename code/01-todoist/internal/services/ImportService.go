@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"todoist/internal/formats"
+	"todoist/internal/models"
+)
+
+type IImportService interface {
+	Import(ctx context.Context, userID string, records []formats.Record) (models.ImportReport, error)
+}
+
+type ImportService struct {
+	todos ITodoService
+}
+
+func NewImportService(todos ITodoService) *ImportService {
+	return &ImportService{
+		todos: todos,
+	}
+}
+
+// Import creates or updates a todo per record. Records are matched to existing todos by UID,
+// either one this server exported or one remembered from an earlier import, so re-imports are idempotent.
+// Records without a UID are always created. Every record is reported, failures included.
+func (s *ImportService) Import(ctx context.Context, userID string, records []formats.Record) (models.ImportReport, error) {
+	if userID == "" {
+		return models.ImportReport{}, ErrInvalidInput
+	}
+
+	existing, err := s.todos.ListTodos(ctx, userID)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+
+	byID := make(map[int]models.Todo, len(existing))
+	byUID := make(map[string]models.Todo)
+	for _, t := range existing {
+		byID[t.ID] = t
+		if t.UID != "" {
+			byUID[t.UID] = t
+		}
+	}
+
+	report := models.ImportReport{Items: make([]models.ImportItem, 0, len(records))}
+	for _, v := range records {
+		item := models.ImportItem{Line: v.Line, UID: v.UID}
+
+		if v.Err != nil {
+			item.Result, item.Error = models.ImportFailed, v.Err.Error()
+			report.Add(item)
+			continue
+		}
+
+		current, found := byUID[v.UID]
+		if !found {
+			if id, ok := formats.ParseTodoUID(v.UID); ok {
+				current, found = byID[id]
+			}
+		}
+
+		if !found {
+			created, err := s.create(ctx, userID, v)
+			if err != nil {
+				item.Result, item.Error = models.ImportFailed, err.Error()
+				report.Add(item)
+				continue
+			}
+			if v.UID != "" {
+				byUID[v.UID] = created
+			}
+			item.ID, item.Result = created.ID, models.ImportCreated
+			report.Add(item)
+			continue
+		}
+
+		item.ID = current.ID
+		dto, changed := recordUpdate(current, v)
+		if !changed {
+			item.Result = models.ImportSkipped
+			report.Add(item)
+			continue
+		}
+
+		if _, err := s.todos.UpdateTodo(ctx, dto); err != nil {
+			item.Result, item.Error = models.ImportFailed, err.Error()
+		} else {
+			item.Result = models.ImportUpdated
+		}
+		report.Add(item)
+	}
+
+	return report, nil
+}
+
+func (s *ImportService) create(ctx context.Context, userID string, v formats.Record) (models.Todo, error) {
+	dto := models.CreateTodo{
+		UserID:      userID,
+		Title:       v.Title,
+		Description: v.Description,
+		DueAt:       v.Due,
+	}
+	// a UID of ours that matched nothing names a deleted todo or someone else's; never adopt it
+	if !formats.IsTodoUID(v.UID) {
+		dto.UID = v.UID
+	}
+
+	if err := ValidateCreateTodo(dto); err != nil {
+		return models.Todo{}, err
+	}
+
+	created, err := s.todos.CreateTodo(ctx, dto)
+	if err != nil {
+		return models.Todo{}, err
+	}
+
+	if v.Status != created.Status {
+		return s.todos.UpdateTodo(ctx, models.UpdateTodo{ID: created.ID, Status: &v.Status})
+	}
+	return created, nil
+}
+
+// recordUpdate builds a partial update holding only the fields that differ
+func recordUpdate(current models.Todo, v formats.Record) (models.UpdateTodo, bool) {
+	dto := models.UpdateTodo{ID: current.ID}
+	changed := false
+
+	if v.Title != current.Title {
+		dto.Title = &v.Title
+		changed = true
+	}
+	if v.Description != current.Description {
+		dto.Description = &v.Description
+		changed = true
+	}
+	if v.Status != current.Status {
+		dto.Status = &v.Status
+		changed = true
+	}
+	if v.Due != nil && (current.DueAt == nil || !v.Due.Equal(*current.DueAt)) {
+		dto.DueAt = v.Due
+		changed = true
+	}
+
+	return dto, changed
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"todoist/internal/formats"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func TestImportIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo())
+	imports := NewImportService(todos)
+
+	items := []formats.Record{
+		{Line: 3, UID: "a@calendar", Title: "from calendar", Status: models.StatusCompleted},
+		{Line: 9, UID: "b@calendar", Title: ""},
+	}
+
+	first, err := imports.Import(ctx, "alice", items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Created != 1 || first.Failed != 1 {
+		t.Errorf("got %+v want 1 created, 1 failed", first)
+	}
+
+	second, _ := imports.Import(ctx, "alice", items)
+	if second.Created != 0 || second.Skipped != 1 {
+		t.Errorf("got %+v want re-import to skip the existing todo", second)
+	}
+
+	// exporting and importing our own todos matches on the generated UID
+	list, _ := todos.ListTodos(ctx, "alice")
+	list = append(list, mustCreate(t, todos, "native"))
+
+	var buf bytes.Buffer
+	formats.WriteICal(&buf, list)
+	parsed, _ := formats.ParseICal(&buf)
+	parsed[1].Title = "renamed"
+
+	third, _ := imports.Import(ctx, "alice", parsed)
+	if third.Created != 0 || third.Updated != 1 || third.Skipped != 1 {
+		t.Errorf("got %+v want 1 updated, 1 skipped", third)
+	}
+}
+
+func mustCreate(t *testing.T, s *TodoService, title string) models.Todo {
+	t.Helper()
+	todo, err := s.CreateTodo(context.Background(), models.CreateTodo{UserID: "alice", Title: title})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return todo
+}
+
+func TestImportReportsPerLineErrors(t *testing.T) {
+	ctx := context.Background()
+	imports := NewImportService(NewTodoService(repositories.NewInMemoryTodoRepo()))
+
+	records, err := formats.ParseCSV(strings.NewReader("title,status\nfine,\n,\n" + strings.Repeat("x", 256) + ",\nbad,NOPE\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := imports.Import(ctx, "alice", records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 1 || report.Failed != 3 {
+		t.Fatalf("got %+v want 1 created, 3 failed", report)
+	}
+	for i, line := range []int{2, 3, 4, 5} {
+		if report.Items[i].Line != line {
+			t.Errorf("item %d: got line %d want %d", i, report.Items[i].Line, line)
+		}
+	}
+}
+
+func TestImportDoesNotAdoptServerUIDs(t *testing.T) {
+	ctx := context.Background()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo())
+	imports := NewImportService(todos)
+
+	bobs, err := todos.CreateTodo(ctx, models.CreateTodo{UserID: "bob", Title: "private"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !formats.IsTodoUID(bobs.UID) || bobs.UID == mustCreate(t, todos, "other").UID {
+		t.Fatalf("got uid %q want a fresh server generated uid per todo", bobs.UID)
+	}
+
+	// alice cannot take over or find bob's todo by importing its UID
+	report, _ := imports.Import(ctx, "alice", []formats.Record{{Line: 1, UID: bobs.UID, Title: "probe", Status: models.StatusPending}})
+	if report.Created != 1 {
+		t.Fatalf("got %+v want a new todo for alice", report)
+	}
+	created, _ := todos.GetTodo(ctx, report.Items[0].ID)
+	if created.UserID != "alice" || created.UID == bobs.UID {
+		t.Errorf("got %+v want alice's own todo under a new uid", created)
+	}
+	if got, _ := todos.GetTodo(ctx, bobs.ID); got.Title != "private" {
+		t.Errorf("got %+v want bob's todo untouched", got)
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+type IProjectService interface {
+	CreateProject(ctx context.Context, dto models.CreateProject) (models.Project, error)
+	GetProject(ctx context.Context, id int) (models.Project, error)
+	ListProjects(ctx context.Context, userID string) ([]models.Project, error)
+	SetWorkflow(ctx context.Context, id int, workflow models.Workflow) (models.Project, error)
+	Board(ctx context.Context, id int) (models.Board, error)
+	// InboxBoard groups a user's todos outside any project by the default workflow
+	InboxBoard(ctx context.Context, userID string) (models.Board, error)
+}
+
+// maxWorkflowStates bounds how many columns a board can have
+const maxWorkflowStates = 50
+
+var stateKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+type ProjectService struct {
+	repo  repositories.ProjectRepository
+	todos ITodoService
+}
+
+func NewProjectService(repo repositories.ProjectRepository, todos ITodoService) *ProjectService {
+	return &ProjectService{
+		repo:  repo,
+		todos: todos,
+	}
+}
+
+// ValidateWorkflow checks a workflow is usable and fills in state names left empty
+func ValidateWorkflow(w models.Workflow) (models.Workflow, error) {
+	if len(w.States) == 0 || len(w.States) > maxWorkflowStates {
+		return models.Workflow{}, ErrInvalidInput
+	}
+
+	states := make([]models.WorkflowState, len(w.States))
+	seen := make(map[string]bool, len(w.States))
+	open := false
+	for i, st := range w.States {
+		if !stateKeyPattern.MatchString(st.Key) || seen[st.Key] || !st.Category.Valid() || len(st.Name) > 64 {
+			return models.Workflow{}, ErrInvalidInput
+		}
+		seen[st.Key] = true
+		open = open || st.Category == models.CategoryOpen
+		if st.Name == "" {
+			st.Name = st.Key
+		}
+		states[i] = st
+	}
+	if !open {
+		return models.Workflow{}, ErrInvalidInput
+	}
+
+	for _, t := range w.Transitions {
+		if !seen[t.From] || !seen[t.To] {
+			return models.Workflow{}, ErrInvalidInput
+		}
+	}
+
+	out := models.Workflow{States: states, Transitions: w.Transitions, Initial: w.Initial}
+	if w.Initial != "" {
+		if st, ok := out.State(w.Initial); !ok || st.Category != models.CategoryOpen {
+			return models.Workflow{}, ErrInvalidInput
+		}
+	}
+	return out, nil
+}
+
+func (s *ProjectService) CreateProject(ctx context.Context, dto models.CreateProject) (models.Project, error) {
+	if dto.UserID == "" || dto.Name == "" || len(dto.Name) > 255 {
+		return models.Project{}, ErrInvalidInput
+	}
+
+	workflow := models.DefaultWorkflow()
+	if dto.Workflow != nil {
+		w, err := ValidateWorkflow(*dto.Workflow)
+		if err != nil {
+			return models.Project{}, err
+		}
+		workflow = w
+	}
+
+	now := time.Now()
+	return s.repo.Create(ctx, models.Project{
+		UserID:    dto.UserID,
+		Name:      dto.Name,
+		Workflow:  workflow,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func (s *ProjectService) GetProject(ctx context.Context, id int) (models.Project, error) {
+	if id <= 0 {
+		return models.Project{}, ErrInvalidInput
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ProjectService) ListProjects(ctx context.Context, userID string) ([]models.Project, error) {
+	if userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// SetWorkflow replaces a project's workflow. It is refused with ErrConflict while any of the
+// project's todos sits in a state the new workflow drops.
+func (s *ProjectService) SetWorkflow(ctx context.Context, id int, workflow models.Workflow) (models.Project, error) {
+	project, err := s.GetProject(ctx, id)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	workflow, err = ValidateWorkflow(workflow)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	todos, err := s.projectTodos(ctx, project)
+	if err != nil {
+		return models.Project{}, err
+	}
+	for _, t := range todos {
+		if _, ok := workflow.State(project.Workflow.StateOf(t).Key); !ok {
+			return models.Project{}, repositories.ErrConflict
+		}
+	}
+
+	project.Workflow = workflow
+	project.UpdatedAt = time.Now()
+	return s.repo.Update(ctx, project)
+}
+
+func (s *ProjectService) Board(ctx context.Context, id int) (models.Board, error) {
+	project, err := s.GetProject(ctx, id)
+	if err != nil {
+		return models.Board{}, err
+	}
+
+	todos, err := s.projectTodos(ctx, project)
+	if err != nil {
+		return models.Board{}, err
+	}
+
+	board := group(project.Workflow, todos)
+	board.Project = &project
+	return board, nil
+}
+
+func (s *ProjectService) InboxBoard(ctx context.Context, userID string) (models.Board, error) {
+	todos, err := s.todos.ListTodos(ctx, userID)
+	if err != nil {
+		return models.Board{}, err
+	}
+
+	inbox := todos[:0:0]
+	for _, t := range todos {
+		if t.ProjectID == 0 {
+			inbox = append(inbox, t)
+		}
+	}
+	return group(models.DefaultWorkflow(), inbox), nil
+}
+
+// projectTodos returns the project's todos in position order. Todos can only be added to
+// projects their owner created, so the owner's list holds them all.
+func (s *ProjectService) projectTodos(ctx context.Context, project models.Project) ([]models.Todo, error) {
+	todos, err := s.todos.ListTodos(ctx, project.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := todos[:0:0]
+	for _, t := range todos {
+		if t.ProjectID == project.ID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// group puts each todo in its state's column, keeping the order todos are given in
+func group(workflow models.Workflow, todos []models.Todo) models.Board {
+	board := models.Board{Columns: make([]models.BoardColumn, len(workflow.States))}
+	index := make(map[string]int, len(workflow.States))
+	for i, st := range workflow.States {
+		board.Columns[i] = models.BoardColumn{State: st, Todos: make([]models.Todo, 0)}
+		index[st.Key] = i
+	}
+
+	for _, t := range todos {
+		i := index[workflow.StateOf(t).Key]
+		board.Columns[i].Todos = append(board.Columns[i].Todos, t)
+	}
+	return board
+}
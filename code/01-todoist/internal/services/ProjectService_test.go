@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func reviewWorkflow() *models.Workflow {
+	return &models.Workflow{
+		States: []models.WorkflowState{
+			{Key: "backlog", Category: models.CategoryOpen},
+			{Key: "doing", Category: models.CategoryOpen},
+			{Key: "review", Category: models.CategoryOpen},
+			{Key: "done", Category: models.CategoryDone},
+			{Key: "dropped", Category: models.CategoryRemoved},
+		},
+		Transitions: []models.Transition{
+			{From: "backlog", To: "doing"},
+			{From: "doing", To: "review"},
+			{From: "review", To: "doing"},
+			{From: "review", To: "done"},
+			{From: "backlog", To: "dropped"},
+		},
+	}
+}
+
+func TestProjectWorkflow(t *testing.T) {
+	ctx := context.Background()
+	projectRepo := repositories.NewInMemoryProjectRepo()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo(), WithProjects(projectRepo))
+	projects := NewProjectService(projectRepo, todos)
+
+	project, err := projects.CreateProject(ctx, models.CreateProject{UserID: "alice", Name: "launch", Workflow: reviewWorkflow()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	todo, err := todos.CreateTodo(ctx, models.CreateTodo{UserID: "alice", Title: "write docs", ProjectID: project.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo.State != "backlog" || todo.Status != models.StatusPending {
+		t.Fatalf("got %s/%s want backlog/PENDING", todo.State, todo.Status)
+	}
+
+	state := func(s string) *string { return &s }
+	completed := models.StatusCompleted
+
+	if _, err := todos.UpdateTodo(ctx, models.UpdateTodo{ID: todo.ID, State: state("review")}); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("got %v want %v for skipping doing", err, ErrInvalidTransition)
+	}
+	if _, err := todos.UpdateTodo(ctx, models.UpdateTodo{ID: todo.ID, State: state("nope")}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("got %v want %v for an unknown state", err, ErrInvalidInput)
+	}
+
+	todos.UpdateTodo(ctx, models.UpdateTodo{ID: todo.ID, State: state("doing")})
+	if _, err := todos.UpdateTodo(ctx, models.UpdateTodo{ID: todo.ID, Status: &completed}); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("got %v want a bare status to respect transitions", err)
+	}
+	todos.UpdateTodo(ctx, models.UpdateTodo{ID: todo.ID, State: state("review")})
+
+	// clients that only know statuses land in the first reachable done state
+	done, err := todos.UpdateTodo(ctx, models.UpdateTodo{ID: todo.ID, Status: &completed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.State != "done" || done.Status != models.StatusCompleted {
+		t.Errorf("got %s/%s want done/COMPLETED", done.State, done.Status)
+	}
+
+	mustCreate(t, todos, "outside any project")
+	todos.CreateTodo(ctx, models.CreateTodo{UserID: "alice", Title: "tests", ProjectID: project.ID})
+
+	board, err := projects.Board(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int{}
+	for _, col := range board.Columns {
+		counts[col.State.Key] = len(col.Todos)
+	}
+	if len(board.Columns) != 5 || counts["backlog"] != 1 || counts["done"] != 1 || counts["doing"] != 0 {
+		t.Errorf("got columns %v want one todo each in backlog and done", counts)
+	}
+
+	t.Run("workflow changes keep todos placed", func(t *testing.T) {
+		shrunk := reviewWorkflow()
+		shrunk.States = shrunk.States[:3]
+		shrunk.Transitions = nil
+		if _, err := projects.SetWorkflow(ctx, project.ID, *shrunk); !errors.Is(err, repositories.ErrConflict) {
+			t.Errorf("got %v want %v while a todo is in done", err, repositories.ErrConflict)
+		}
+
+		open := reviewWorkflow()
+		open.Transitions = nil
+		if _, err := projects.SetWorkflow(ctx, project.ID, *open); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := todos.UpdateTodo(ctx, models.UpdateTodo{ID: todo.ID, State: state("backlog")}); err != nil {
+			t.Errorf("got %v want any move allowed without transitions", err)
+		}
+	})
+
+	t.Run("todos only join their owner's projects", func(t *testing.T) {
+		_, err := todos.CreateTodo(ctx, models.CreateTodo{UserID: "bob", Title: "sneaky", ProjectID: project.ID})
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("got %v want %v", err, ErrInvalidInput)
+		}
+	})
+}
+
+func TestDefaultWorkflowKeepsStatuses(t *testing.T) {
+	ctx := context.Background()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo())
+	projects := NewProjectService(repositories.NewInMemoryProjectRepo(), todos)
+
+	todo := mustCreate(t, todos, "plain")
+	for _, status := range []models.TodoStatus{models.StatusCompleted, models.StatusPending, models.StatusTrashed} {
+		updated, err := todos.UpdateTodo(ctx, models.UpdateTodo{ID: todo.ID, Status: &status})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Status != status || updated.State != "" {
+			t.Errorf("got %s/%q want %s with no state stored", updated.Status, updated.State, status)
+		}
+	}
+
+	board, err := projects.InboxBoard(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(board.Columns) != 3 || len(board.Columns[2].Todos) != 1 || board.Columns[2].State.Key != string(models.StatusTrashed) {
+		t.Errorf("got %+v want the trashed todo in the TRASHED column", board.Columns)
+	}
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(w *models.Workflow)
+	}{
+		{"duplicate key", func(w *models.Workflow) { w.States[1].Key = "backlog" }},
+		{"bad key", func(w *models.Workflow) { w.States[0].Key = "has space" }},
+		{"bad category", func(w *models.Workflow) { w.States[0].Category = "blocked" }},
+		{"no open state", func(w *models.Workflow) { w.States = w.States[3:] }},
+		{"unknown transition", func(w *models.Workflow) { w.Transitions[0].To = "shipped" }},
+		{"initial not open", func(w *models.Workflow) { w.Initial = "done" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := reviewWorkflow()
+			tt.mutate(w)
+			if _, err := ValidateWorkflow(*w); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("got %v want %v", err, ErrInvalidInput)
+			}
+		})
+	}
+
+	w, err := ValidateWorkflow(*reviewWorkflow())
+	if err != nil || w.States[0].Name != "backlog" {
+		t.Errorf("got %v, %q want the key used as name", err, w.States[0].Name)
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/quickadd"
+)
+
+// maxQuickAddLength bounds the text a quick-add request may carry
+const maxQuickAddLength = 1000
+
+type IQuickAddService interface {
+	QuickAdd(ctx context.Context, dto models.QuickAdd) (QuickAddResult, error)
+}
+
+// QuickAddResult pairs the created todo with what the parser understood, so a UI can show it
+type QuickAddResult struct {
+	Todo   models.Todo     `json:"todo"`
+	Parsed quickadd.Result `json:"parsed"`
+}
+
+type QuickAddService struct {
+	todos ITodoService
+	now   func() time.Time
+}
+
+func NewQuickAddService(todos ITodoService) *QuickAddService {
+	return &QuickAddService{
+		todos: todos,
+		now:   time.Now,
+	}
+}
+
+// QuickAdd parses the text in the user's time zone and creates the todo through the todo service
+func (s *QuickAddService) QuickAdd(ctx context.Context, dto models.QuickAdd) (QuickAddResult, error) {
+	if dto.Text == "" || len(dto.Text) > maxQuickAddLength {
+		return QuickAddResult{}, ErrInvalidInput
+	}
+
+	loc, err := time.LoadLocation(dto.TimeZone)
+	if err != nil {
+		return QuickAddResult{}, ErrInvalidInput
+	}
+
+	parsed := quickadd.Parse(dto.Text, s.now(), loc)
+
+	create := models.CreateTodo{
+		UserID:   dto.UserID,
+		Title:    parsed.Title,
+		DueAt:    parsed.Due,
+		Labels:   parsed.Labels,
+		Priority: parsed.Priority,
+	}
+	if parsed.Recurrence != nil {
+		create.Recurrence = parsed.Recurrence.Rule()
+	}
+
+	todo, err := s.todos.CreateTodo(ctx, create)
+	if err != nil {
+		return QuickAddResult{}, err
+	}
+
+	return QuickAddResult{Todo: todo, Parsed: parsed}, nil
+}
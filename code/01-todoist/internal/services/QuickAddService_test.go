@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func TestQuickAdd(t *testing.T) {
+	ctx := context.Background()
+	quick := NewQuickAddService(NewTodoService(repositories.NewInMemoryTodoRepo()))
+	quick.now = func() time.Time { return time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC) }
+
+	got, err := quick.QuickAdd(ctx, models.QuickAdd{
+		UserID:   "alice",
+		Text:     "Pay rent every month on the 1st at 9am #finance !high",
+		TimeZone: "America/New_York",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	todo := got.Todo
+	if todo.Title != "Pay rent" || todo.Priority != models.PriorityHigh || !slices.Equal(todo.Labels, []string{"finance"}) {
+		t.Errorf("got %+v", todo)
+	}
+	if todo.Recurrence != "FREQ=MONTHLY;BYMONTHDAY=1" {
+		t.Errorf("got recurrence %q", todo.Recurrence)
+	}
+	// 9am in New York on April 1st is 13:00 UTC
+	if want := time.Date(2026, 4, 1, 13, 0, 0, 0, time.UTC); todo.DueAt == nil || !todo.DueAt.Equal(want) {
+		t.Errorf("got due %v want %v", todo.DueAt, want)
+	}
+	if len(got.Parsed.Parts) == 0 {
+		t.Error("got no parse breakdown")
+	}
+
+	for _, dto := range []models.QuickAdd{
+		{UserID: "alice", Text: "tomorrow at 9am #only-metadata"},
+		{UserID: "alice", Text: "call mom", TimeZone: "Mars/Olympus_Mons"},
+	} {
+		if _, err := quick.QuickAdd(ctx, dto); err != ErrInvalidInput {
+			t.Errorf("got %v want %v for %+v", err, ErrInvalidInput, dto)
+		}
+	}
+}
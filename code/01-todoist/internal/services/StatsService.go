@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/models"
+)
+
+type IStatsService interface {
+	Stats(ctx context.Context, q models.StatsQuery) (models.Stats, error)
+}
+
+// userStats is kept per user and updated by every event. Activity is counted in quarter-hour
+// buckets so that day boundaries can be drawn in whatever time zone a query asks for, including
+// those offset from UTC by :30 or :45.
+type userStats struct {
+	created   map[int64]int
+	completed map[int64]int
+	// completedAt remembers when each currently completed todo was completed so reopening it can be undone exactly
+	completedAt map[int]time.Time
+	byStatus    map[models.TodoStatus]int
+	durationSum time.Duration
+	durationN   int
+}
+
+// StatsService derives productivity statistics from the event bus instead of scanning todos
+type StatsService struct {
+	mu    sync.Mutex
+	users map[string]*userStats
+	now   func() time.Time
+}
+
+func NewStatsService() *StatsService {
+	return &StatsService{
+		users: make(map[string]*userStats),
+		now:   time.Now,
+	}
+}
+
+// bucketSize divides every UTC offset in use, so no bucket straddles midnight in any zone
+const bucketSize = 15 * 60
+
+func bucketOf(t time.Time) int64 {
+	return t.Unix() / bucketSize
+}
+
+func (s *StatsService) user(id string) *userStats {
+	u, ok := s.users[id]
+	if !ok {
+		u = &userStats{
+			created:     make(map[int64]int),
+			completed:   make(map[int64]int),
+			completedAt: make(map[int]time.Time),
+			byStatus:    make(map[models.TodoStatus]int),
+		}
+		s.users[id] = u
+	}
+	return u
+}
+
+// HandleEvent is registered with events.Bus.Listen
+func (s *StatsService) HandleEvent(e events.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.user(e.UserID)
+	t := e.Todo
+
+	switch e.Type {
+	case events.TodoCreated:
+		u.created[bucketOf(t.CreatedAt)]++
+		u.byStatus[t.Status]++
+		if t.Status == models.StatusCompleted {
+			u.complete(t, e.OccurredAt)
+		}
+	case events.TodoUpdated:
+		if e.Previous == nil || e.Previous.Status == t.Status {
+			return
+		}
+		u.move(e.Previous.Status, t.Status)
+		if e.Completed() {
+			u.complete(t, e.OccurredAt)
+		} else if e.Previous.Status == models.StatusCompleted {
+			u.reopen(t)
+		}
+	case events.TodoDeleted:
+		u.move(t.Status, "")
+		// the completion still happened; it stays in the history but is no longer reopenable
+		delete(u.completedAt, t.ID)
+	}
+}
+
+// Rebuild recounts everything from todos, as after a backup is restored. Stored todos do not
+// say when they were completed, so completions are dated by each todo's last update.
+func (s *StatsService) Rebuild(todos []models.Todo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = make(map[string]*userStats)
+	for _, t := range todos {
+		u := s.user(t.UserID)
+		u.created[bucketOf(t.CreatedAt)]++
+		u.byStatus[t.Status]++
+		if t.Status == models.StatusCompleted {
+			u.complete(t, t.UpdatedAt)
+		}
+	}
+}
+
+func (u *userStats) move(from, to models.TodoStatus) {
+	if from != "" {
+		if u.byStatus[from]--; u.byStatus[from] <= 0 {
+			delete(u.byStatus, from)
+		}
+	}
+	if to != "" {
+		u.byStatus[to]++
+	}
+}
+
+func (u *userStats) complete(t models.Todo, at time.Time) {
+	u.completed[bucketOf(at)]++
+	u.completedAt[t.ID] = at
+	u.durationSum += at.Sub(t.CreatedAt)
+	u.durationN++
+}
+
+func (u *userStats) reopen(t models.Todo) {
+	at, ok := u.completedAt[t.ID]
+	if !ok {
+		return
+	}
+	delete(u.completedAt, t.ID)
+
+	b := bucketOf(at)
+	if u.completed[b]--; u.completed[b] <= 0 {
+		delete(u.completed, b)
+	}
+	u.durationSum -= at.Sub(t.CreatedAt)
+	u.durationN--
+}
+
+func (s *StatsService) Stats(ctx context.Context, q models.StatsQuery) (models.Stats, error) {
+	if q.UserID == "" || q.Days < 0 || q.Weeks < 0 || q.Months < 0 || q.Days > 366 || q.Weeks > 104 || q.Months > 120 {
+		return models.Stats{}, ErrInvalidInput
+	}
+	if err := ctx.Err(); err != nil {
+		return models.Stats{}, err
+	}
+
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	now := s.now().In(loc)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[q.UserID]
+	if !ok {
+		u = &userStats{}
+	}
+
+	daily := periods(now, q.Days, func(t time.Time, i int) time.Time { return t.AddDate(0, 0, -i) }, dayKey)
+	weekly := periods(now, q.Weeks, func(t time.Time, i int) time.Time { return t.AddDate(0, 0, -7*i) }, weekKey)
+	monthly := periods(now, q.Months, func(t time.Time, i int) time.Time {
+		first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return first.AddDate(0, -i, 0)
+	}, monthKey)
+
+	completionDays := make(map[string]bool)
+	count := func(buckets map[int64]int, add func(*models.PeriodCount, int)) {
+		for b, n := range buckets {
+			t := time.Unix(b*bucketSize, 0).In(loc)
+			if p, ok := daily.index[dayKey(t)]; ok {
+				add(&daily.counts[p], n)
+			}
+			if p, ok := weekly.index[weekKey(t)]; ok {
+				add(&weekly.counts[p], n)
+			}
+			if p, ok := monthly.index[monthKey(t)]; ok {
+				add(&monthly.counts[p], n)
+			}
+		}
+	}
+	count(u.created, func(p *models.PeriodCount, n int) { p.Created += n })
+	count(u.completed, func(p *models.PeriodCount, n int) { p.Completed += n })
+
+	for b := range u.completed {
+		completionDays[dayKey(time.Unix(b*bucketSize, 0).In(loc))] = true
+	}
+	current, longest := streaks(completionDays, now)
+
+	stats := models.Stats{
+		TimeZone:      loc.String(),
+		Daily:         daily.counts,
+		Weekly:        weekly.counts,
+		Monthly:       monthly.counts,
+		CurrentStreak: current,
+		LongestStreak: longest,
+		ByStatus: map[models.TodoStatus]int{
+			models.StatusPending:   0,
+			models.StatusCompleted: 0,
+			models.StatusTrashed:   0,
+		},
+	}
+	for status, n := range u.byStatus {
+		stats.ByStatus[status] = n
+	}
+	if u.durationN > 0 {
+		avg := u.durationSum / time.Duration(u.durationN)
+		stats.AverageCompletion = avg.Round(time.Second).String()
+		stats.AverageCompletionSeconds = avg.Seconds()
+	}
+
+	return stats, nil
+}
+
+type periodTable struct {
+	counts []models.PeriodCount
+	index  map[string]int
+}
+
+// periods lays out the last n periods, oldest first, ending with the one containing now
+func periods(now time.Time, n int, back func(time.Time, int) time.Time, key func(time.Time) string) periodTable {
+	table := periodTable{counts: make([]models.PeriodCount, n), index: make(map[string]int, n)}
+	for i := range n {
+		k := key(back(now, n-1-i))
+		table.counts[i].Period = k
+		table.index[k] = i
+	}
+	return table
+}
+
+func dayKey(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+func weekKey(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func monthKey(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// streaks counts runs of consecutive days with at least one completion. The current streak
+// is still alive today if the user completed something yesterday but nothing yet today.
+func streaks(days map[string]bool, now time.Time) (current, longest int) {
+	if len(days) == 0 {
+		return 0, 0
+	}
+
+	sorted := make([]time.Time, 0, len(days))
+	for d := range days {
+		t, _ := time.ParseInLocation(time.DateOnly, d, time.UTC)
+		sorted = append(sorted, t)
+	}
+	slices.SortFunc(sorted, func(a, b time.Time) int { return a.Compare(b) })
+
+	run := 0
+	for i, d := range sorted {
+		if i > 0 && d.Sub(sorted[i-1]) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	day, _ := time.ParseInLocation(time.DateOnly, dayKey(now), time.UTC)
+	if !days[dayKey(day)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[dayKey(day)] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, longest
+}
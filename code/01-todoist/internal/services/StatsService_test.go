@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/models"
+)
+
+func TestStatsFromEvents(t *testing.T) {
+	ctx := context.Background()
+	stats := NewStatsService()
+	now := time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC)
+	stats.now = func() time.Time { return now }
+
+	day := func(offset int, hour int) time.Time {
+		return time.Date(2026, 3, 10+offset, hour, 0, 0, 0, time.UTC)
+	}
+
+	id := 0
+	create := func(at time.Time) models.Todo {
+		id++
+		todo := models.Todo{ID: id, UserID: "alice", Status: models.StatusPending, CreatedAt: at}
+		stats.HandleEvent(events.Event{Type: events.TodoCreated, UserID: "alice", Todo: todo, OccurredAt: at})
+		return todo
+	}
+	setStatus := func(todo models.Todo, status models.TodoStatus, at time.Time) models.Todo {
+		prev := todo
+		todo.Status = status
+		stats.HandleEvent(events.Event{Type: events.TodoUpdated, UserID: "alice", Todo: todo, Previous: &prev, OccurredAt: at})
+		return todo
+	}
+
+	// completions on days -6, -5, -4 (a streak of 3), then -1 and today (a current streak of 2)
+	for _, offset := range []int{-6, -5, -4, -1, 0} {
+		todo := create(day(offset, 8))
+		setStatus(todo, models.StatusCompleted, day(offset, 10))
+	}
+
+	// reopened, so it must not count as completed
+	reopened := create(day(0, 9))
+	reopened = setStatus(reopened, models.StatusCompleted, day(0, 11))
+	setStatus(reopened, models.StatusPending, day(0, 12))
+
+	trashed := create(day(-2, 9))
+	trashed = setStatus(trashed, models.StatusTrashed, day(-2, 10))
+	stats.HandleEvent(events.Event{Type: events.TodoDeleted, UserID: "alice", Todo: trashed, Previous: &trashed})
+
+	got, err := stats.Stats(ctx, models.StatsQuery{UserID: "alice", Days: 7, Weeks: 2, Months: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.CurrentStreak != 2 || got.LongestStreak != 3 {
+		t.Errorf("got streaks %d/%d want 2/3", got.CurrentStreak, got.LongestStreak)
+	}
+	if got.AverageCompletion != "2h0m0s" {
+		t.Errorf("got average %s want 2h0m0s", got.AverageCompletion)
+	}
+
+	today := got.Daily[len(got.Daily)-1]
+	if today.Period != "2026-03-10" || today.Created != 2 || today.Completed != 1 {
+		t.Errorf("got today %+v want 2 created, 1 completed", today)
+	}
+	if len(got.Daily) != 7 || got.Daily[0].Period != "2026-03-04" {
+		t.Errorf("got daily %+v want the 7 days ending today", got.Daily)
+	}
+	if m := got.Monthly[0]; m.Period != "2026-03" || m.Created != 7 || m.Completed != 5 {
+		t.Errorf("got month %+v want 7 created, 5 completed", m)
+	}
+
+	want := map[models.TodoStatus]int{models.StatusPending: 1, models.StatusCompleted: 5, models.StatusTrashed: 0}
+	for status, n := range want {
+		if got.ByStatus[status] != n {
+			t.Errorf("got %d %s want %d", got.ByStatus[status], status, n)
+		}
+	}
+
+	t.Run("day boundaries follow the time zone", func(t *testing.T) {
+		// today's 10:00 UTC completion was still yesterday evening in Pago Pago
+		got, _ := stats.Stats(ctx, models.StatsQuery{UserID: "alice", Location: time.FixedZone("SST", -11*60*60), Days: 2})
+		if got.Daily[0].Period != "2026-03-09" || got.Daily[0].Completed != 1 || got.Daily[1].Completed != 0 {
+			t.Errorf("got %+v", got.Daily)
+		}
+	})
+}
+
+func TestStatsHalfHourZone(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+
+	stats := NewStatsService()
+	stats.now = func() time.Time { return time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC) }
+
+	// 23:45 and 00:15 in Kolkata: the same UTC hour, but different days
+	for i, at := range []time.Time{
+		time.Date(2026, 3, 10, 18, 15, 0, 0, time.UTC),
+		time.Date(2026, 3, 10, 18, 45, 0, 0, time.UTC),
+	} {
+		todo := models.Todo{ID: i + 1, UserID: "alice", Status: models.StatusPending, CreatedAt: at}
+		stats.HandleEvent(events.Event{Type: events.TodoCreated, UserID: "alice", Todo: todo, OccurredAt: at})
+		prev := todo
+		todo.Status = models.StatusCompleted
+		stats.HandleEvent(events.Event{Type: events.TodoUpdated, UserID: "alice", Todo: todo, Previous: &prev, OccurredAt: at})
+	}
+
+	got, err := stats.Stats(context.Background(), models.StatsQuery{UserID: "alice", Location: kolkata, Days: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []models.PeriodCount{
+		{Period: "2026-03-10", Created: 1, Completed: 1},
+		{Period: "2026-03-11", Created: 1, Completed: 1},
+	}
+	for i, w := range want {
+		if got.Daily[i] != w {
+			t.Errorf("got %+v want %+v", got.Daily[i], w)
+		}
+	}
+	if got.CurrentStreak != 2 {
+		t.Errorf("got current streak %d want 2", got.CurrentStreak)
+	}
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+type ISyncService interface {
+	Sync(ctx context.Context, req models.SyncRequest) (models.SyncResponse, error)
+}
+
+type SyncService struct {
+	todos ITodoService
+	log   repositories.ChangeLog
+}
+
+func NewSyncService(todos ITodoService, log repositories.ChangeLog) *SyncService {
+	return &SyncService{
+		todos: todos,
+		log:   log,
+	}
+}
+
+// Sync applies the client's queued mutations and then returns everything that
+// changed for the user since the client's token, including the client's own writes.
+func (s *SyncService) Sync(ctx context.Context, req models.SyncRequest) (models.SyncResponse, error) {
+	if req.UserID == "" {
+		return models.SyncResponse{}, ErrInvalidInput
+	}
+
+	since, err := decodeSyncToken(req.Token)
+	if err != nil {
+		return models.SyncResponse{}, ErrInvalidInput
+	}
+
+	switch req.Strategy {
+	case "":
+		req.Strategy = models.LastWriterWins
+	case models.LastWriterWins, models.FieldMerge:
+	default:
+		return models.SyncResponse{}, ErrInvalidInput
+	}
+
+	resp := models.SyncResponse{
+		Changed: make([]models.Todo, 0),
+		Deleted: make([]models.Tombstone, 0),
+		Results: make([]models.MutationResult, 0, len(req.Mutations)),
+	}
+
+	for _, m := range req.Mutations {
+		resp.Results = append(resp.Results, s.apply(ctx, req.UserID, req.Strategy, m))
+	}
+
+	changes, head, err := s.log.ChangesSince(ctx, req.UserID, since)
+	if err != nil {
+		return models.SyncResponse{}, err
+	}
+
+	for _, c := range changes {
+		switch c.Op {
+		case repositories.OpUpsert:
+			resp.Changed = append(resp.Changed, c.Todo)
+		case repositories.OpDelete:
+			resp.Deleted = append(resp.Deleted, models.Tombstone{ID: c.Todo.ID, DeletedAt: c.At})
+		}
+	}
+	resp.Token = encodeSyncToken(head)
+
+	return resp, nil
+}
+
+func (s *SyncService) apply(ctx context.Context, userID string, strategy models.ConflictStrategy, m models.SyncMutation) models.MutationResult {
+	res := models.MutationResult{ClientRef: m.ClientRef, ID: m.ID}
+
+	reject := func(status models.MutationStatus, err error) models.MutationResult {
+		res.Status = status
+		res.Error = err.Error()
+		return res
+	}
+
+	if m.Op == models.MutationCreate {
+		dto := models.CreateTodo{UserID: userID}
+		if m.Fields.Title != nil {
+			dto.Title = *m.Fields.Title
+		}
+		if m.Fields.Description != nil {
+			dto.Description = *m.Fields.Description
+		}
+		// in the same call, so a status the server refuses leaves no todo behind
+		if m.Fields.Status != nil {
+			dto.Status = *m.Fields.Status
+		}
+
+		created, err := s.todos.CreateTodo(ctx, dto)
+		if err != nil {
+			return reject(models.MutationRejected, err)
+		}
+		res.ID = created.ID
+		res.Status = models.MutationApplied
+		return res
+	}
+
+	current, err := s.todos.GetTodo(ctx, m.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		if m.Op == models.MutationDelete {
+			// already gone, deletes are idempotent
+			res.Status = models.MutationApplied
+			return res
+		}
+		return reject(models.MutationConflict, err)
+	}
+	if err != nil {
+		return reject(models.MutationRejected, err)
+	}
+	if current.UserID != userID {
+		return reject(models.MutationRejected, repositories.ErrNotFound)
+	}
+
+	serverChanged := current.UpdatedAt.After(m.BaseUpdatedAt)
+	clientNewer := m.ModifiedAt.After(current.UpdatedAt)
+
+	switch m.Op {
+	case models.MutationDelete:
+		// under field merge a concurrent server edit keeps the todo alive
+		if serverChanged && (strategy == models.FieldMerge || !clientNewer) {
+			return reject(models.MutationConflict, errors.New("todo changed on server"))
+		}
+		if err := s.todos.DeleteTodo(ctx, m.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			return reject(models.MutationRejected, err)
+		}
+
+	case models.MutationUpdate:
+		fields := m.Fields
+		if serverChanged {
+			switch strategy {
+			case models.LastWriterWins:
+				if !clientNewer {
+					return reject(models.MutationConflict, errors.New("server has a newer version"))
+				}
+			case models.FieldMerge:
+				fields = mergeFields(current, m, clientNewer)
+			}
+		}
+
+		dto := models.UpdateTodo{
+			ID:          m.ID,
+			Title:       fields.Title,
+			Description: fields.Description,
+			Status:      fields.Status,
+		}
+		if dto.Title == nil && dto.Description == nil && dto.Status == nil {
+			return reject(models.MutationConflict, errors.New("all fields changed on server"))
+		}
+		if _, err := s.todos.UpdateTodo(ctx, dto); err != nil {
+			return reject(models.MutationRejected, err)
+		}
+
+	default:
+		return reject(models.MutationRejected, ErrInvalidInput)
+	}
+
+	res.Status = models.MutationApplied
+	return res
+}
+
+// mergeFields keeps the client's value for every field the server left untouched since the
+// client's base. Fields changed on both sides go to whichever write is newer.
+func mergeFields(current models.Todo, m models.SyncMutation, clientNewer bool) models.TodoFields {
+	base := models.TodoFields{}
+	if m.Base != nil {
+		base = *m.Base
+	}
+
+	keep := func(serverValue string, baseValue *string) bool {
+		serverUntouched := baseValue != nil && *baseValue == serverValue
+		return serverUntouched || clientNewer
+	}
+
+	merged := models.TodoFields{}
+	if m.Fields.Title != nil && keep(current.Title, base.Title) {
+		merged.Title = m.Fields.Title
+	}
+	if m.Fields.Description != nil && keep(current.Description, base.Description) {
+		merged.Description = m.Fields.Description
+	}
+	if m.Fields.Status != nil {
+		var baseStatus *string
+		if base.Status != nil {
+			v := string(*base.Status)
+			baseStatus = &v
+		}
+		if keep(string(current.Status), baseStatus) {
+			merged.Status = m.Fields.Status
+		}
+	}
+
+	return merged
+}
+
+const syncTokenPrefix = "v1:"
+
+func encodeSyncToken(seq uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(syncTokenPrefix + strconv.FormatUint(seq, 10)))
+}
+
+func decodeSyncToken(token string) (uint64, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+
+	v, ok := strings.CutPrefix(string(raw), syncTokenPrefix)
+	if !ok {
+		return 0, fmt.Errorf("unknown sync token version")
+	}
+
+	return strconv.ParseUint(v, 10, 64)
+}
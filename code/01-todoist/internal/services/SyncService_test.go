@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func newSyncFixture() (*TodoService, *SyncService) {
+	repo := repositories.NewInMemoryTodoRepo()
+	todos := NewTodoService(repo)
+	return todos, NewSyncService(todos, repo)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSyncDeltas(t *testing.T) {
+	ctx := context.Background()
+	todos, sync := newSyncFixture()
+
+	first, err := sync.Sync(ctx, models.SyncRequest{UserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept, _ := todos.CreateTodo(ctx, models.CreateTodo{UserID: "alice", Title: "keep"})
+	gone, _ := todos.CreateTodo(ctx, models.CreateTodo{UserID: "alice", Title: "gone"})
+	todos.CreateTodo(ctx, models.CreateTodo{UserID: "bob", Title: "not mine"})
+	if err := todos.DeleteTodo(ctx, gone.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := sync.Sync(ctx, models.SyncRequest{UserID: "alice", Token: first.Token})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(second.Changed) != 1 || second.Changed[0].ID != kept.ID {
+		t.Errorf("got changed %+v want only todo %d", second.Changed, kept.ID)
+	}
+	if len(second.Deleted) != 1 || second.Deleted[0].ID != gone.ID {
+		t.Errorf("got deleted %+v want tombstone for %d", second.Deleted, gone.ID)
+	}
+
+	third, _ := sync.Sync(ctx, models.SyncRequest{UserID: "alice", Token: second.Token})
+	if len(third.Changed)+len(third.Deleted) != 0 {
+		t.Errorf("got %+v want no changes after latest token", third)
+	}
+
+	if _, err := sync.Sync(ctx, models.SyncRequest{UserID: "alice", Token: "garbage!"}); err != ErrInvalidInput {
+		t.Errorf("got %v want %v", err, ErrInvalidInput)
+	}
+}
+
+func TestSyncConflicts(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*TodoService, *SyncService, models.Todo, time.Time) {
+		t.Helper()
+		todos, sync := newSyncFixture()
+		todo, _ := todos.CreateTodo(ctx, models.CreateTodo{UserID: "alice", Title: "old", Description: "old"})
+		base := todo.UpdatedAt
+
+		// server edits the description after the client last synced
+		time.Sleep(time.Millisecond)
+		todos.UpdateTodo(ctx, models.UpdateTodo{ID: todo.ID, Description: ptr("server")})
+		return todos, sync, todo, base
+	}
+
+	t.Run("last writer wins rejects stale client edits", func(t *testing.T) {
+		todos, sync, todo, base := setup(t)
+
+		resp, _ := sync.Sync(ctx, models.SyncRequest{
+			UserID:   "alice",
+			Strategy: models.LastWriterWins,
+			Mutations: []models.SyncMutation{{
+				Op: models.MutationUpdate, ID: todo.ID,
+				Fields:        models.TodoFields{Title: ptr("client")},
+				BaseUpdatedAt: base,
+				ModifiedAt:    base,
+			}},
+		})
+
+		if got := resp.Results[0].Status; got != models.MutationConflict {
+			t.Errorf("got %q want %q", got, models.MutationConflict)
+		}
+		got, _ := todos.GetTodo(ctx, todo.ID)
+		if got.Title != "old" {
+			t.Errorf("got title %q want old", got.Title)
+		}
+	})
+
+	t.Run("field merge keeps both sides' edits", func(t *testing.T) {
+		todos, sync, todo, base := setup(t)
+
+		resp, _ := sync.Sync(ctx, models.SyncRequest{
+			UserID:   "alice",
+			Strategy: models.FieldMerge,
+			Mutations: []models.SyncMutation{{
+				Op: models.MutationUpdate, ID: todo.ID,
+				Fields:        models.TodoFields{Title: ptr("client")},
+				Base:          &models.TodoFields{Title: ptr("old")},
+				BaseUpdatedAt: base,
+				ModifiedAt:    base,
+			}},
+		})
+
+		if got := resp.Results[0].Status; got != models.MutationApplied {
+			t.Fatalf("got %q want %q", got, models.MutationApplied)
+		}
+		got, _ := todos.GetTodo(ctx, todo.ID)
+		if got.Title != "client" || got.Description != "server" {
+			t.Errorf("got %q/%q want client/server", got.Title, got.Description)
+		}
+	})
+}
+
+func TestSyncCreate(t *testing.T) {
+	ctx := context.Background()
+	todos, sync := newSyncFixture()
+
+	resp, err := sync.Sync(ctx, models.SyncRequest{
+		UserID: "alice",
+		Mutations: []models.SyncMutation{
+			{ClientRef: "done", Op: models.MutationCreate, Fields: models.TodoFields{Title: ptr("done offline"), Status: ptr(models.StatusCompleted)}},
+			{ClientRef: "bad", Op: models.MutationCreate, Fields: models.TodoFields{Title: ptr("bad status"), Status: ptr(models.TodoStatus("LOST"))}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resp.Results[0]; got.Status != models.MutationApplied || got.ID == 0 {
+		t.Errorf("got %+v want the completed todo created", got)
+	}
+	if got := resp.Results[1]; got.Status != models.MutationRejected {
+		t.Errorf("got %+v want the invalid status rejected", got)
+	}
+
+	// a rejected create leaves nothing the next pull would return
+	list, _ := todos.ListTodos(ctx, "alice")
+	if len(list) != 1 || list[0].Title != "done offline" || list[0].Status != models.StatusCompleted {
+		t.Errorf("got %+v want only the completed todo", list)
+	}
+	if len(resp.Changed) != 1 {
+		t.Errorf("got changed %+v want only the applied create", resp.Changed)
+	}
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+type ITimeService interface {
+	StartTimer(ctx context.Context, dto models.StartTimer) (models.TimeEntry, error)
+	// StopTimer stops the user's running timer, which must be on the given todo
+	StopTimer(ctx context.Context, todoID int, userID string) (models.TimeEntry, error)
+	AddEntry(ctx context.Context, dto models.CreateTimeEntry) (models.TimeEntry, error)
+	DeleteEntry(ctx context.Context, todoID int, userID string, id int) error
+	// TodoTime sums the user's entries on one of their todos
+	TodoTime(ctx context.Context, todoID int, userID string) (models.TodoTime, error)
+	Totals(ctx context.Context, q models.TimeQuery) (models.TimeTotals, error)
+	Timesheet(ctx context.Context, q models.TimeQuery) ([]models.TimesheetRow, error)
+}
+
+const (
+	// maxEntryDuration rejects manual entries that are almost certainly typos
+	maxEntryDuration = 24 * time.Hour
+	maxNoteLength    = 1000
+	// maxTimePeriods bounds how many periods one totals query can lay out
+	maxTimePeriods = 400
+)
+
+type TimeService struct {
+	repo  repositories.TimeEntryRepository
+	todos ITodoService
+	now   func() time.Time
+}
+
+func NewTimeService(repo repositories.TimeEntryRepository, todos ITodoService) *TimeService {
+	return &TimeService{
+		repo:  repo,
+		todos: todos,
+		now:   time.Now,
+	}
+}
+
+// StartTimer fails with ErrConflict while the user has a timer running, on this todo or any other
+func (s *TimeService) StartTimer(ctx context.Context, dto models.StartTimer) (models.TimeEntry, error) {
+	if dto.UserID == "" || len(dto.Note) > maxNoteLength {
+		return models.TimeEntry{}, ErrInvalidInput
+	}
+	if _, err := ownedTodo(ctx, s.todos, dto.TodoID, dto.UserID); err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	now := s.now().Truncate(time.Second)
+	return s.repo.Create(ctx, models.TimeEntry{
+		TodoID:    dto.TodoID,
+		UserID:    dto.UserID,
+		Start:     now,
+		Note:      dto.Note,
+		CreatedAt: now,
+	})
+}
+
+func (s *TimeService) StopTimer(ctx context.Context, todoID int, userID string) (models.TimeEntry, error) {
+	if userID == "" {
+		return models.TimeEntry{}, ErrInvalidInput
+	}
+
+	running, err := s.repo.Running(ctx, userID)
+	if err != nil {
+		return models.TimeEntry{}, err
+	}
+	if running.TodoID != todoID {
+		return models.TimeEntry{}, repositories.ErrNotFound
+	}
+
+	// a timer stopped within the second it started still ends after it began
+	end := later(s.now().Truncate(time.Second), running.Start.Add(time.Second))
+	running.End = &end
+	return s.repo.Update(ctx, running)
+}
+
+// AddEntry records time worked without a timer. Entries must end after they start, not lie in
+// the future, and not overlap the user's other entries.
+func (s *TimeService) AddEntry(ctx context.Context, dto models.CreateTimeEntry) (models.TimeEntry, error) {
+	if dto.UserID == "" || len(dto.Note) > maxNoteLength || dto.Start.IsZero() || dto.End.IsZero() {
+		return models.TimeEntry{}, ErrInvalidInput
+	}
+
+	start, end := dto.Start.Truncate(time.Second), dto.End.Truncate(time.Second)
+	if !end.After(start) || end.Sub(start) > maxEntryDuration || end.After(s.now()) {
+		return models.TimeEntry{}, ErrInvalidInput
+	}
+
+	if _, err := ownedTodo(ctx, s.todos, dto.TodoID, dto.UserID); err != nil {
+		return models.TimeEntry{}, err
+	}
+
+	return s.repo.Create(ctx, models.TimeEntry{
+		TodoID:    dto.TodoID,
+		UserID:    dto.UserID,
+		Start:     start,
+		End:       &end,
+		Note:      dto.Note,
+		CreatedAt: s.now(),
+	})
+}
+
+// DeleteEntry removes one of the user's own entries; other users' entries look missing
+func (s *TimeService) DeleteEntry(ctx context.Context, todoID int, userID string, id int) error {
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if e.TodoID != todoID || e.UserID != userID {
+		return repositories.ErrNotFound
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *TimeService) TodoTime(ctx context.Context, todoID int, userID string) (models.TodoTime, error) {
+	if _, err := ownedTodo(ctx, s.todos, todoID, userID); err != nil {
+		return models.TodoTime{}, err
+	}
+
+	entries, err := s.repo.ListByTodo(ctx, todoID)
+	if err != nil {
+		return models.TodoTime{}, err
+	}
+	// only the user's own entries, and so their own notes, are shown
+	entries = slices.DeleteFunc(entries, func(e models.TimeEntry) bool { return e.UserID != userID })
+
+	now := s.now()
+	out := models.TodoTime{TodoID: todoID, Entries: entries}
+	for _, e := range entries {
+		out.Seconds += int64(e.Duration(now) / time.Second)
+		out.Running = out.Running || e.Running()
+	}
+	return out, nil
+}
+
+// Totals splits the user's time in [From, To) into periods and todos. Entries crossing a
+// period boundary are counted in each period for the part that falls inside it.
+func (s *TimeService) Totals(ctx context.Context, q models.TimeQuery) (models.TimeTotals, error) {
+	bounds, key, err := periodBounds(q)
+	if err != nil {
+		return models.TimeTotals{}, err
+	}
+
+	entries, err := s.repo.ListByUser(ctx, q.UserID, q.From, q.To)
+	if err != nil {
+		return models.TimeTotals{}, err
+	}
+
+	now := s.now()
+	totals := models.TimeTotals{Periods: make([]models.PeriodTotal, len(bounds)-1), Todos: make([]models.TodoTotal, 0)}
+	for i := range totals.Periods {
+		totals.Periods[i].Period = key(bounds[i])
+	}
+
+	byTodo := make(map[int]int)
+	for _, e := range entries {
+		for i := range totals.Periods {
+			from, to := later(bounds[i], q.From), earlier(bounds[i+1], q.To)
+			totals.Periods[i].Seconds += int64(clip(e, from, to, now) / time.Second)
+		}
+
+		seconds := int64(clip(e, q.From, q.To, now) / time.Second)
+		totals.Seconds += seconds
+		if i, ok := byTodo[e.TodoID]; ok {
+			totals.Todos[i].Seconds += seconds
+			continue
+		}
+		byTodo[e.TodoID] = len(totals.Todos)
+		totals.Todos = append(totals.Todos, models.TodoTotal{TodoID: e.TodoID, Title: s.title(ctx, e.TodoID), Seconds: seconds})
+	}
+	slices.SortStableFunc(totals.Todos, func(a, b models.TodoTotal) int { return cmp.Compare(b.Seconds, a.Seconds) })
+
+	return totals, nil
+}
+
+func (s *TimeService) Timesheet(ctx context.Context, q models.TimeQuery) ([]models.TimesheetRow, error) {
+	if q.UserID == "" || !q.From.Before(q.To) {
+		return nil, ErrInvalidInput
+	}
+
+	entries, err := s.repo.ListByUser(ctx, q.UserID, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[int]string)
+	rows := make([]models.TimesheetRow, 0, len(entries))
+	for _, e := range entries {
+		if e.Running() {
+			// only finished work is billed
+			continue
+		}
+		if _, ok := titles[e.TodoID]; !ok {
+			titles[e.TodoID] = s.title(ctx, e.TodoID)
+		}
+		rows = append(rows, models.TimesheetRow{TimeEntry: e, Title: titles[e.TodoID]})
+	}
+	return rows, nil
+}
+
+// title is empty for deleted todos; their entries are kept because the time was still worked
+func (s *TimeService) title(ctx context.Context, todoID int) string {
+	todo, err := s.todos.GetTodo(ctx, todoID)
+	if err != nil {
+		return ""
+	}
+	return todo.Title
+}
+
+// clip returns how much of the entry falls inside [from, to)
+func clip(e models.TimeEntry, from, to, now time.Time) time.Duration {
+	end := now
+	if e.End != nil {
+		end = *e.End
+	}
+	return max(earlier(end, to).Sub(later(e.Start, from)), 0)
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// periodBounds returns the start of every period touching [From, To) followed by the end of the last
+func periodBounds(q models.TimeQuery) ([]time.Time, func(time.Time) string, error) {
+	if q.UserID == "" || !q.From.Before(q.To) {
+		return nil, nil, ErrInvalidInput
+	}
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	from := q.From.In(loc)
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	var step func(time.Time) time.Time
+	var key func(time.Time) string
+	switch q.Period {
+	case "", "day":
+		step, key = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }, dayKey
+	case "week":
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+		step, key = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }, weekKey
+	case "month":
+		start = start.AddDate(0, 0, 1-start.Day())
+		step, key = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }, monthKey
+	default:
+		return nil, nil, ErrInvalidInput
+	}
+
+	bounds := []time.Time{start}
+	for bounds[len(bounds)-1].Before(q.To) {
+		if len(bounds) > maxTimePeriods {
+			return nil, nil, ErrInvalidInput
+		}
+		bounds = append(bounds, step(bounds[len(bounds)-1]))
+	}
+	return bounds, key, nil
+}
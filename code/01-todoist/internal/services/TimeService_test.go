@@ -0,0 +1,191 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"todoist/internal/formats"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func newTimeService(t *testing.T) (*TimeService, *TodoService, *time.Time) {
+	t.Helper()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo())
+	s := NewTimeService(repositories.NewInMemoryTimeEntryRepo(), todos)
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, todos, &now
+}
+
+func TestTimers(t *testing.T) {
+	ctx := context.Background()
+	s, todos, now := newTimeService(t)
+	a := mustCreate(t, todos, "a")
+	b := mustCreate(t, todos, "b")
+
+	if _, err := s.StartTimer(ctx, models.StartTimer{TodoID: a.ID, UserID: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.StartTimer(ctx, models.StartTimer{TodoID: b.ID, UserID: "alice"}); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("got %v want %v for a second running timer", err, repositories.ErrConflict)
+	}
+	bobs, err := todos.CreateTodo(ctx, models.CreateTodo{UserID: "bob", Title: "bob's"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.StartTimer(ctx, models.StartTimer{TodoID: bobs.ID, UserID: "bob"}); err != nil {
+		t.Errorf("got %v want other users unaffected", err)
+	}
+
+	*now = now.Add(25 * time.Minute)
+	total, _ := s.TodoTime(ctx, a.ID, "alice")
+	if total.Seconds != 1500 || !total.Running {
+		t.Errorf("got %+v want 1500s running", total)
+	}
+
+	if _, err := s.StopTimer(ctx, b.ID, "alice"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("got %v want %v stopping a timer on the wrong todo", err, repositories.ErrNotFound)
+	}
+	stopped, err := s.StopTimer(ctx, a.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped.Duration(*now) != 25*time.Minute {
+		t.Errorf("got %v want 25m", stopped.Duration(*now))
+	}
+	if _, err := s.StopTimer(ctx, a.ID, "alice"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("got %v want nothing left to stop", err)
+	}
+	if _, err := s.StartTimer(ctx, models.StartTimer{TodoID: b.ID, UserID: "alice"}); err != nil {
+		t.Errorf("got %v want a new timer once the first stopped", err)
+	}
+}
+
+func TestTimeOnOtherUsersTodos(t *testing.T) {
+	ctx := context.Background()
+	s, todos, _ := newTimeService(t)
+	a := mustCreate(t, todos, "a")
+	if _, err := s.AddEntry(ctx, models.CreateTimeEntry{TodoID: a.ID, UserID: "alice", Start: time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC), Note: "private"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.StartTimer(ctx, models.StartTimer{TodoID: a.ID, UserID: "bob"}); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("start: got %v want %v", err, repositories.ErrNotFound)
+	}
+	if _, err := s.AddEntry(ctx, models.CreateTimeEntry{TodoID: a.ID, UserID: "bob", Start: time.Date(2026, 3, 9, 11, 0, 0, 0, time.UTC), End: time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)}); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("add: got %v want %v", err, repositories.ErrNotFound)
+	}
+	if _, err := s.TodoTime(ctx, a.ID, "bob"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("list: got %v want %v", err, repositories.ErrNotFound)
+	}
+
+	// an entry someone else left on the todo, say from an import, is not shown to its owner
+	if _, err := s.repo.Create(ctx, models.TimeEntry{TodoID: a.ID, UserID: "bob", Start: time.Date(2026, 3, 8, 9, 0, 0, 0, time.UTC), Note: "bob's"}); err != nil {
+		t.Fatal(err)
+	}
+	total, err := s.TodoTime(ctx, a.ID, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(total.Entries) != 1 || total.Entries[0].UserID != "alice" || total.Seconds != 3600 || total.Running {
+		t.Errorf("got %+v want only alice's hour", total)
+	}
+}
+
+func TestManualEntries(t *testing.T) {
+	ctx := context.Background()
+	s, todos, now := newTimeService(t)
+	a := mustCreate(t, todos, "a")
+
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 9, h, m, 0, 0, time.UTC) }
+	if _, err := s.AddEntry(ctx, models.CreateTimeEntry{TodoID: a.ID, UserID: "alice", Start: at(9, 0), End: at(10, 30)}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       error
+	}{
+		{"negative", at(12, 0), at(11, 0), ErrInvalidInput},
+		{"empty", at(12, 0), at(12, 0), ErrInvalidInput},
+		{"in the future", *now, now.Add(time.Hour), ErrInvalidInput},
+		{"overlaps end", at(10, 0), at(11, 0), repositories.ErrConflict},
+		{"inside", at(9, 15), at(9, 45), repositories.ErrConflict},
+		{"touching", at(10, 30), at(11, 0), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.AddEntry(ctx, models.CreateTimeEntry{TodoID: a.ID, UserID: "alice", Start: tt.start, End: tt.end})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v want %v", err, tt.want)
+			}
+		})
+	}
+
+	// a running timer blocks entries that start after it
+	s.StartTimer(ctx, models.StartTimer{TodoID: a.ID, UserID: "alice"})
+	*now = now.Add(10 * time.Minute)
+	if _, err := s.AddEntry(ctx, models.CreateTimeEntry{TodoID: a.ID, UserID: "alice", Start: now.Add(-5 * time.Minute), End: *now}); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("got %v want %v", err, repositories.ErrConflict)
+	}
+}
+
+func TestTimeTotals(t *testing.T) {
+	ctx := context.Background()
+	s, todos, _ := newTimeService(t)
+	a := mustCreate(t, todos, "a")
+	b := mustCreate(t, todos, "b")
+
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	for _, e := range []models.CreateTimeEntry{
+		{TodoID: a.ID, Start: day(2, 9), End: day(2, 11)},
+		{TodoID: b.ID, Start: day(2, 23), End: day(3, 1), Note: "late, night"},
+		{TodoID: a.ID, Start: day(8, 10), End: day(8, 11)},
+	} {
+		e.UserID = "alice"
+		if _, err := s.AddEntry(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := models.TimeQuery{UserID: "alice", From: day(2, 0), To: day(4, 0), Period: "day"}
+	totals, err := s.Totals(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totals.Seconds != 4*3600 || len(totals.Periods) != 2 {
+		t.Fatalf("got %+v want 4h over two days", totals)
+	}
+	if totals.Periods[0].Seconds != 3*3600 || totals.Periods[1].Seconds != 3600 {
+		t.Errorf("got %+v want the night entry split across midnight", totals.Periods)
+	}
+
+	q.From, q.To, q.Period = day(1, 0), day(31, 0), "week"
+	totals, _ = s.Totals(ctx, q)
+	if totals.Periods[0].Period != "2026-W09" || totals.Todos[0].Title != "a" || totals.Todos[0].Seconds != 3*3600 {
+		t.Errorf("got %+v want weeks from 2026-W09 and a first with 3h", totals)
+	}
+
+	q.Period = "fortnight"
+	if _, err := s.Totals(ctx, q); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("got %v want %v", err, ErrInvalidInput)
+	}
+
+	rows, err := s.Timesheet(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := formats.WriteTimesheet(&buf, rows, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || lines[2] != `2026-03-02,2026-03-02T23:00:00Z,2026-03-03T01:00:00Z,2.00,7200,2,b,"late, night"` {
+		t.Errorf("unexpected timesheet:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+// checkBlockers returns ErrBlocked while any of the todo's blockers is pending.
+// Trashed blockers no longer hold anything up.
+func (s *TodoService) checkBlockers(ctx context.Context, id int) error {
+	if s.deps == nil {
+		return nil
+	}
+
+	blockers, err := s.deps.Blockers(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, b := range blockers {
+		blocker, err := s.repo.GetByID(ctx, b)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if blocker.Status == models.StatusPending {
+			return ErrBlocked
+		}
+	}
+	return nil
+}
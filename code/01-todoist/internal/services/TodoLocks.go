@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"todoist/internal/models"
+)
+
+// userLocks serialises the writes to each user's todos, so that a check made before a write,
+// such as undo's staleness check or choosing a free position, still holds when it is made
+type userLocks struct {
+	mu    sync.Mutex
+	users map[string]*userLock
+}
+
+type userLock struct {
+	sync.Mutex
+
+	// last is the position key of the user's last todo, cached so a create does not have to
+	// list and sort the user's todos to find the end of the list
+	last  string
+	known bool
+}
+
+func (l *userLocks) lock(userID string) *userLock {
+	l.mu.Lock()
+	if l.users == nil {
+		l.users = make(map[string]*userLock)
+	}
+	u := l.users[userID]
+	if u == nil {
+		u = &userLock{}
+		l.users[userID] = u
+	}
+	l.mu.Unlock()
+
+	u.Lock()
+	return u
+}
+
+// reset makes every user's next create look up the end of the list again
+func (l *userLocks) reset() {
+	l.mu.Lock()
+	users := make([]*userLock, 0, len(l.users))
+	for _, u := range l.users {
+		users = append(users, u)
+	}
+	l.mu.Unlock()
+
+	for _, u := range users {
+		u.Lock()
+		u.known = false
+		u.Unlock()
+	}
+}
+
+// lockTodo locks the owner of a todo and returns the todo as it is under the lock
+func (s *TodoService) lockTodo(ctx context.Context, id int) (models.Todo, *userLock, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.Todo{}, nil, err
+	}
+
+	lock := s.writes.lock(t.UserID)
+	// a todo never changes owner, but it may have been written or deleted in the meantime
+	if t, err = s.repo.GetByID(ctx, id); err != nil {
+		lock.Unlock()
+		return models.Todo{}, nil, err
+	}
+	return t, lock, nil
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/rank"
+)
+
+// ordered lists a user's todos by position. The repository's slice is copied first because
+// decorators such as the cache may share it.
+func (s *TodoService) ordered(ctx context.Context, userID string) ([]models.Todo, error) {
+	todos, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	todos = slices.Clone(todos)
+	slices.SortFunc(todos, models.ComparePosition)
+	return todos, nil
+}
+
+// insert stores a new todo at the end of its user's list. When the key after the last todo
+// would grow past rank.MaxLength, the list is respaced first and those writes are returned
+// so they can be undone together with the create.
+func (s *TodoService) insert(ctx context.Context, t models.Todo) (models.Todo, []undoChange, error) {
+	tail := s.writes.lock(t.UserID)
+	defer tail.Unlock()
+
+	if !tail.known {
+		todos, err := s.ordered(ctx, t.UserID)
+		if err != nil {
+			return models.Todo{}, nil, err
+		}
+		tail.last = ""
+		if len(todos) > 0 {
+			tail.last = todos[len(todos)-1].Position
+		}
+		tail.known = true
+	}
+
+	var changes []undoChange
+	key, err := rank.Between(tail.last, "")
+	if err != nil || len(key) > rank.MaxLength {
+		todos, err := s.ordered(ctx, t.UserID)
+		if err != nil {
+			return models.Todo{}, nil, err
+		}
+		keys := rank.Spread(len(todos) + 1)
+		if changes, err = s.respace(ctx, todos, keys); err != nil {
+			tail.known = false
+			return models.Todo{}, changes, err
+		}
+		key = keys[len(todos)]
+	}
+
+	t.Position = key
+	created, err := s.repo.Create(ctx, t)
+	if err != nil {
+		return models.Todo{}, changes, err
+	}
+	tail.last = key
+	return created, changes, nil
+}
+
+// MoveTodo writes a single new position key for the moved todo. Only when the key would grow
+// past rank.MaxLength, or the list holds todos without a valid key, are all of the user's
+// todos given fresh evenly spaced keys.
+func (s *TodoService) MoveTodo(ctx context.Context, dto models.MoveTodo) (models.Todo, error) {
+	if dto.ID <= 0 || (dto.After == nil && dto.Before == nil) {
+		return models.Todo{}, ErrInvalidInput
+	}
+
+	todo, lock, err := s.lockTodo(ctx, dto.ID)
+	if err != nil {
+		return models.Todo{}, err
+	}
+	defer lock.Unlock()
+	// the moved todo may become, or stop being, the last one
+	lock.known = false
+
+	todos, err := s.ordered(ctx, todo.UserID)
+	if err != nil {
+		return models.Todo{}, err
+	}
+	siblings := slices.DeleteFunc(todos, func(t models.Todo) bool { return t.ID == todo.ID })
+
+	indexOf := func(id *int) (int, bool) {
+		if id == nil {
+			return -1, true
+		}
+		i := slices.IndexFunc(siblings, func(t models.Todo) bool { return t.ID == *id })
+		return i, i >= 0
+	}
+	ai, okA := indexOf(dto.After)
+	bi, okB := indexOf(dto.Before)
+	// neighbours must belong to the same user and be in the order given
+	if !okA || !okB || (dto.After != nil && dto.Before != nil && ai >= bi) {
+		return models.Todo{}, ErrInvalidInput
+	}
+
+	// the slot the todo moves into
+	slot := bi
+	if dto.After != nil {
+		slot = ai + 1
+	}
+
+	a, b := "", ""
+	if slot > 0 {
+		a = siblings[slot-1].Position
+	}
+	if dto.Before != nil {
+		b = siblings[bi].Position
+	} else if slot < len(siblings) {
+		b = siblings[slot].Position
+	}
+
+	key, err := rank.Between(a, b)
+	if err != nil || len(key) > rank.MaxLength {
+		return s.rebalance(ctx, slices.Insert(siblings, slot, todo), todo.ID)
+	}
+
+	moved, err := s.setPosition(ctx, todo, key)
+	if err != nil {
+		return models.Todo{}, err
+	}
+	s.remember(moved.UserID, undoChange{Before: &todo, After: &moved})
+	return moved, nil
+}
+
+func (s *TodoService) setPosition(ctx context.Context, t models.Todo, key string) (models.Todo, error) {
+	previous := t
+	t.Position = key
+	t.UpdatedAt = time.Now()
+
+	updated, err := s.repo.Update(ctx, t)
+	if err != nil {
+		return models.Todo{}, err
+	}
+
+	s.publish(events.TodoUpdated, updated, &previous)
+	return updated, nil
+}
+
+// rebalance gives todos, already in the wanted order, evenly spaced keys and returns the todo
+// with the given ID. All the writes are undone together.
+func (s *TodoService) rebalance(ctx context.Context, todos []models.Todo, id int) (models.Todo, error) {
+	changes, err := s.respace(ctx, todos, rank.Spread(len(todos)))
+	s.remember(todos[0].UserID, changes...)
+	if err != nil {
+		return models.Todo{}, err
+	}
+
+	for _, c := range changes {
+		if c.After.ID == id {
+			return *c.After, nil
+		}
+	}
+	i := slices.IndexFunc(todos, func(t models.Todo) bool { return t.ID == id })
+	return todos[i], nil
+}
+
+// respace writes keys to todos in order, skipping those already there, and returns the writes
+// made even when one fails
+func (s *TodoService) respace(ctx context.Context, todos []models.Todo, keys []string) ([]undoChange, error) {
+	var changes []undoChange
+	for i, t := range todos {
+		if t.Position == keys[i] {
+			continue
+		}
+		updated, err := s.setPosition(ctx, t, keys[i])
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, undoChange{Before: &todos[i], After: &updated})
+	}
+	return changes, nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"todoist/internal/models"
+	"todoist/internal/rank"
+	"todoist/internal/repositories"
+)
+
+func titles(t *testing.T, s *TodoService) string {
+	t.Helper()
+	todos, err := s.ListTodos(context.Background(), "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]string, len(todos))
+	for i, todo := range todos {
+		out[i] = todo.Title
+	}
+	return strings.Join(out, ",")
+}
+
+func TestMoveTodo(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewInMemoryTodoRepo()
+	todos := NewTodoService(repo)
+	a := mustCreate(t, todos, "a")
+	b := mustCreate(t, todos, "b")
+	c := mustCreate(t, todos, "c")
+
+	if got := titles(t, todos); got != "a,b,c" {
+		t.Fatalf("got %s want creation order", got)
+	}
+
+	_, before, _ := repo.ChangesSince(ctx, "alice", 0)
+	if _, err := todos.MoveTodo(ctx, models.MoveTodo{ID: c.ID, After: &a.ID, Before: &b.ID}); err != nil {
+		t.Fatal(err)
+	}
+	_, after, _ := repo.ChangesSince(ctx, "alice", 0)
+	if got := titles(t, todos); got != "a,c,b" {
+		t.Errorf("got %s want a,c,b", got)
+	}
+	if after != before+1 {
+		t.Errorf("got %d writes want only the moved todo written", after-before)
+	}
+
+	todos.MoveTodo(ctx, models.MoveTodo{ID: a.ID, After: &b.ID})
+	todos.MoveTodo(ctx, models.MoveTodo{ID: b.ID, Before: &c.ID})
+	if got := titles(t, todos); got != "b,c,a" {
+		t.Errorf("got %s want b,c,a", got)
+	}
+
+	bob, _ := todos.CreateTodo(ctx, models.CreateTodo{UserID: "bob", Title: "not alice's"})
+	invalid := []models.MoveTodo{
+		{ID: a.ID},
+		{ID: a.ID, After: &bob.ID},
+		{ID: a.ID, After: &c.ID, Before: &b.ID},
+	}
+	for _, dto := range invalid {
+		if _, err := todos.MoveTodo(ctx, dto); err != ErrInvalidInput {
+			t.Errorf("got %v want %v for %+v", err, ErrInvalidInput, dto)
+		}
+	}
+}
+
+// TestMoveTodoRebalances keeps squeezing a todo into the same gap until the keys must be respread
+func TestMoveTodoRebalances(t *testing.T) {
+	ctx := context.Background()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo())
+	first := mustCreate(t, todos, "first")
+	last := mustCreate(t, todos, "last")
+
+	lower := first
+	for i := range 300 {
+		moved := mustCreate(t, todos, "x")
+		moved, err := todos.MoveTodo(ctx, models.MoveTodo{ID: moved.ID, After: &lower.ID, Before: &last.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(moved.Position) > rank.MaxLength {
+			t.Fatalf("move %d: key %q longer than %d", i, moved.Position, rank.MaxLength)
+		}
+		lower = moved
+	}
+
+	list, _ := todos.ListTodos(ctx, "alice")
+	if list[0].ID != first.ID || list[len(list)-1].ID != last.ID || list[len(list)-2].ID != lower.ID {
+		t.Error("order was not preserved through rebalancing")
+	}
+}
+
+func TestCreateTodoKeepsKeysShort(t *testing.T) {
+	ctx := context.Background()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo())
+
+	for i := range 2000 {
+		todo := mustCreate(t, todos, "x")
+		if len(todo.Position) > rank.MaxLength {
+			t.Fatalf("create %d: key %q longer than %d", i, todo.Position, rank.MaxLength)
+		}
+	}
+
+	list, _ := todos.ListTodos(ctx, "alice")
+	for i := 1; i < len(list); i++ {
+		if list[i].ID < list[i-1].ID || len(list[i].Position) > rank.MaxLength {
+			t.Fatalf("got %+v after %+v want creation order with short keys", list[i], list[i-1])
+		}
+	}
+}
+
+func TestCreateTodoConcurrentKeys(t *testing.T) {
+	ctx := context.Background()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo())
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := todos.CreateTodo(ctx, models.CreateTodo{UserID: "alice", Title: "x"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	list, _ := todos.ListTodos(ctx, "alice")
+	seen := make(map[string]bool)
+	for _, todo := range list {
+		if seen[todo.Position] {
+			t.Errorf("got key %q twice", todo.Position)
+		}
+		seen[todo.Position] = true
+	}
+}
@@ -2,9 +2,14 @@ package services
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
+	"todoist/internal/events"
+	"todoist/internal/formats"
 	"todoist/internal/models"
 	"todoist/internal/repositories"
+	"unicode"
 )
 
 type ITodoService interface {
@@ -13,39 +18,183 @@ type ITodoService interface {
 	ListTodos(ctx context.Context, userID string) ([]models.Todo, error)
 	UpdateTodo(ctx context.Context, dto models.UpdateTodo) (models.Todo, error)
 	DeleteTodo(ctx context.Context, id int) error
+	MoveTodo(ctx context.Context, dto models.MoveTodo) (models.Todo, error)
 }
 
 type TodoService struct {
-	repo repositories.TodoRepository
+	repo     repositories.TodoRepository
+	events   *events.Bus
+	users    repositories.UserRepository
+	projects repositories.ProjectRepository
+	deps     repositories.DependencyRepository
+	undo     *undoLog
+	writes   userLocks
 }
 
-func NewTodoService(repo repositories.TodoRepository) *TodoService {
-	return &TodoService{
+// Option configures optional collaborators of TodoService
+type Option func(*TodoService)
+
+// WithEvents makes the service publish an event after each successful write
+func WithEvents(bus *events.Bus) Option {
+	return func(s *TodoService) {
+		s.events = bus
+	}
+}
+
+// WithUsers makes CreateTodo refuse todos for accounts that do not exist
+func WithUsers(users repositories.UserRepository) Option {
+	return func(s *TodoService) {
+		s.users = users
+	}
+}
+
+// WithProjects lets todos belong to projects and follow their workflows
+func WithProjects(projects repositories.ProjectRepository) Option {
+	return func(s *TodoService) {
+		s.projects = projects
+	}
+}
+
+// WithDependencies makes UpdateTodo refuse to complete todos whose blockers are still pending
+func WithDependencies(deps repositories.DependencyRepository) Option {
+	return func(s *TodoService) {
+		s.deps = deps
+	}
+}
+
+func NewTodoService(repo repositories.TodoRepository, opts ...Option) *TodoService {
+	s := &TodoService{
 		repo: repo,
+		undo: newUndoLog(defaultUndoDepth),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// CreateTodo validates input, constructs domain model, and delegates to repository
-func (s *TodoService) CreateTodo(ctx context.Context, dto models.CreateTodo) (models.Todo, error) {
+func (s *TodoService) publish(typ events.Type, t models.Todo, prev *models.Todo) {
+	if s.events == nil {
+		return
+	}
+	s.events.Publish(events.Event{Type: typ, UserID: t.UserID, Todo: t, Previous: prev})
+}
 
+// ValidateCreateTodo holds the rules every new todo must satisfy, whichever way it is created
+func ValidateCreateTodo(dto models.CreateTodo) error {
 	if dto.UserID == "" || dto.Title == "" {
-		return models.Todo{}, ErrInvalidInput
+		return ErrInvalidInput
 	}
 
 	if len(dto.Title) > 255 {
-		return models.Todo{}, ErrInvalidInput
+		return ErrInvalidInput
 	}
 
+	if _, err := normalizeLabels(dto.Labels); err != nil {
+		return err
+	}
+
+	if !validPriority(dto.Priority) || len(dto.Recurrence) > 255 {
+		return ErrInvalidInput
+	}
+
+	return nil
+}
+
+// maxLabels bounds how many labels one todo can carry
+const maxLabels = 20
+
+// normalizeLabels drops a leading # and duplicates that differ only in case, keeping the first spelling
+func normalizeLabels(labels []string) ([]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	if len(labels) > maxLabels {
+		return nil, ErrInvalidInput
+	}
+
+	seen := make(map[string]bool, len(labels))
+	out := make([]string, 0, len(labels))
+	for _, l := range labels {
+		l = strings.TrimPrefix(l, "#")
+		if l == "" || len(l) > 64 || strings.ContainsFunc(l, unicode.IsSpace) {
+			return nil, ErrInvalidInput
+		}
+		if key := strings.ToLower(l); !seen[key] {
+			seen[key] = true
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func validPriority(p models.Priority) bool {
+	switch p {
+	case "", models.PriorityLow, models.PriorityMedium, models.PriorityHigh, models.PriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// CreateTodo validates input, constructs domain model, and delegates to repository
+func (s *TodoService) CreateTodo(ctx context.Context, dto models.CreateTodo) (models.Todo, error) {
+
+	if err := ValidateCreateTodo(dto); err != nil {
+		return models.Todo{}, err
+	}
+
+	labels, _ := normalizeLabels(dto.Labels)
+
+	if s.users != nil {
+		if _, err := s.users.GetByID(ctx, dto.UserID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return models.Todo{}, ErrUnknownUser
+			}
+			return models.Todo{}, err
+		}
+	}
+
+	workflow, err := s.workflowFor(ctx, dto.UserID, dto.ProjectID)
+	if err != nil {
+		return models.Todo{}, err
+	}
+	initial := workflow.InitialState()
+
 	t := models.Todo{
 		UserID:      dto.UserID,
 		Title:       dto.Title,
 		Description: dto.Description,
-		Status:      models.StatusPending,
+		Status:      initial.Category.Status(),
+		DueAt:       dto.DueAt,
+		Labels:      labels,
+		Priority:    dto.Priority,
+		Recurrence:  dto.Recurrence,
+		ProjectID:   dto.ProjectID,
+		UID:         dto.UID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	if t.ProjectID != 0 {
+		t.State = initial.Key
+	}
+	if dto.Status != "" && dto.Status != t.Status {
+		if err := s.transition(ctx, &t, &dto.Status, nil); err != nil {
+			return models.Todo{}, err
+		}
+	}
+	if t.UID == "" {
+		t.UID = formats.NewTodoUID()
+	}
 
-	return s.repo.Create(ctx, t)
+	created, respaced, err := s.insert(ctx, t)
+	if err != nil {
+		s.remember(t.UserID, respaced...)
+		return models.Todo{}, err
+	}
+
+	s.remember(created.UserID, append(respaced, undoChange{After: &created})...)
+	s.publish(events.TodoCreated, created, nil)
+	return created, nil
 }
 
 // GetTodo retrieves a todo by ID with validation
@@ -57,13 +206,26 @@ func (s *TodoService) GetTodo(ctx context.Context, id int) (models.Todo, error)
 	return s.repo.GetByID(ctx, id)
 }
 
-// ListTodos retrieves all todos for a given user
+// ownedTodo fetches a todo on behalf of a user. Someone else's todo looks missing, so IDs
+// cannot be probed through the comments and attachments hanging off it.
+func ownedTodo(ctx context.Context, todos ITodoService, id int, userID string) (models.Todo, error) {
+	t, err := todos.GetTodo(ctx, id)
+	if err != nil {
+		return models.Todo{}, err
+	}
+	if userID == "" || t.UserID != userID {
+		return models.Todo{}, repositories.ErrNotFound
+	}
+	return t, nil
+}
+
+// ListTodos retrieves all todos for a given user in position order
 func (s *TodoService) ListTodos(ctx context.Context, userID string) ([]models.Todo, error) {
 	if userID == "" {
 		return nil, ErrInvalidInput
 	}
 
-	return s.repo.ListByUser(ctx, userID)
+	return s.ordered(ctx, userID)
 }
 
 func (s *TodoService) UpdateTodo(ctx context.Context, dto models.UpdateTodo) (models.Todo, error) {
@@ -72,12 +234,13 @@ func (s *TodoService) UpdateTodo(ctx context.Context, dto models.UpdateTodo) (mo
 		return models.Todo{}, ErrInvalidInput
 	}
 
-	existing, err := s.repo.GetByID(ctx, dto.ID)
-
+	existing, lock, err := s.lockTodo(ctx, dto.ID)
 	if err != nil {
 		// propagate ErrNotFound from repo
 		return models.Todo{}, err
 	}
+	defer lock.Unlock()
+	previous := existing
 
 	if dto.Title != nil {
 		if *dto.Title == "" || len(*dto.Title) > 255 {
@@ -88,18 +251,43 @@ func (s *TodoService) UpdateTodo(ctx context.Context, dto models.UpdateTodo) (mo
 	if dto.Description != nil {
 		existing.Description = *dto.Description
 	}
-	if dto.Status != nil {
-		switch *dto.Status {
-		case models.StatusPending, models.StatusCompleted, models.StatusTrashed:
-			existing.Status = *dto.Status
-		default:
+	if dto.Status != nil || dto.State != nil {
+		if err := s.transition(ctx, &existing, dto.Status, dto.State); err != nil {
+			return models.Todo{}, err
+		}
+	}
+	if existing.Status == models.StatusCompleted && previous.Status != models.StatusCompleted {
+		if err := s.checkBlockers(ctx, existing.ID); err != nil {
+			return models.Todo{}, err
+		}
+	}
+	if dto.DueAt != nil {
+		existing.DueAt = dto.DueAt
+	}
+	if dto.Labels != nil {
+		labels, err := normalizeLabels(*dto.Labels)
+		if err != nil {
+			return models.Todo{}, err
+		}
+		existing.Labels = labels
+	}
+	if dto.Priority != nil {
+		if !validPriority(*dto.Priority) {
 			return models.Todo{}, ErrInvalidInput
 		}
+		existing.Priority = *dto.Priority
 	}
 
 	existing.UpdatedAt = time.Now()
 
-	return s.repo.Update(ctx, existing)
+	updated, err := s.repo.Update(ctx, existing)
+	if err != nil {
+		return models.Todo{}, err
+	}
+
+	s.remember(updated.UserID, undoChange{Before: &previous, After: &updated})
+	s.publish(events.TodoUpdated, updated, &previous)
+	return updated, nil
 }
 
 func (s *TodoService) DeleteTodo(ctx context.Context, id int) error {
@@ -107,5 +295,17 @@ func (s *TodoService) DeleteTodo(ctx context.Context, id int) error {
 		return ErrInvalidInput
 	}
 
-	return s.repo.Delete(ctx, id)
+	existing, lock, err := s.lockTodo(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.remember(existing.UserID, undoChange{Before: &existing})
+	s.publish(events.TodoDeleted, existing, &existing)
+	return nil
 }
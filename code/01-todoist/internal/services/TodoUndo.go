@@ -0,0 +1,311 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+type IUndoService interface {
+	Undo(ctx context.Context, userID string) (models.UndoResult, error)
+	Redo(ctx context.Context, userID string) (models.UndoResult, error)
+}
+
+// defaultUndoDepth is how many steps each user can undo
+const defaultUndoDepth = 50
+
+// undoChange is one write: Before is nil for a create and After is nil for a delete
+type undoChange struct {
+	Before *models.Todo
+	After  *models.Todo
+}
+
+// undoStep is everything one TodoService call wrote, such as all the todos a move rebalanced
+type undoStep []undoChange
+
+type undoStacks struct {
+	undo, redo []undoStep
+}
+
+// undoLog keeps bounded per-user stacks. Both stacks hold steps in the direction they were
+// written; undoing or redoing a step means reverting it, which produces the step that goes on
+// the other stack. Steps that fall off either stack are returned so that todos only they
+// could bring back can be purged.
+type undoLog struct {
+	depth int
+	mu    sync.Mutex
+	users map[string]*undoStacks
+}
+
+func newUndoLog(depth int) *undoLog {
+	return &undoLog{depth: depth, users: make(map[string]*undoStacks)}
+}
+
+func (l *undoLog) stacks(userID string) *undoStacks {
+	st := l.users[userID]
+	if st == nil {
+		st = &undoStacks{}
+		l.users[userID] = st
+	}
+	return st
+}
+
+func (l *undoLog) bounded(steps []undoStep, step undoStep) ([]undoStep, []undoStep) {
+	steps = append(steps, step)
+	if len(steps) <= l.depth {
+		return steps, nil
+	}
+	n := len(steps) - l.depth
+	dropped := slices.Clone(steps[:n])
+	return slices.Delete(steps, 0, n), dropped
+}
+
+// record stores a new mutation; it makes anything undone so far impossible to redo
+func (l *undoLog) record(userID string, step undoStep) []undoStep {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := l.stacks(userID)
+	var dropped []undoStep
+	st.undo, dropped = l.bounded(st.undo, step)
+	dropped = append(dropped, st.redo...)
+	st.redo = nil
+	return dropped
+}
+
+func (l *undoLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.users = make(map[string]*undoStacks)
+}
+
+func (l *undoLog) pop(userID string, redo bool) (undoStep, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := l.stacks(userID)
+	from := &st.undo
+	if redo {
+		from = &st.redo
+	}
+	if len(*from) == 0 {
+		return nil, false
+	}
+	step := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	return step, true
+}
+
+func (l *undoLog) push(userID string, step undoStep, redo bool) []undoStep {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := l.stacks(userID)
+	var dropped []undoStep
+	if redo {
+		st.redo, dropped = l.bounded(st.redo, step)
+	} else {
+		st.undo, dropped = l.bounded(st.undo, step)
+	}
+	return dropped
+}
+
+// WithUndoDepth sets how many steps each user can undo; 0 turns undo off
+func WithUndoDepth(depth int) Option {
+	return func(s *TodoService) {
+		s.undo = nil
+		if depth > 0 {
+			s.undo = newUndoLog(depth)
+		}
+	}
+}
+
+// Rebuild forgets the undo history and cached positions, which describe the todos as they were
+// before a backup was restored
+func (s *TodoService) Rebuild(todos []models.Todo) {
+	if s.undo != nil {
+		s.undo.reset()
+	}
+	s.writes.reset()
+}
+
+func (s *TodoService) remember(userID string, changes ...undoChange) {
+	if len(changes) == 0 {
+		return
+	}
+	if s.undo == nil {
+		s.purge([]undoStep{changes})
+		return
+	}
+	s.purge(s.undo.record(userID, changes))
+}
+
+// purge publishes TodoPurged for the todos deleted by the given steps that are still gone.
+// Nothing else can bring them back once their step is dropped, so only then are the comments,
+// attachments and relations that hang off them dropped too.
+func (s *TodoService) purge(dropped []undoStep) {
+	for _, step := range dropped {
+		for _, c := range step {
+			if c.After != nil {
+				continue
+			}
+			if _, err := s.repo.GetByID(context.Background(), c.Before.ID); errors.Is(err, repositories.ErrNotFound) {
+				s.publish(events.TodoPurged, *c.Before, nil)
+			}
+		}
+	}
+}
+
+// Undo reverts the user's latest step. If any todo in it has changed since, nothing is
+// applied, the step is dropped and ErrStale is returned.
+func (s *TodoService) Undo(ctx context.Context, userID string) (models.UndoResult, error) {
+	return s.revertLatest(ctx, userID, false)
+}
+
+// Redo reapplies the step most recently undone, with the same staleness check as Undo
+func (s *TodoService) Redo(ctx context.Context, userID string) (models.UndoResult, error) {
+	return s.revertLatest(ctx, userID, true)
+}
+
+func (s *TodoService) revertLatest(ctx context.Context, userID string, redo bool) (models.UndoResult, error) {
+	if userID == "" {
+		return models.UndoResult{}, ErrInvalidInput
+	}
+	if s.undo == nil {
+		return models.UndoResult{}, errors.ErrUnsupported
+	}
+
+	// no other write to the user's todos can come between the staleness check and the revert
+	lock := s.writes.lock(userID)
+	defer lock.Unlock()
+
+	step, ok := s.undo.pop(userID, redo)
+	if !ok {
+		return models.UndoResult{}, ErrNothingToUndo
+	}
+
+	reverted, err := s.revert(ctx, step)
+	// restored and moved todos may now sit after the last key handed out
+	lock.known = false
+	if err != nil {
+		if errors.Is(err, ErrStale) {
+			s.purge([]undoStep{step})
+		} else {
+			// nothing was left applied, so the step can be tried again
+			s.purge(s.undo.push(userID, step, redo))
+		}
+		return models.UndoResult{}, err
+	}
+	s.purge(s.undo.push(userID, reverted, !redo))
+
+	result := models.UndoResult{Changed: make([]models.Todo, 0), Deleted: make([]int, 0)}
+	for _, c := range reverted {
+		if c.After != nil {
+			result.Changed = append(result.Changed, *c.After)
+		} else {
+			result.Deleted = append(result.Deleted, c.Before.ID)
+		}
+	}
+	return result, nil
+}
+
+// revert puts every todo in the step back to its Before state, last write first, and returns
+// the writes it made. All todos are checked before anything is written; if a write fails,
+// the ones already made are reverted in turn.
+func (s *TodoService) revert(ctx context.Context, step undoStep) (undoStep, error) {
+	for _, c := range step {
+		if err := s.unchanged(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
+	done := make(undoStep, 0, len(step))
+	for i := len(step) - 1; i >= 0; i-- {
+		c, err := s.write(ctx, step[i])
+		if err != nil {
+			for j := len(done) - 1; j >= 0; j-- {
+				if _, rerr := s.write(ctx, done[j]); rerr != nil {
+					break
+				}
+			}
+			return nil, err
+		}
+		done = append(done, c)
+	}
+	return done, nil
+}
+
+// write reverts a single change and returns the change that made
+func (s *TodoService) write(ctx context.Context, c undoChange) (undoChange, error) {
+	switch {
+	case c.Before == nil:
+		if err := s.repo.Delete(ctx, c.After.ID); err != nil {
+			return undoChange{}, err
+		}
+		s.publish(events.TodoDeleted, *c.After, c.After)
+		return undoChange{Before: c.After}, nil
+
+	case c.After == nil:
+		t := *c.Before
+		t.UpdatedAt = time.Now()
+		restored, err := s.repo.(repositories.Restorer).Restore(ctx, t)
+		if err != nil {
+			return undoChange{}, err
+		}
+		s.publish(events.TodoCreated, restored, nil)
+		return undoChange{After: &restored}, nil
+	}
+
+	t := *c.Before
+	t.UpdatedAt = time.Now()
+	updated, err := s.repo.Update(ctx, t)
+	if err != nil {
+		return undoChange{}, err
+	}
+	s.publish(events.TodoUpdated, updated, c.After)
+	return undoChange{Before: c.After, After: &updated}, nil
+}
+
+// unchanged checks the todo is still as the step left it
+func (s *TodoService) unchanged(ctx context.Context, c undoChange) error {
+	id := idOf(c)
+	current, err := s.repo.GetByID(ctx, id)
+	switch {
+	case c.After == nil && errors.Is(err, repositories.ErrNotFound):
+		if _, ok := s.repo.(repositories.Restorer); !ok {
+			return errors.ErrUnsupported
+		}
+		return nil
+	case c.After == nil, errors.Is(err, repositories.ErrNotFound):
+		return ErrStale
+	case err != nil:
+		return err
+	case !sameTodo(current, *c.After):
+		return ErrStale
+	}
+	return nil
+}
+
+// sameTodo compares everything but UpdatedAt, which every undo and redo moves forward.
+// Going through JSON compares times by instant rather than by their monotonic readings.
+func sameTodo(a, b models.Todo) bool {
+	a.UpdatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	ja, erra := json.Marshal(a)
+	jb, errb := json.Marshal(b)
+	return erra == nil && errb == nil && bytes.Equal(ja, jb)
+}
+
+func idOf(c undoChange) int {
+	if c.After != nil {
+		return c.After.ID
+	}
+	return c.Before.ID
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"todoist/internal/blobs"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func TestUndoRedo(t *testing.T) {
+	ctx := context.Background()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo())
+
+	a := mustCreate(t, todos, "a")
+	renamed := "renamed"
+	todos.UpdateTodo(ctx, models.UpdateTodo{ID: a.ID, Title: &renamed})
+	todos.DeleteTodo(ctx, a.ID)
+
+	// undo the delete, then the rename, then the create
+	result, err := todos.Undo(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changed) != 1 || result.Changed[0].ID != a.ID || result.Changed[0].Title != "renamed" {
+		t.Fatalf("got %+v want todo %d restored under its old ID", result, a.ID)
+	}
+	todos.Undo(ctx, "alice")
+	if got, _ := todos.GetTodo(ctx, a.ID); got.Title != "a" {
+		t.Errorf("got title %q want a", got.Title)
+	}
+	todos.Undo(ctx, "alice")
+	if _, err := todos.GetTodo(ctx, a.ID); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("got %v want the create undone", err)
+	}
+	if _, err := todos.Undo(ctx, "alice"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("got %v want %v", err, ErrNothingToUndo)
+	}
+
+	for range 2 {
+		if _, err := todos.Redo(ctx, "alice"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := todos.GetTodo(ctx, a.ID); got.Title != "renamed" {
+		t.Errorf("got title %q want the rename redone", got.Title)
+	}
+
+	// a new mutation clears what could be redone
+	mustCreate(t, todos, "b")
+	if _, err := todos.Redo(ctx, "alice"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("got %v want %v", err, ErrNothingToUndo)
+	}
+	if _, err := todos.Undo(ctx, "bob"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("got %v want stacks kept per user", err)
+	}
+}
+
+func TestUndoRefusesStaleRecords(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewInMemoryTodoRepo()
+	todos := NewTodoService(repo)
+
+	a := mustCreate(t, todos, "a")
+	done := models.StatusCompleted
+	todos.UpdateTodo(ctx, models.UpdateTodo{ID: a.ID, Status: &done})
+
+	// written behind the service's back, as another replica would
+	current, _ := repo.GetByID(ctx, a.ID)
+	current.Title = "edited elsewhere"
+	repo.Update(ctx, current)
+
+	if _, err := todos.Undo(ctx, "alice"); !errors.Is(err, ErrStale) {
+		t.Fatalf("got %v want %v", err, ErrStale)
+	}
+	if got, _ := todos.GetTodo(ctx, a.ID); got.Status != models.StatusCompleted || got.Title != "edited elsewhere" {
+		t.Errorf("got %+v want nothing applied", got)
+	}
+
+	// the stale step is dropped, the create below it is also stale now
+	if _, err := todos.Undo(ctx, "alice"); !errors.Is(err, ErrStale) {
+		t.Errorf("got %v want %v", err, ErrStale)
+	}
+	if _, err := todos.Undo(ctx, "alice"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("got %v want %v", err, ErrNothingToUndo)
+	}
+}
+
+func TestUndoIsBoundedAndGroupsRebalances(t *testing.T) {
+	ctx := context.Background()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo(), WithUndoDepth(3))
+	for _, title := range []string{"a", "b", "c", "d"} {
+		mustCreate(t, todos, title)
+	}
+
+	undone := 0
+	for {
+		if _, err := todos.Undo(ctx, "alice"); err != nil {
+			break
+		}
+		undone++
+	}
+	if undone != 3 {
+		t.Errorf("got %d undos want 3", undone)
+	}
+	if got := titles(t, todos); got != "a" {
+		t.Errorf("got %s want the oldest create kept", got)
+	}
+
+	for range 3 {
+		todos.Redo(ctx, "alice")
+	}
+	d := 4
+	todos.rebalance(ctx, []models.Todo{mustGet(t, todos, d), mustGet(t, todos, 1), mustGet(t, todos, 2), mustGet(t, todos, 3)}, d)
+	if got := titles(t, todos); got != "d,a,b,c" {
+		t.Fatalf("got %s want d,a,b,c", got)
+	}
+	if _, err := todos.Undo(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(t, todos); got != "a,b,c,d" {
+		t.Errorf("got %s want the whole rebalance undone at once", got)
+	}
+}
+
+func mustGet(t *testing.T, s *TodoService, id int) models.Todo {
+	t.Helper()
+	todo, err := s.GetTodo(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return todo
+}
+
+func TestUndoDeleteKeepsRelatedData(t *testing.T) {
+	ctx := context.Background()
+
+	type fixture struct {
+		todos       *TodoService
+		comments    *CommentService
+		attachments *AttachmentService
+		deps        *DependencyService
+		todo        models.Todo
+		blocker     models.Todo
+		attachment  models.Attachment
+	}
+	setup := func(t *testing.T, opts ...Option) fixture {
+		bus := events.NewBus(16)
+		depRepo := repositories.NewInMemoryDependencyRepo()
+		todos := NewTodoService(repositories.NewInMemoryTodoRepo(), append([]Option{WithEvents(bus)}, opts...)...)
+		f := fixture{
+			todos:       todos,
+			comments:    NewCommentService(repositories.NewInMemoryCommentRepo(), todos),
+			attachments: NewAttachmentService(repositories.NewInMemoryAttachmentRepo(), blobs.NewMemoryStore(), todos),
+			deps:        NewDependencyService(depRepo, todos, repositories.NewInMemoryProjectRepo()),
+		}
+		bus.Listen(f.comments.HandleEvent)
+		bus.Listen(f.attachments.HandleEvent)
+		bus.Listen(f.deps.HandleEvent)
+
+		f.todo = mustCreate(t, todos, "with history")
+		f.blocker = mustCreate(t, todos, "first")
+		f.comments.AddComment(ctx, models.CreateComment{TodoID: f.todo.ID, UserID: "alice", Body: "note"})
+		f.attachment, _ = f.attachments.Upload(ctx, models.UploadAttachment{TodoID: f.todo.ID, UserID: "alice", Body: strings.NewReader("data")})
+		if err := f.deps.AddBlocker(ctx, models.AddBlocker{TodoID: f.todo.ID, BlockerID: f.blocker.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := todos.DeleteTodo(ctx, f.todo.ID); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	related := func(f fixture) (comments, attachments, blockers int) {
+		c, _ := f.comments.ListComments(ctx, f.todo.ID, "alice")
+		a, _ := f.attachments.ListAttachments(ctx, f.todo.ID, "alice")
+		b, _ := f.deps.ListBlockers(ctx, f.todo.ID)
+		return len(c), len(a), len(b)
+	}
+
+	t.Run("undo brings everything back", func(t *testing.T) {
+		f := setup(t)
+		if _, err := f.todos.Undo(ctx, "alice"); err != nil {
+			t.Fatal(err)
+		}
+
+		if c, a, b := related(f); c != 1 || a != 1 || b != 1 {
+			t.Errorf("got %d comments, %d attachments, %d blockers want one of each", c, a, b)
+		}
+		_, r, err := f.attachments.Open(ctx, f.todo.ID, "alice", f.attachment.ID)
+		if err != nil {
+			t.Fatalf("got %v want the file still stored", err)
+		}
+		r.Close()
+	})
+
+	t.Run("purged once the delete cannot be undone", func(t *testing.T) {
+		f := setup(t, WithUndoDepth(1))
+		// pushes the delete off the one-step stack
+		mustCreate(t, f.todos, "later")
+
+		if _, err := f.todos.Undo(ctx, "alice"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.todos.Undo(ctx, "alice"); !errors.Is(err, ErrNothingToUndo) {
+			t.Fatalf("got %v want %v", err, ErrNothingToUndo)
+		}
+		if attachments, _ := f.attachments.repo.ListByTodo(ctx, f.todo.ID); len(attachments) != 0 {
+			t.Errorf("got %d attachments want them purged", len(attachments))
+		}
+		if comments, _ := f.comments.repo.ListByTodo(ctx, f.todo.ID); len(comments) != 0 {
+			t.Errorf("got %d comments want them purged", len(comments))
+		}
+		if _, err := f.attachments.blobs.Open(ctx, f.attachment.BlobKey); !errors.Is(err, blobs.ErrNotFound) {
+			t.Errorf("got %v want the file removed", err)
+		}
+	})
+
+	t.Run("purged at once without undo", func(t *testing.T) {
+		f := setup(t, WithUndoDepth(0))
+		if comments, _ := f.comments.repo.ListByTodo(ctx, f.todo.ID); len(comments) != 0 {
+			t.Errorf("got %d comments want them purged", len(comments))
+		}
+		if blockers, _ := f.deps.repo.Blockers(ctx, f.todo.ID); len(blockers) != 0 {
+			t.Errorf("got %d blockers want them purged", len(blockers))
+		}
+	})
+}
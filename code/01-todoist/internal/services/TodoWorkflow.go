@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+// workflowFor returns the workflow of a user's project, or the default workflow for todos outside any project
+func (s *TodoService) workflowFor(ctx context.Context, userID string, projectID int) (models.Workflow, error) {
+	if projectID == 0 {
+		return models.DefaultWorkflow(), nil
+	}
+	if projectID < 0 || s.projects == nil {
+		return models.Workflow{}, ErrInvalidInput
+	}
+
+	project, err := s.projects.GetByID(ctx, projectID)
+	if errors.Is(err, repositories.ErrNotFound) || (err == nil && project.UserID != userID) {
+		return models.Workflow{}, ErrInvalidInput
+	}
+	if err != nil {
+		return models.Workflow{}, err
+	}
+	return project.Workflow, nil
+}
+
+// transition moves a todo to a new workflow state. A bare status, as sent by clients that predate
+// workflows, picks the first state of the matching category the todo is allowed to reach.
+func (s *TodoService) transition(ctx context.Context, t *models.Todo, status *models.TodoStatus, state *string) error {
+	if status != nil {
+		switch *status {
+		case models.StatusPending, models.StatusCompleted, models.StatusTrashed:
+		default:
+			return ErrInvalidInput
+		}
+	}
+
+	workflow, err := s.workflowFor(ctx, t.UserID, t.ProjectID)
+	if err != nil {
+		return err
+	}
+	current := workflow.StateOf(*t)
+
+	var target models.WorkflowState
+	switch {
+	case state != nil:
+		st, ok := workflow.State(*state)
+		if !ok || (status != nil && st.Category.Status() != *status) {
+			return ErrInvalidInput
+		}
+		target = st
+	case current.Category.Status() == *status:
+		target = current
+	default:
+		found := false
+		for _, st := range workflow.States {
+			if st.Category.Status() == *status && workflow.Allows(current.Key, st.Key) {
+				target, found = st, true
+				break
+			}
+		}
+		if !found {
+			return ErrInvalidTransition
+		}
+	}
+
+	if !workflow.Allows(current.Key, target.Key) {
+		return ErrInvalidTransition
+	}
+
+	t.Status = target.Category.Status()
+	if t.ProjectID != 0 {
+		t.State = target.Key
+	}
+	return nil
+}
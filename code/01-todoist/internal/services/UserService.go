@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"regexp"
+	"sync"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+type IUserService interface {
+	Register(ctx context.Context, dto models.RegisterUser) (models.User, error)
+	Login(ctx context.Context, dto models.Login) (models.User, error)
+	ChangePassword(ctx context.Context, dto models.ChangePassword) error
+}
+
+const (
+	// DefaultPasswordIterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	DefaultPasswordIterations = 600_000
+	saltSize                  = 16
+	hashSize                  = 32
+	minPasswordLength         = 8
+	maxPasswordLength         = 1024
+)
+
+// user IDs end up in URL paths, so they are kept to a conservative alphabet
+var userIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type UserService struct {
+	repo       repositories.UserRepository
+	iterations int
+	limiter    *loginLimiter
+}
+
+// UserOption configures a UserService
+type UserOption func(*UserService)
+
+// WithPasswordIterations sets the PBKDF2 work factor for new hashes. Existing hashes
+// with fewer iterations are upgraded on the next successful login.
+func WithPasswordIterations(n int) UserOption {
+	return func(s *UserService) {
+		s.iterations = n
+	}
+}
+
+// WithLoginLimit locks an account for window after max failed logins within window
+func WithLoginLimit(max int, window time.Duration) UserOption {
+	return func(s *UserService) {
+		s.limiter.max = max
+		s.limiter.window = window
+	}
+}
+
+func NewUserService(repo repositories.UserRepository, opts ...UserOption) *UserService {
+	s := &UserService{
+		repo:       repo,
+		iterations: DefaultPasswordIterations,
+		limiter:    newLoginLimiter(5, 15*time.Minute),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func validatePassword(p string) error {
+	if len(p) < minPasswordLength || len(p) > maxPasswordLength {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+func (s *UserService) Register(ctx context.Context, dto models.RegisterUser) (models.User, error) {
+	if !userIDPattern.MatchString(dto.ID) {
+		return models.User{}, ErrInvalidInput
+	}
+	if err := validatePassword(dto.Password); err != nil {
+		return models.User{}, err
+	}
+
+	u := models.User{
+		ID:        dto.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.setPassword(&u, dto.Password); err != nil {
+		return models.User{}, err
+	}
+
+	// propagates ErrConflict when the ID is taken
+	return s.repo.Create(ctx, u)
+}
+
+// Login checks the password. Repeated failures for the same account from the same client
+// lock that client out of the account for a while.
+func (s *UserService) Login(ctx context.Context, dto models.Login) (models.User, error) {
+	if dto.ID == "" || dto.Password == "" {
+		return models.User{}, ErrInvalidCredentials
+	}
+
+	key := loginKey{id: dto.ID, ip: dto.ClientIP}
+	if !s.limiter.allow(key) {
+		return models.User{}, ErrTooManyAttempts
+	}
+
+	u, err := s.authenticate(ctx, dto.ID, dto.Password)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidCredentials) {
+			s.limiter.refund(key)
+		}
+		return models.User{}, err
+	}
+	s.limiter.reset(key)
+
+	if u.Iterations < s.iterations {
+		if err := s.setPassword(&u, dto.Password); err == nil {
+			if upgraded, err := s.repo.Update(ctx, u); err == nil {
+				u = upgraded
+			}
+		}
+	}
+
+	return u, nil
+}
+
+func (s *UserService) ChangePassword(ctx context.Context, dto models.ChangePassword) error {
+	if err := validatePassword(dto.NewPassword); err != nil {
+		return err
+	}
+
+	key := loginKey{id: dto.UserID, ip: dto.ClientIP}
+	if !s.limiter.allow(key) {
+		return ErrTooManyAttempts
+	}
+
+	u, err := s.authenticate(ctx, dto.UserID, dto.OldPassword)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidCredentials) {
+			s.limiter.refund(key)
+		}
+		return err
+	}
+	s.limiter.reset(key)
+
+	if err := s.setPassword(&u, dto.NewPassword); err != nil {
+		return err
+	}
+	u.UpdatedAt = time.Now()
+
+	_, err = s.repo.Update(ctx, u)
+	return err
+}
+
+// authenticate returns ErrInvalidCredentials for both unknown accounts and wrong passwords,
+// and hashes in either case so the two cannot be told apart by timing
+func (s *UserService) authenticate(ctx context.Context, id, password string) (models.User, error) {
+	u, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		pbkdf2.Key(sha256.New, password, make([]byte, saltSize), s.iterations, hashSize)
+		return models.User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+
+	hash, err := pbkdf2.Key(sha256.New, password, u.Salt, u.Iterations, len(u.PasswordHash))
+	if err != nil {
+		return models.User{}, err
+	}
+	if subtle.ConstantTimeCompare(hash, u.PasswordHash) != 1 {
+		return models.User{}, ErrInvalidCredentials
+	}
+	return u, nil
+}
+
+// setPassword hashes with a fresh salt, so changing a password never reuses one
+func (s *UserService) setPassword(u *models.User, password string) error {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	hash, err := pbkdf2.Key(sha256.New, password, salt, s.iterations, hashSize)
+	if err != nil {
+		return err
+	}
+
+	u.PasswordHash, u.Salt, u.Iterations = hash, salt, s.iterations
+	return nil
+}
+
+// loginLimiter counts failed logins per account and client in a fixed window. A client
+// that reaches max failures on an account is refused until its window ends; other clients
+// can still sign in to the same account.
+//
+// Every attempt is counted as a failure up front and given back on success, so attempts
+// racing through the password check in parallel cannot get past max.
+type loginLimiter struct {
+	max    int
+	window time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	failures map[loginKey]*loginFailures
+}
+
+type loginKey struct {
+	id string
+	ip string
+}
+
+type loginFailures struct {
+	count int
+	start time.Time
+}
+
+func newLoginLimiter(max int, window time.Duration) *loginLimiter {
+	return &loginLimiter{
+		max:      max,
+		window:   window,
+		now:      time.Now,
+		failures: make(map[loginKey]*loginFailures),
+	}
+}
+
+// allow reserves an attempt, or reports false when the client has used them all
+func (l *loginLimiter) allow(key loginKey) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	f, ok := l.failures[key]
+	if !ok || now.Sub(f.start) >= l.window {
+		f = &loginFailures{start: now}
+		l.failures[key] = f
+		l.sweep(now)
+	}
+	if f.count >= l.max {
+		return false
+	}
+	f.count++
+	return true
+}
+
+// refund gives back a reserved attempt that failed for reasons other than the password
+func (l *loginLimiter) refund(key loginKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if f, ok := l.failures[key]; ok && f.count > 0 {
+		f.count--
+	}
+}
+
+func (l *loginLimiter) reset(key loginKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, key)
+}
+
+// sweep drops expired windows so guessing at many account names cannot grow the map forever
+func (l *loginLimiter) sweep(now time.Time) {
+	if len(l.failures) < 1024 {
+		return
+	}
+	for key, f := range l.failures {
+		if now.Sub(f.start) >= l.window {
+			delete(l.failures, key)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func newUserFixture() (*UserService, *repositories.InMemoryUserRepo) {
+	repo := repositories.NewInMemoryUserRepo()
+	// a low work factor keeps the tests fast; the hashing itself is the same
+	return NewUserService(repo, WithPasswordIterations(1000), WithLoginLimit(3, time.Minute)), repo
+}
+
+func TestUserRegistrationAndLogin(t *testing.T) {
+	ctx := context.Background()
+	users, repo := newUserFixture()
+
+	if _, err := users.Register(ctx, models.RegisterUser{ID: "alice", Password: "correct horse"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.Register(ctx, models.RegisterUser{ID: "alice", Password: "another one"}); err != repositories.ErrConflict {
+		t.Errorf("got %v want %v for a taken ID", err, repositories.ErrConflict)
+	}
+	if _, err := users.Register(ctx, models.RegisterUser{ID: "bob", Password: "short"}); err != ErrInvalidInput {
+		t.Errorf("got %v want %v for a short password", err, ErrInvalidInput)
+	}
+	if _, err := users.Register(ctx, models.RegisterUser{ID: "a/b", Password: "long enough"}); err != ErrInvalidInput {
+		t.Errorf("got %v want %v for an ID with a slash", err, ErrInvalidInput)
+	}
+
+	stored, _ := repo.GetByID(ctx, "alice")
+	if string(stored.PasswordHash) == "correct horse" || len(stored.Salt) != saltSize {
+		t.Errorf("got %+v want a salted hash", stored)
+	}
+
+	if _, err := users.Login(ctx, models.Login{ID: "alice", Password: "correct horse"}); err != nil {
+		t.Errorf("got %v want successful login", err)
+	}
+	if _, err := users.Login(ctx, models.Login{ID: "alice", Password: "wrong horse"}); err != ErrInvalidCredentials {
+		t.Errorf("got %v want %v", err, ErrInvalidCredentials)
+	}
+	if _, err := users.Login(ctx, models.Login{ID: "nobody", Password: "correct horse"}); err != ErrInvalidCredentials {
+		t.Errorf("got %v want %v for an unknown account", err, ErrInvalidCredentials)
+	}
+
+	t.Run("same password gets a different salt", func(t *testing.T) {
+		users.Register(ctx, models.RegisterUser{ID: "carol", Password: "correct horse"})
+		carol, _ := repo.GetByID(ctx, "carol")
+		alice, _ := repo.GetByID(ctx, "alice")
+		if string(carol.Salt) == string(alice.Salt) || string(carol.PasswordHash) == string(alice.PasswordHash) {
+			t.Error("got identical salt or hash for two accounts")
+		}
+	})
+
+	t.Run("change password", func(t *testing.T) {
+		err := users.ChangePassword(ctx, models.ChangePassword{UserID: "alice", OldPassword: "correct horse", NewPassword: "battery staple"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := users.Login(ctx, models.Login{ID: "alice", Password: "correct horse"}); err != ErrInvalidCredentials {
+			t.Errorf("got %v want old password rejected", err)
+		}
+		if _, err := users.Login(ctx, models.Login{ID: "alice", Password: "battery staple"}); err != nil {
+			t.Errorf("got %v want new password accepted", err)
+		}
+	})
+}
+
+func TestLoginRateLimit(t *testing.T) {
+	ctx := context.Background()
+	users, _ := newUserFixture()
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	users.limiter.now = func() time.Time { return now }
+
+	users.Register(ctx, models.RegisterUser{ID: "alice", Password: "correct horse"})
+
+	for range 3 {
+		users.Login(ctx, models.Login{ID: "alice", Password: "guess"})
+	}
+	if _, err := users.Login(ctx, models.Login{ID: "alice", Password: "correct horse"}); err != ErrTooManyAttempts {
+		t.Errorf("got %v want %v once the limit is reached, even with the right password", err, ErrTooManyAttempts)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := users.Login(ctx, models.Login{ID: "alice", Password: "correct horse"}); err != nil {
+		t.Errorf("got %v want login allowed after the window", err)
+	}
+}
+
+func TestLoginRateLimitPerClient(t *testing.T) {
+	ctx := context.Background()
+	users, _ := newUserFixture()
+
+	users.Register(ctx, models.RegisterUser{ID: "alice", Password: "correct horse"})
+
+	t.Run("parallel guesses cannot exceed the limit", func(t *testing.T) {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		checked := 0
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := users.Login(ctx, models.Login{ID: "alice", Password: "guess", ClientIP: "10.0.0.1"})
+				if err == ErrInvalidCredentials {
+					mu.Lock()
+					checked++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if checked != 3 {
+			t.Errorf("got %d passwords checked want 3", checked)
+		}
+	})
+
+	t.Run("other clients can still sign in", func(t *testing.T) {
+		if _, err := users.Login(ctx, models.Login{ID: "alice", Password: "correct horse", ClientIP: "10.0.0.2"}); err != nil {
+			t.Errorf("got %v want login allowed from another client", err)
+		}
+		if _, err := users.Login(ctx, models.Login{ID: "alice", Password: "correct horse", ClientIP: "10.0.0.1"}); err != ErrTooManyAttempts {
+			t.Errorf("got %v want %v for the locked client", err, ErrTooManyAttempts)
+		}
+	})
+}
+
+func TestCreateTodoRejectsUnknownUsers(t *testing.T) {
+	ctx := context.Background()
+	users, repo := newUserFixture()
+	todos := NewTodoService(repositories.NewInMemoryTodoRepo(), WithUsers(repo))
+
+	users.Register(ctx, models.RegisterUser{ID: "alice", Password: "correct horse"})
+
+	if _, err := todos.CreateTodo(ctx, models.CreateTodo{UserID: "alice", Title: "mine"}); err != nil {
+		t.Errorf("got %v want todo created for a registered user", err)
+	}
+	if _, err := todos.CreateTodo(ctx, models.CreateTodo{UserID: "bob", Title: "not mine"}); err != ErrUnknownUser {
+		t.Errorf("got %v want %v", err, ErrUnknownUser)
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"slices"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+type IWebhookService interface {
+	RegisterWebhook(ctx context.Context, dto models.CreateWebhook) (models.Webhook, error)
+	ListWebhooks(ctx context.Context, userID string) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID string, id int) error
+	ListAttempts(ctx context.Context, userID string, id int) ([]models.DeliveryAttempt, error)
+	ListDeadLetters(ctx context.Context, userID string) ([]models.DeadLetter, error)
+}
+
+var webhookEventTypes = []string{
+	string(events.TodoCreated),
+	string(events.TodoUpdated),
+	string(events.TodoDeleted),
+	string(events.TodoCompleted),
+}
+
+type WebhookService struct {
+	repo repositories.WebhookRepository
+}
+
+func NewWebhookService(repo repositories.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		repo: repo,
+	}
+}
+
+// RegisterWebhook validates the target and event filter. A signing secret is generated when none is given;
+// it is only ever returned from this call.
+func (s *WebhookService) RegisterWebhook(ctx context.Context, dto models.CreateWebhook) (models.Webhook, error) {
+	if dto.UserID == "" || len(dto.Events) == 0 {
+		return models.Webhook{}, ErrInvalidInput
+	}
+
+	u, err := url.Parse(dto.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.Webhook{}, ErrInvalidInput
+	}
+
+	for _, e := range dto.Events {
+		if !slices.Contains(webhookEventTypes, e) {
+			return models.Webhook{}, ErrInvalidInput
+		}
+	}
+
+	secret := dto.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		rand.Read(buf)
+		secret = hex.EncodeToString(buf)
+	}
+
+	return s.repo.Create(ctx, models.Webhook{
+		UserID:    dto.UserID,
+		URL:       u.String(),
+		Secret:    secret,
+		Events:    dto.Events,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context, userID string) ([]models.Webhook, error) {
+	if userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	hooks, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	return hooks, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID string, id int) error {
+	if _, err := s.owned(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *WebhookService) ListAttempts(ctx context.Context, userID string, id int) ([]models.DeliveryAttempt, error) {
+	if _, err := s.owned(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListAttempts(ctx, id)
+}
+
+func (s *WebhookService) ListDeadLetters(ctx context.Context, userID string) ([]models.DeadLetter, error) {
+	if userID == "" {
+		return nil, ErrInvalidInput
+	}
+
+	return s.repo.ListDeadLetters(ctx, userID)
+}
+
+// owned hides other users' webhooks behind ErrNotFound
+func (s *WebhookService) owned(ctx context.Context, userID string, id int) (models.Webhook, error) {
+	if userID == "" || id <= 0 {
+		return models.Webhook{}, ErrInvalidInput
+	}
+
+	w, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if w.UserID != userID {
+		return models.Webhook{}, repositories.ErrNotFound
+	}
+	return w, nil
+}
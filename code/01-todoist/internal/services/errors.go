@@ -4,4 +4,22 @@ import "errors"
 
 var (
 	ErrInvalidInput = errors.New("invalid input")
+	// ErrUnknownUser is returned when a todo is created for an account that does not exist
+	ErrUnknownUser = errors.New("unknown user")
+	// ErrInvalidCredentials deliberately does not say whether the account or the password was wrong
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrTooManyAttempts    = errors.New("too many login attempts")
+	ErrTooLarge           = errors.New("upload too large")
+	// ErrChecksumMismatch means the stored bytes do not hash to the checksum the client sent
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrInvalidTransition is returned when the project's workflow does not allow moving a todo to the requested state
+	ErrInvalidTransition = errors.New("transition not allowed by workflow")
+	// ErrBlocked is returned when completing a todo that still waits for pending blockers
+	ErrBlocked = errors.New("todo is blocked by open todos")
+	// ErrDependencyCycle is returned when a blocked-by relation would make todos wait for each other
+	ErrDependencyCycle = errors.New("dependency would form a cycle")
+	// ErrNothingToUndo is returned by undo and redo when the user has no step left to revert
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrStale is returned by undo and redo when a todo was written again after the step being reverted
+	ErrStale = errors.New("todo changed since")
 )
@@ -0,0 +1,276 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/health"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+type Config struct {
+	Workers     int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Workers:     4,
+		MaxAttempts: 6,
+		BaseBackoff: time.Second,
+		MaxBackoff:  5 * time.Minute,
+		Timeout:     10 * time.Second,
+	}
+}
+
+type delivery struct {
+	webhookID int
+	userID    string
+	id        string
+	eventType string
+	payload   []byte
+	attempt   int
+}
+
+type payload struct {
+	DeliveryID string       `json:"deliveryId"`
+	Type       string       `json:"type"`
+	OccurredAt time.Time    `json:"occurredAt"`
+	Todo       models.Todo  `json:"todo"`
+	Previous   *models.Todo `json:"previous,omitempty"`
+}
+
+// Dispatcher delivers bus events to matching webhooks from a pool of workers.
+// Failed deliveries are retried with exponential backoff and jitter until MaxAttempts,
+// after which they are moved to the dead-letter list.
+type Dispatcher struct {
+	repo   repositories.WebhookRepository
+	cfg    Config
+	client *http.Client
+
+	// Heartbeat is beaten by idle and busy workers alike
+	Heartbeat health.Heartbeat
+
+	mu     sync.Mutex
+	queue  []delivery
+	signal chan struct{}
+	seq    atomic.Uint64
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(repo repositories.WebhookRepository, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		signal: make(chan struct{}, 1),
+	}
+}
+
+// HandleEvent is registered with events.Bus.Listen. It only enqueues; delivery happens on the workers.
+func (d *Dispatcher) HandleEvent(e events.Event) {
+	hooks, err := d.repo.ListByUser(context.Background(), e.UserID)
+	if err != nil {
+		return
+	}
+
+	types := []string{string(e.Type)}
+	if e.Completed() {
+		types = append(types, string(events.TodoCompleted))
+	}
+
+	for _, hook := range hooks {
+		for _, typ := range types {
+			if !slices.Contains(hook.Events, typ) {
+				continue
+			}
+
+			id := strconv.FormatUint(d.seq.Add(1), 10)
+			body, err := json.Marshal(payload{
+				DeliveryID: id,
+				Type:       typ,
+				OccurredAt: e.OccurredAt,
+				Todo:       e.Todo,
+				Previous:   e.Previous,
+			})
+			if err != nil {
+				continue
+			}
+
+			d.enqueue(delivery{
+				webhookID: hook.ID,
+				userID:    hook.UserID,
+				id:        id,
+				eventType: typ,
+				payload:   body,
+			})
+		}
+	}
+}
+
+// Start runs the workers until ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	for range d.cfg.Workers {
+		d.wg.Add(1)
+		go d.work(ctx)
+	}
+}
+
+// Wait blocks until every worker has returned after Start's context is cancelled
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) enqueue(job delivery) {
+	d.mu.Lock()
+	d.queue = append(d.queue, job)
+	d.mu.Unlock()
+
+	select {
+	case d.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) next() (delivery, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.queue) == 0 {
+		return delivery{}, false
+	}
+	job := d.queue[0]
+	d.queue = d.queue[1:]
+
+	// wake another worker if there is more to do
+	if len(d.queue) > 0 {
+		select {
+		case d.signal <- struct{}{}:
+		default:
+		}
+	}
+	return job, true
+}
+
+func (d *Dispatcher) work(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		d.Heartbeat.Beat()
+
+		job, ok := d.next()
+		if ok {
+			d.deliver(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.signal:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, job delivery) {
+	hook, err := d.repo.GetByID(ctx, job.webhookID)
+	if err != nil {
+		// webhook was removed while the delivery was pending
+		return
+	}
+
+	job.attempt++
+	start := time.Now()
+	status, err := d.post(ctx, hook, job)
+
+	attempt := models.DeliveryAttempt{
+		WebhookID:  hook.ID,
+		DeliveryID: job.id,
+		EventType:  job.eventType,
+		Attempt:    job.attempt,
+		StatusCode: status,
+		Duration:   time.Since(start).String(),
+		At:         start,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	d.repo.AddAttempt(ctx, attempt)
+
+	if err == nil {
+		return
+	}
+
+	if job.attempt >= d.cfg.MaxAttempts {
+		d.repo.AddDeadLetter(ctx, models.DeadLetter{
+			WebhookID:  hook.ID,
+			UserID:     hook.UserID,
+			DeliveryID: job.id,
+			EventType:  job.eventType,
+			Payload:    job.payload,
+			Attempts:   job.attempt,
+			LastError:  err.Error(),
+			FailedAt:   time.Now(),
+		})
+		return
+	}
+
+	time.AfterFunc(d.backoff(job.attempt), func() {
+		if ctx.Err() == nil {
+			d.enqueue(job)
+		}
+	})
+}
+
+func (d *Dispatcher) post(ctx context.Context, hook models.Webhook, job delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(job.payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, job.eventType)
+	req.Header.Set(DeliveryHeader, job.id)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, timestamp, job.payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff doubles BaseBackoff per attempt up to MaxBackoff and picks a random delay in the upper half
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	wait := d.cfg.BaseBackoff << (attempt - 1)
+	if wait <= 0 || wait > d.cfg.MaxBackoff {
+		wait = d.cfg.MaxBackoff
+	}
+
+	half := wait / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,166 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todoist/internal/events"
+	"todoist/internal/models"
+	"todoist/internal/repositories"
+)
+
+func testConfig() Config {
+	return Config{
+		Workers:     2,
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+		Timeout:     time.Second,
+	}
+}
+
+func startDispatcher(t *testing.T, repo repositories.WebhookRepository) *Dispatcher {
+	t.Helper()
+	d := NewDispatcher(repo, testConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	d.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		d.Wait()
+	})
+	return d
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	received := make(chan payload, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify("s3cret", r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader)) {
+			t.Error("signature did not verify")
+		}
+
+		var p payload
+		json.Unmarshal(body, &p)
+		received <- p
+	}))
+	defer receiver.Close()
+
+	ctx := context.Background()
+	repo := repositories.NewInMemoryWebhookRepo()
+	repo.Create(ctx, models.Webhook{UserID: "alice", URL: receiver.URL, Secret: "s3cret", Events: []string{"todo.completed"}})
+
+	d := startDispatcher(t, repo)
+
+	pending := models.Todo{ID: 1, UserID: "alice", Status: models.StatusPending}
+	completed := pending
+	completed.Status = models.StatusCompleted
+
+	// not subscribed: creation, and updates that don't complete the todo
+	d.HandleEvent(events.Event{Type: events.TodoCreated, UserID: "alice", Todo: pending})
+	d.HandleEvent(events.Event{Type: events.TodoUpdated, UserID: "alice", Todo: pending, Previous: &pending})
+	d.HandleEvent(events.Event{Type: events.TodoUpdated, UserID: "alice", Todo: completed, Previous: &pending})
+
+	select {
+	case p := <-received:
+		if p.Type != string(events.TodoCompleted) || p.Todo.ID != 1 {
+			t.Errorf("got %+v want todo.completed for todo 1", p)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no delivery received")
+	}
+
+	select {
+	case p := <-received:
+		t.Errorf("unexpected extra delivery %+v", p)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDispatcherRetriesThenDeadLetters(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	ctx := context.Background()
+	repo := repositories.NewInMemoryWebhookRepo()
+	hook, _ := repo.Create(ctx, models.Webhook{UserID: "alice", URL: receiver.URL, Secret: "s", Events: []string{"todo.created"}})
+
+	d := startDispatcher(t, repo)
+	d.HandleEvent(events.Event{Type: events.TodoCreated, UserID: "alice", Todo: models.Todo{ID: 7}})
+
+	waitFor(t, func() bool {
+		letters, _ := repo.ListDeadLetters(ctx, "alice")
+		return len(letters) == 1
+	})
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("got %d calls want 3", got)
+	}
+
+	attempts, _ := repo.ListAttempts(ctx, hook.ID)
+	if len(attempts) != 3 {
+		t.Fatalf("got %d attempts want 3", len(attempts))
+	}
+	for i, a := range attempts {
+		if a.Attempt != i+1 || a.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("attempt %d: got %+v", i, a)
+		}
+	}
+}
+
+func TestDispatcherRecoversAfterFailure(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "flaky", http.StatusInternalServerError)
+		}
+	}))
+	defer receiver.Close()
+
+	ctx := context.Background()
+	repo := repositories.NewInMemoryWebhookRepo()
+	hook, _ := repo.Create(ctx, models.Webhook{UserID: "alice", URL: receiver.URL, Secret: "s", Events: []string{"todo.deleted"}})
+
+	d := startDispatcher(t, repo)
+	d.HandleEvent(events.Event{Type: events.TodoDeleted, UserID: "alice"})
+
+	waitFor(t, func() bool {
+		attempts, _ := repo.ListAttempts(ctx, hook.ID)
+		return len(attempts) == 2
+	})
+
+	letters, _ := repo.ListDeadLetters(ctx, "alice")
+	if len(letters) != 0 {
+		t.Errorf("got %d dead letters want 0", len(letters))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, Config{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	for attempt, max := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: 10 * time.Second} {
+		got := d.backoff(attempt)
+		if got < max/2 || got > max {
+			t.Errorf("attempt %d: got %s want between %s and %s", attempt, got, max/2, max)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	SignatureHeader = "X-Todoist-Signature"
+	TimestampHeader = "X-Todoist-Timestamp"
+	EventHeader     = "X-Todoist-Event"
+	DeliveryHeader  = "X-Todoist-Delivery"
+)
+
+// Sign returns the signature header value for a delivery.
+// The timestamp is part of the signed message so receivers can reject replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value in constant time
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}